@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strconv"
@@ -14,11 +15,48 @@ type Config struct {
 	Port             string
 	AccessTokenTTL   int
 	RefreshTokenTTL  int
+	ImpersonationTTL int
+	AppURL           string
+	SigningSecret    string
+	ExportDir        string
+	ExportLinkTTL    int
+	ExportRetention  int
+	StorageDriver    string
+	StorageDir       string
+	StoragePublicURL string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3PublicURL      string
+	UploadMaxBytes   int
+	ImageRetention   int
+	ImportDir        string
+	ImportMaxBytes   int
+	ReservationTTL   int
+	Allocation       string
+	MailDriver       string
+	MailFrom         string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
 	DbName           string
 	DbHost           string
 	DbPort           string
 	DbUser           string
 	DbPass           string
+	OIDCProvider     string
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCAuthURL      string
+	OIDCTokenURL     string
+	OIDCUserInfoURL  string
+	OIDCJWKSURL      string
+	OIDCRedirectURL  string
+	OIDCScopes       string
 }
 
 var Envs = InitConfig()
@@ -35,14 +73,60 @@ func InitConfig() *Config {
 		Port:             getEnv("PORT", "3000"),
 		AccessTokenTTL:   getEnvAsInt("ACC_EXPIRED", 3600*3),
 		RefreshTokenTTL:  getEnvAsInt("REFRESH_EXPIRED", 3600*24*7),
+		ImpersonationTTL: getEnvAsInt("IMPERSONATION_EXPIRED", 60*15),
+		AppURL:           getEnv("APP_URL", "http://localhost:9000"),
+		SigningSecret:    getEnv("SIGNING_SECRET", ""),
+		ExportDir:        getEnv("EXPORT_DIR", "storage/exports"),
+		ExportLinkTTL:    getEnvAsInt("EXPORT_LINK_EXPIRED", 3600*24),
+		ExportRetention:  getEnvAsInt("EXPORT_RETENTION", 3600*24*7),
+		StorageDriver:    getEnv("STORAGE_DRIVER", "local"),
+		StorageDir:       getEnv("STORAGE_DIR", "storage/uploads"),
+		StoragePublicURL: getEnv("STORAGE_PUBLIC_URL", "http://localhost:9000/uploads"),
+		S3Endpoint:       getEnv("S3_ENDPOINT", ""),
+		S3Region:         getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:         getEnv("S3_BUCKET", ""),
+		S3AccessKey:      getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:      getEnv("S3_SECRET_KEY", ""),
+		S3PublicURL:      getEnv("S3_PUBLIC_URL", ""),
+		UploadMaxBytes:   getEnvAsInt("UPLOAD_MAX_BYTES", 5<<20),
+		ImageRetention:   getEnvAsInt("IMAGE_RETENTION", 3600*24*30),
+		ImportDir:        getEnv("IMPORT_DIR", "storage/imports"),
+		ImportMaxBytes:   getEnvAsInt("IMPORT_MAX_BYTES", 50<<20),
+		ReservationTTL:   getEnvAsInt("RESERVATION_TTL", 30*60),
+		Allocation:       getEnv("ALLOCATION_STRATEGY", "priority"),
+		MailDriver:       getEnv("MAIL_DRIVER", "log"),
+		MailFrom:         getEnv("MAIL_FROM", "no-reply@localhost"),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
 		DbName:           getEnv("DB_NAME", "postgres"),
 		DbHost:           getEnv("DB_HOST", "127.0.0.1"),
 		DbPort:           getEnv("DB_PORT", "5432"),
 		DbUser:           getEnv("DB_USER", "postgres"),
 		DbPass:           getEnv("DB_PASS", "postgres"),
+		OIDCProvider:     getEnv("OIDC_PROVIDER", "oidc"),
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCAuthURL:      getEnv("OIDC_AUTH_URL", ""),
+		OIDCTokenURL:     getEnv("OIDC_TOKEN_URL", ""),
+		OIDCUserInfoURL:  getEnv("OIDC_USERINFO_URL", ""),
+		OIDCJWKSURL:      getEnv("OIDC_JWKS_URL", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:9000/api/v1/auth/oidc/callback"),
+		OIDCScopes:       getEnv("OIDC_SCOPES", "openid email profile"),
 	}
 }
 
+// Validate rejects settings the server cannot safely start with. Signed links
+// could be forged with a well-known secret, so there is no default one.
+func (c *Config) Validate() error {
+	if c.SigningSecret == "" {
+		return errors.New("SIGNING_SECRET must be set")
+	}
+	return nil
+}
+
 func getEnv(key, fallback string) string {
 	value := os.Getenv(key)
 	if value == "" {
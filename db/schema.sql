@@ -128,4 +128,536 @@ product_id UUID NOT NULL,
 quantity INTEGER NOT NULL,
 price BIGINT NOT NULL,
 created_at BIGINT NOT NULL,
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+name VARCHAR(100) NOT NULL,
+token_hash VARCHAR(64) NOT NULL UNIQUE,
+prefix VARCHAR(16) NOT NULL,
+scopes JSONB NOT NULL DEFAULT '[]',
+expires_at BIGINT NOT NULL,
+last_used_at BIGINT,
+revoked_at BIGINT,
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+provider VARCHAR(50) NOT NULL,
+subject VARCHAR(255) NOT NULL,
+email VARCHAR(255),
+created_at BIGINT NOT NULL,
+last_login_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities (provider, subject);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'customer';
+
+CREATE TABLE IF NOT EXISTS audit_events (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+actor_id UUID,
+action VARCHAR(100) NOT NULL,
+target_type VARCHAR(50),
+target_id VARCHAR(100),
+ip VARCHAR(64),
+user_agent TEXT,
+outcome VARCHAR(20) NOT NULL,
+metadata JSONB NOT NULL DEFAULT '{}',
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id);
+
+CREATE INDEX idx_audit_events_action ON audit_events (action);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS data_exports (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id),
+status VARCHAR(20) NOT NULL,
+file_path TEXT,
+file_size BIGINT NOT NULL DEFAULT 0,
+error TEXT,
+created_at BIGINT NOT NULL,
+completed_at BIGINT,
+expires_at BIGINT
+);
+
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id);
+
+CREATE INDEX idx_data_exports_status ON data_exports (status);
+
+CREATE TABLE IF NOT EXISTS notifications (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id),
+type VARCHAR(100) NOT NULL,
+title VARCHAR(255) NOT NULL,
+body TEXT,
+data JSONB NOT NULL DEFAULT '{}',
+read_at BIGINT,
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, created_at);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at BIGINT;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
+
+CREATE TABLE IF NOT EXISTS user_addresses (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id),
+label VARCHAR(50),
+recipient_name VARCHAR(255) NOT NULL,
+phone VARCHAR(30),
+line1 VARCHAR(255) NOT NULL,
+line2 VARCHAR(255),
+city VARCHAR(100) NOT NULL DEFAULT '',
+region VARCHAR(100),
+postal_code VARCHAR(20),
+country_code CHAR(2) NOT NULL,
+is_default BOOLEAN NOT NULL DEFAULT FALSE,
+created_at BIGINT NOT NULL,
+updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_user_addresses_user_id ON user_addresses (user_id);
+
+CREATE UNIQUE INDEX idx_user_addresses_default ON user_addresses (user_id) WHERE is_default;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS image JSONB;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products (LOWER(slug));
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS categories (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+parent_id UUID REFERENCES categories(id),
+name VARCHAR(255) NOT NULL,
+slug VARCHAR(255) NOT NULL UNIQUE,
+description TEXT,
+position INT NOT NULL DEFAULT 0,
+created_at BIGINT NOT NULL,
+updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX idx_product_categories_category_id ON product_categories (category_id);
+
+UPDATE products SET variant_count = 0 WHERE variant_count IS NULL;
+
+ALTER TABLE products ALTER COLUMN variant_count SET DEFAULT 0;
+
+ALTER TABLE products ALTER COLUMN variant_count SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS product_options (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+name VARCHAR(100) NOT NULL,
+values JSONB NOT NULL,
+position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_product_options_product_id ON product_options (product_id);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+sku VARCHAR(100) NOT NULL,
+options JSONB NOT NULL,
+price INT NOT NULL,
+stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+image JSONB,
+position INT NOT NULL DEFAULT 0,
+created_at BIGINT NOT NULL,
+updated_at BIGINT NOT NULL,
+deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
+
+CREATE INDEX idx_product_variants_deleted_at ON product_variants (deleted_at);
+
+CREATE UNIQUE INDEX idx_product_variants_sku ON product_variants (LOWER(sku));
+
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id UUID REFERENCES product_variants(id);
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id UUID REFERENCES product_variants(id);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+setweight(to_tsvector('simple', COALESCE(sku, '')), 'B') ||
+setweight(to_tsvector('simple', COALESCE(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_products_sku_trgm ON products USING GIN (sku gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_products_price ON products (price);
+
+CREATE INDEX IF NOT EXISTS idx_products_created_at ON products (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items (product_id);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_keyset ON orders (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_keyset ON transactions (account_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_products_created_keyset ON products (created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS product_images (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+position INT NOT NULL DEFAULT 0,
+storage_key VARCHAR(255) NOT NULL,
+url TEXT NOT NULL,
+content_type VARCHAR(50) NOT NULL,
+size BIGINT NOT NULL,
+width INT NOT NULL,
+height INT NOT NULL,
+thumbnails JSONB,
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_product_images_product_id ON product_images (product_id, position);
+
+CREATE TABLE IF NOT EXISTS product_imports (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id),
+format VARCHAR(10) NOT NULL,
+status VARCHAR(20) NOT NULL,
+file_path TEXT,
+processed_rows INT NOT NULL DEFAULT 0,
+created_count INT NOT NULL DEFAULT 0,
+updated_count INT NOT NULL DEFAULT 0,
+failed_count INT NOT NULL DEFAULT 0,
+row_errors JSONB,
+error TEXT,
+created_at BIGINT NOT NULL,
+completed_at BIGINT
+);
+
+CREATE INDEX idx_product_imports_status ON product_imports (status);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+quantity INT NOT NULL,
+stock_after INT NOT NULL CHECK (stock_after >= 0),
+reason VARCHAR(20) NOT NULL,
+reference_type VARCHAR(50),
+reference_id VARCHAR(100),
+note TEXT,
+actor_id UUID REFERENCES users(id),
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements (product_id, created_at DESC);
+
+CREATE INDEX idx_stock_movements_variant_id ON stock_movements (variant_id, created_at DESC);
+
+CREATE INDEX idx_stock_movements_reference ON stock_movements (reference_type, reference_id);
+
+-- Seed the ledger with the stock each product and variant has today, so stock
+-- keeps matching the sum of its movements.
+INSERT INTO stock_movements (product_id, variant_id, quantity, stock_after, reason, created_at)
+SELECT p.id, NULL, p.stock, p.stock, 'initial', p.created_at
+FROM products p
+WHERE p.variant_count = 0 AND COALESCE(p.stock, 0) <> 0
+AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL);
+
+INSERT INTO stock_movements (product_id, variant_id, quantity, stock_after, reason, created_at)
+SELECT v.product_id, v.id, v.stock, v.stock, 'initial', v.created_at
+FROM product_variants v
+WHERE v.stock <> 0
+AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.variant_id = v.id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS available INT GENERATED ALWAYS AS (COALESCE(stock, 0) - reserved) STORED;
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS available INT GENERATED ALWAYS AS (stock - reserved) STORED;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS expires_at BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_orders_expires_at ON orders (status, expires_at);
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+product_id UUID NOT NULL REFERENCES products(id),
+variant_id UUID REFERENCES product_variants(id),
+quantity INT NOT NULL CHECK (quantity > 0),
+status VARCHAR(20) NOT NULL,
+expires_at BIGINT NOT NULL,
+created_at BIGINT NOT NULL,
+closed_at BIGINT
+);
+
+CREATE INDEX idx_stock_reservations_order_id ON stock_reservations (order_id);
+
+CREATE INDEX idx_stock_reservations_active ON stock_reservations (product_id, variant_id) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS warehouses (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+code VARCHAR(50) NOT NULL UNIQUE,
+name VARCHAR(255) NOT NULL,
+city VARCHAR(100),
+region VARCHAR(100),
+postal_code VARCHAR(20),
+country_code CHAR(2) NOT NULL,
+priority INT NOT NULL DEFAULT 0,
+active BOOLEAN NOT NULL DEFAULT TRUE,
+is_default BOOLEAN NOT NULL DEFAULT FALSE,
+created_at BIGINT NOT NULL,
+updated_at BIGINT NOT NULL
+);
+
+-- Stock changes that name no warehouse go to the default one.
+CREATE UNIQUE INDEX idx_warehouses_default ON warehouses (is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, country_code, is_default, created_at, updated_at)
+SELECT 'MAIN', 'Main warehouse', 'ID', TRUE,
+(EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT, (EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT
+WHERE NOT EXISTS (SELECT 1 FROM warehouses);
+
+CREATE TABLE IF NOT EXISTS warehouse_stocks (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+available INT GENERATED ALWAYS AS (quantity - reserved) STORED,
+updated_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_warehouse_stocks_product ON warehouse_stocks (warehouse_id, product_id) WHERE variant_id IS NULL;
+
+CREATE UNIQUE INDEX idx_warehouse_stocks_variant ON warehouse_stocks (warehouse_id, variant_id) WHERE variant_id IS NOT NULL;
+
+CREATE INDEX idx_warehouse_stocks_product_id ON warehouse_stocks (product_id);
+
+-- Existing stock starts out at the default warehouse.
+INSERT INTO warehouse_stocks (warehouse_id, product_id, variant_id, quantity, updated_at)
+SELECT w.id, p.id, NULL, p.stock, p.created_at
+FROM products p, warehouses w
+WHERE w.is_default AND p.variant_count = 0 AND COALESCE(p.stock, 0) > 0
+AND NOT EXISTS (SELECT 1 FROM warehouse_stocks s WHERE s.product_id = p.id AND s.variant_id IS NULL);
+
+INSERT INTO warehouse_stocks (warehouse_id, product_id, variant_id, quantity, updated_at)
+SELECT w.id, v.product_id, v.id, v.stock, v.created_at
+FROM product_variants v, warehouses w
+WHERE w.is_default AND v.stock > 0
+AND NOT EXISTS (SELECT 1 FROM warehouse_stocks s WHERE s.variant_id = v.id);
+
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+
+UPDATE stock_movements SET warehouse_id = (SELECT id FROM warehouses WHERE is_default)
+WHERE warehouse_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse_id ON stock_movements (warehouse_id, created_at DESC);
+
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_threshold INT NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0);
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS email_status VARCHAR(20);
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS email_attempts INT NOT NULL DEFAULT 0;
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS emailed_at BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_email_pending ON notifications (created_at) WHERE email_status = 'pending';
+
+CREATE TABLE IF NOT EXISTS stock_subscriptions (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+created_at BIGINT NOT NULL,
+notified_at BIGINT
+);
+
+CREATE INDEX idx_stock_subscriptions_user_id ON stock_subscriptions (user_id);
+
+CREATE INDEX idx_stock_subscriptions_open ON stock_subscriptions (product_id, variant_id) WHERE notified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS product_codes (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id),
+code VARCHAR(255) NOT NULL,
+status VARCHAR(20) NOT NULL,
+order_id UUID REFERENCES orders(id),
+order_item_id UUID REFERENCES order_items(id),
+replaces_id UUID REFERENCES product_codes(id),
+created_at BIGINT NOT NULL,
+assigned_at BIGINT,
+voided_at BIGINT
+);
+
+CREATE UNIQUE INDEX idx_product_codes_product_code ON product_codes (product_id, code);
+
+CREATE INDEX idx_product_codes_available ON product_codes (product_id, created_at) WHERE status = 'available';
+
+CREATE INDEX idx_product_codes_order_item_id ON product_codes (order_item_id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_reviews (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id),
+user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+order_id UUID NOT NULL REFERENCES orders(id),
+rating INT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+title VARCHAR(150),
+body TEXT,
+images JSONB NOT NULL DEFAULT '[]',
+status VARCHAR(20) NOT NULL,
+helpful_count INT NOT NULL DEFAULT 0,
+moderated_by UUID REFERENCES users(id),
+moderated_at BIGINT,
+created_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_product_reviews_user_product ON product_reviews (user_id, product_id);
+
+CREATE INDEX idx_product_reviews_product_id ON product_reviews (product_id, status, created_at DESC);
+
+CREATE INDEX idx_product_reviews_status ON product_reviews (status, created_at);
+
+CREATE TABLE IF NOT EXISTS review_votes (
+review_id UUID NOT NULL REFERENCES product_reviews(id) ON DELETE CASCADE,
+user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+created_at BIGINT NOT NULL,
+PRIMARY KEY (review_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS wishlists (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+name VARCHAR(100) NOT NULL,
+share_token VARCHAR(64),
+created_at BIGINT NOT NULL,
+updated_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_wishlists_user_name ON wishlists (user_id, LOWER(name));
+
+CREATE UNIQUE INDEX idx_wishlists_share_token ON wishlists (share_token);
+
+CREATE TABLE IF NOT EXISTS wishlist_items (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+wishlist_id UUID NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+product_id UUID NOT NULL REFERENCES products(id),
+variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+last_price INT NOT NULL,
+created_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_wishlist_items_product ON wishlist_items (wishlist_id, product_id) WHERE variant_id IS NULL;
+
+CREATE UNIQUE INDEX idx_wishlist_items_variant ON wishlist_items (wishlist_id, product_id, variant_id) WHERE variant_id IS NOT NULL;
+
+CREATE INDEX idx_wishlist_items_product_id ON wishlist_items (product_id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS compare_at_price INT;
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS compare_at_price INT;
+
+CREATE TABLE IF NOT EXISTS price_changes (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id),
+variant_id UUID REFERENCES product_variants(id),
+price INT NOT NULL,
+previous_price INT,
+compare_at_price INT,
+reason VARCHAR(20) NOT NULL,
+reference_type VARCHAR(50),
+reference_id VARCHAR(100),
+actor_id UUID,
+created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_price_changes_product ON price_changes (product_id, variant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS price_schedules (
+id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+product_id UUID NOT NULL REFERENCES products(id),
+variant_id UUID REFERENCES product_variants(id),
+price INT NOT NULL,
+starts_at BIGINT NOT NULL,
+ends_at BIGINT,
+status VARCHAR(20) NOT NULL,
+regular_price INT,
+created_by UUID,
+created_at BIGINT NOT NULL,
+started_at BIGINT,
+ended_at BIGINT,
+CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_price_schedules_product_id ON price_schedules (product_id, starts_at);
+
+CREATE INDEX idx_price_schedules_due_start ON price_schedules (starts_at) WHERE status = 'scheduled';
+
+CREATE INDEX idx_price_schedules_due_end ON price_schedules (ends_at) WHERE status = 'active';
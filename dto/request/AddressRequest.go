@@ -0,0 +1,27 @@
+package request
+
+type CreateAddressRequest struct {
+	Label         string `json:"label" validate:"max=50" example:"Home"`
+	RecipientName string `json:"recipient_name" validate:"required,max=255" example:"Budi Santoso"`
+	Phone         string `json:"phone" validate:"max=30" example:"+628123456789"`
+	Line1         string `json:"line1" validate:"required,max=255" example:"Jl. Sudirman No. 1"`
+	Line2         string `json:"line2" validate:"max=255"`
+	City          string `json:"city" validate:"max=100" example:"Jakarta Selatan"`
+	Region        string `json:"region" validate:"max=100" example:"DKI Jakarta"`
+	PostalCode    string `json:"postal_code" validate:"max=20" example:"12190"`
+	CountryCode   string `json:"country_code" validate:"required,len=2,alpha" example:"ID"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+// UpdateAddressRequest is a partial update, omitted fields are left unchanged.
+type UpdateAddressRequest struct {
+	Label         *string `json:"label" validate:"omitempty,max=50"`
+	RecipientName *string `json:"recipient_name" validate:"omitempty,max=255"`
+	Phone         *string `json:"phone" validate:"omitempty,max=30"`
+	Line1         *string `json:"line1" validate:"omitempty,max=255"`
+	Line2         *string `json:"line2" validate:"omitempty,max=255"`
+	City          *string `json:"city" validate:"omitempty,max=100"`
+	Region        *string `json:"region" validate:"omitempty,max=100"`
+	PostalCode    *string `json:"postal_code" validate:"omitempty,max=20"`
+	CountryCode   *string `json:"country_code" validate:"omitempty,len=2,alpha"`
+}
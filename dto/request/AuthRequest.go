@@ -17,8 +17,16 @@ type AccessTokenPayload struct {
 	UserID    string    `json:"user_id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
+	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// Actor is set when an admin is impersonating UserID.
+	Actor *TokenActor `json:"act,omitempty"`
+}
+
+type TokenActor struct {
+	UserID   string `json:"sub"`
+	Username string `json:"username"`
 }
 
 type RefreshTokenPayload struct {
@@ -27,3 +35,13 @@ type RefreshTokenPayload struct {
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
+
+type CreateApiTokenRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
@@ -2,6 +2,8 @@ package request
 
 type AddToCartRequest struct {
 	ProductID string `json:"product_id" validate:"required"`
+	// VariantID is required for products sold in variants.
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
 	Quantity  int    `json:"quantity" validate:"required,min=1"`
 }
 
@@ -0,0 +1,25 @@
+package request
+
+type CreateCategoryRequest struct {
+	Name        string  `json:"name" validate:"required,max=255" example:"Vitamins"`
+	Slug        string  `json:"slug" validate:"omitempty,max=255" example:"vitamins"`
+	Description string  `json:"description"`
+	ParentID    *string `json:"parent_id" validate:"omitempty,uuid"`
+	Position    int     `json:"position"`
+}
+
+// UpdateCategoryRequest is a partial update, omitted fields are left unchanged.
+// Use MoveCategoryRequest to change the parent.
+type UpdateCategoryRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Slug        *string `json:"slug" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+	Position    *int    `json:"position"`
+}
+
+// MoveCategoryRequest moves a category, with its whole subtree, under a new
+// parent. A null parent_id moves it to the top level.
+type MoveCategoryRequest struct {
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid"`
+	Position *int    `json:"position"`
+}
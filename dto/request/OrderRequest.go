@@ -7,6 +7,8 @@ type CreateOrderRequest struct {
 	CartID string `json:"cart_id" validate:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
 	// Array of cart item IDs to include in the order
 	SelectedItems []string `json:"selected_items" validate:"required" example:"['123e4567-e89b-12d3-a456-426614174000']"`
+	// Address book entry to ship to, defaults to the user's default address
+	AddressID string `json:"address_id" validate:"omitempty,uuid" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
 }
 
 // UpdateOrderStatusRequest represents the request to update order status
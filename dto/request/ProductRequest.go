@@ -4,22 +4,124 @@ type CreateProductRequest struct {
 	Name        string   `json:"name" validate:"required"`
 	Description string   `json:"description"`
 	Image       []string `json:"image"`
-	Stock       int      `json:"stock" validate:"required"`
+	Stock       int      `json:"stock" validate:"required_without=UniqueCodeType"`
 	Price       int      `json:"price" validate:"required"`
 	Weight      int      `json:"weight" validate:"required"`
 	BasePrice   int      `json:"base_price" validate:"required"`
 	SKU         string   `json:"sku"`
-	// UniqueCodeType string   `json:"unique_code_type"`
+	CategoryIDs []string `json:"category_ids" validate:"omitempty,dive,uuid"`
+	// ReorderThreshold alerts admins when available stock drops below it.
+	ReorderThreshold int `json:"reorder_threshold" validate:"min=0"`
+	// UniqueCodeType makes a digital product sold as redemption codes. Its
+	// stock comes from uploaded codes and must be left at 0.
+	UniqueCodeType string `json:"unique_code_type" validate:"omitempty,oneof=voucher license_key"`
 }
 
+// UpdateProductRequest is a partial update, omitted fields are left unchanged.
 type UpdateProductRequest struct {
-	Name        string   `json:"name" validate:"required"`
-	Description string   `json:"description"`
-	Image       []string `json:"image"`
-	Stock       int      `json:"stock" validate:"required"`
-	Price       int      `json:"price" validate:"required"`
-	Weight      int      `json:"weight" validate:"required"`
-	BasePrice   int      `json:"base_price" validate:"required"`
-	SKU         string   `json:"sku"`
-	// UniqueCodeType string   `json:"unique_code_type"`
+	Name        *string   `json:"name" validate:"omitempty,min=1"`
+	Description *string   `json:"description"`
+	Image       *[]string `json:"image"`
+	Stock       *int      `json:"stock" validate:"omitempty,min=0"`
+	Price       *int      `json:"price" validate:"omitempty,min=0"`
+	Weight      *int      `json:"weight" validate:"omitempty,min=0"`
+	BasePrice   *int      `json:"base_price" validate:"omitempty,min=0"`
+	SKU         *string   `json:"sku"`
+	CategoryIDs *[]string `json:"category_ids" validate:"omitempty,dive,uuid"`
+	// ReorderThreshold of 0 disables low-stock alerts.
+	ReorderThreshold *int `json:"reorder_threshold" validate:"omitempty,min=0"`
+	// UniqueCodeType can only change while the product has no stock, an
+	// empty value makes it a physical product again.
+	UniqueCodeType *string `json:"unique_code_type" validate:"omitempty,oneof=voucher license_key"`
+}
+
+// ToUpdate turns a full replacement (PUT) into an update touching every field.
+func (r CreateProductRequest) ToUpdate() UpdateProductRequest {
+	image := r.Image
+	categoryIDs := r.CategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []string{}
+	}
+	return UpdateProductRequest{
+		Name:             &r.Name,
+		Description:      &r.Description,
+		Image:            &image,
+		Stock:            &r.Stock,
+		Price:            &r.Price,
+		Weight:           &r.Weight,
+		BasePrice:        &r.BasePrice,
+		SKU:              &r.SKU,
+		CategoryIDs:      &categoryIDs,
+		ReorderThreshold: &r.ReorderThreshold,
+		UniqueCodeType:   &r.UniqueCodeType,
+	}
+}
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Values []string `json:"values" validate:"required,min=1,dive,required"`
+}
+
+// SetProductOptionsRequest replaces the option types of a product.
+type SetProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options" validate:"dive"`
+}
+
+type CreateVariantRequest struct {
+	SKU      string            `json:"sku" validate:"required,max=100"`
+	Options  map[string]string `json:"options" validate:"required"`
+	Price    int               `json:"price" validate:"required,min=1"`
+	Stock    int               `json:"stock" validate:"min=0"`
+	Image    []string          `json:"image"`
+	Position int               `json:"position"`
+}
+
+// UpdateVariantRequest is a partial update, omitted fields are left unchanged.
+type UpdateVariantRequest struct {
+	SKU      *string            `json:"sku" validate:"omitempty,min=1,max=100"`
+	Options  *map[string]string `json:"options"`
+	Price    *int               `json:"price" validate:"omitempty,min=1"`
+	Stock    *int               `json:"stock" validate:"omitempty,min=0"`
+	Image    *[]string          `json:"image"`
+	Position *int               `json:"position"`
+}
+
+// ReorderProductImagesRequest lists every image of the product in the new order.
+type ReorderProductImagesRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// StockAdjustmentRequest changes stock by a signed quantity. VariantID is
+// required for products sold in variants. Without WarehouseID the default
+// warehouse is adjusted.
+type StockAdjustmentRequest struct {
+	VariantID   *string `json:"variant_id" validate:"omitempty,uuid"`
+	WarehouseID *string `json:"warehouse_id" validate:"omitempty,uuid"`
+	Quantity    int     `json:"quantity" validate:"required"`
+	Reason      string  `json:"reason" validate:"omitempty,oneof=adjustment return"`
+	ReferenceID string  `json:"reference_id" validate:"max=100"`
+	Note        string  `json:"note" validate:"max=500"`
+}
+
+// UploadProductCodesRequest adds redemption codes to the pool of a digital
+// product. Codes the product already has are skipped.
+type UploadProductCodesRequest struct {
+	Codes []string `json:"codes" validate:"required,min=1,max=10000,dive,required,max=255"`
+}
+
+// StockSubscriptionRequest asks to be notified when an out of stock product is
+// back. VariantID is required for products sold in variants.
+type StockSubscriptionRequest struct {
+	ProductID string  `json:"product_id" validate:"required,uuid"`
+	VariantID *string `json:"variant_id" validate:"omitempty,uuid"`
+}
+
+// CreatePriceScheduleRequest sets the price of a product, or of one of its
+// variants, at StartsAt. With EndsAt it is a sale and the regular price comes
+// back at EndsAt. Times are Unix milliseconds.
+type CreatePriceScheduleRequest struct {
+	VariantID *string `json:"variant_id" validate:"omitempty,uuid"`
+	Price     int     `json:"price" validate:"min=0"`
+	StartsAt  int64   `json:"starts_at" validate:"required"`
+	EndsAt    *int64  `json:"ends_at" validate:"omitempty,gtfield=StartsAt"`
 }
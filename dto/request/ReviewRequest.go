@@ -0,0 +1,14 @@
+package request
+
+// CreateReviewRequest is sent as JSON, or as a multipart form when images are
+// attached.
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" form:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" form:"title" validate:"max=150"`
+	Body   string `json:"body" form:"body" validate:"required,max=5000"`
+}
+
+// ModerateReviewRequest approves a review, making it public, or hides it.
+type ModerateReviewRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved hidden"`
+}
@@ -6,8 +6,21 @@ type CreateUserRequest struct {
 	Password string `json:"password" binding:"required,min=6" validate:"required,min=6"`
 }
 
+// UpdateUserRequest is a partial update, omitted fields are left unchanged.
 type UpdateUserRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=20" validate:"required,min=3,max=20"`
-	Email    string `json:"email" binding:"required,email" validate:"required,email"`
-	Password string `json:"password" binding:"required,min=6" validate:"required,min=6"`
+	Username *string `json:"username" binding:"omitempty,min=3,max=20" validate:"omitempty,min=3,max=20"`
+	Email    *string `json:"email" binding:"omitempty,email" validate:"omitempty,email"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" validate:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=100" validate:"required,min=8,max=100"`
+}
+
+type DeleteUserRequest struct {
+	Password string `json:"password" binding:"required" validate:"required"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
 }
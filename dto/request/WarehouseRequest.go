@@ -0,0 +1,38 @@
+package request
+
+type CreateWarehouseRequest struct {
+	Code        string `json:"code" validate:"required,max=50" example:"JKT-1"`
+	Name        string `json:"name" validate:"required,max=255" example:"Jakarta Fulfilment Center"`
+	City        string `json:"city" validate:"max=100" example:"Jakarta"`
+	Region      string `json:"region" validate:"max=100" example:"DKI Jakarta"`
+	PostalCode  string `json:"postal_code" validate:"max=20" example:"10110"`
+	CountryCode string `json:"country_code" validate:"required,len=2,alpha" example:"ID"`
+	// Priority orders warehouses for fulfilment, lower ships first.
+	Priority  int   `json:"priority" example:"1"`
+	Active    *bool `json:"active"`
+	IsDefault bool  `json:"is_default"`
+}
+
+// UpdateWarehouseRequest is a partial update, omitted fields are left unchanged.
+type UpdateWarehouseRequest struct {
+	Code        *string `json:"code" validate:"omitempty,min=1,max=50"`
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	City        *string `json:"city" validate:"omitempty,max=100"`
+	Region      *string `json:"region" validate:"omitempty,max=100"`
+	PostalCode  *string `json:"postal_code" validate:"omitempty,max=20"`
+	CountryCode *string `json:"country_code" validate:"omitempty,len=2,alpha"`
+	Priority    *int    `json:"priority"`
+	Active      *bool   `json:"active"`
+	IsDefault   *bool   `json:"is_default"`
+}
+
+// StockTransferRequest moves stock of a product, or of one of its variants,
+// from one warehouse to another.
+type StockTransferRequest struct {
+	ProductID       string  `json:"product_id" validate:"required,uuid"`
+	VariantID       *string `json:"variant_id" validate:"omitempty,uuid"`
+	FromWarehouseID string  `json:"from_warehouse_id" validate:"required,uuid"`
+	ToWarehouseID   string  `json:"to_warehouse_id" validate:"required,uuid,nefield=FromWarehouseID"`
+	Quantity        int     `json:"quantity" validate:"required,min=1"`
+	Note            string  `json:"note" validate:"max=500"`
+}
@@ -0,0 +1,21 @@
+package request
+
+type CreateWishlistRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateWishlistRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// AddWishlistItemRequest saves a product. VariantID is required for products
+// sold in variants.
+type AddWishlistItemRequest struct {
+	ProductID string  `json:"product_id" validate:"required,uuid"`
+	VariantID *string `json:"variant_id" validate:"omitempty,uuid"`
+}
+
+// MoveToCartRequest moves a wishlist item to the cart, 1 unit by default.
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity" validate:"omitempty,min=1"`
+}
@@ -0,0 +1,8 @@
+package response
+
+import "nuxatech-nextmedis/model"
+
+type AuditPagingResponse struct {
+	Metadata Metadata            `json:"metadata"`
+	Result   []*model.AuditEvent `json:"result"`
+}
@@ -13,3 +13,39 @@ type LoginResponse struct {
 type RegisterResponse struct {
 	User UserResponse `json:"user"`
 }
+
+type ApiTokenResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  int64    `json:"expires_at"`
+	LastUsedAt *int64   `json:"last_used_at,omitempty"`
+	RevokedAt  *int64   `json:"revoked_at,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+// CreatedApiTokenResponse carries the plain token, which is only ever
+// returned once at creation time.
+type CreatedApiTokenResponse struct {
+	ApiTokenResponse
+	Token string `json:"token"`
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type UserIdentityResponse struct {
+	ID          string `json:"id"`
+	Provider    string `json:"provider"`
+	Email       string `json:"email"`
+	CreatedAt   int64  `json:"created_at"`
+	LastLoginAt int64  `json:"last_login_at"`
+}
+
+type ImpersonationResponse struct {
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   int64        `json:"expires_at"`
+	User        UserResponse `json:"user"`
+}
@@ -1,9 +1,12 @@
 package response
 
+import "nuxatech-nextmedis/model"
+
 type CartItemResponse struct {
-	ID       string          `json:"id"`
-	Product  ProductResponse `json:"product"`
-	Quantity int             `json:"quantity"`
+	ID       string                `json:"id"`
+	Product  ProductResponse       `json:"product"`
+	Variant  *model.ProductVariant `json:"variant,omitempty"`
+	Quantity int                   `json:"quantity"`
 }
 
 type CartResponse struct {
@@ -0,0 +1,11 @@
+package response
+
+type CategoryResponse struct {
+	ID          string              `json:"id"`
+	ParentID    *string             `json:"parent_id"`
+	Name        string              `json:"name"`
+	Slug        string              `json:"slug"`
+	Description string              `json:"description"`
+	Position    int                 `json:"position"`
+	Children    []*CategoryResponse `json:"children"`
+}
@@ -0,0 +1,13 @@
+package response
+
+type DataExportResponse struct {
+	ID                string `json:"id"`
+	Status            string `json:"status"`
+	FileSize          int64  `json:"file_size"`
+	Error             string `json:"error,omitempty"`
+	CreatedAt         int64  `json:"created_at"`
+	CompletedAt       *int64 `json:"completed_at,omitempty"`
+	ExpiresAt         *int64 `json:"expires_at,omitempty"`
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt int64  `json:"download_expires_at,omitempty"`
+}
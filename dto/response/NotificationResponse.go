@@ -0,0 +1,8 @@
+package response
+
+import "nuxatech-nextmedis/model"
+
+type NotificationPagingResponse struct {
+	Metadata Metadata              `json:"metadata"`
+	Result   []*model.Notification `json:"result"`
+}
@@ -5,27 +5,43 @@ import "nuxatech-nextmedis/model"
 // OrderItemResponse represents a single item in an order
 // @Description Order item details
 type OrderItemResponse struct {
-	ID       string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Product  ProductResponse `json:"product"`
-	Quantity int             `json:"quantity" example:"2"`
-	Price    int64           `json:"price" example:"150000"`
+	ID       string                `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Product  ProductResponse       `json:"product"`
+	Variant  *model.ProductVariant `json:"variant,omitempty"`
+	Quantity int                   `json:"quantity" example:"2"`
+	Price    int64                 `json:"price" example:"150000"`
+	// WarehouseID is the warehouse the item ships from.
+	WarehouseID *string `json:"warehouse_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Codes are the redemption codes of a digital product, only shown in the
+	// order detail once the order is paid.
+	Codes []OrderItemCodeResponse `json:"codes,omitempty"`
+}
+
+type OrderItemCodeResponse struct {
+	ID         string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Code       string `json:"code" example:"ABCD-EFGH-IJKL"`
+	AssignedAt *int64 `json:"assigned_at" example:"1700000000000"`
 }
 
 // OrderResponse represents the complete order information
 // @Description Complete order information
 type OrderResponse struct {
-	ID          string              `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Status      string              `json:"status" example:"pending"`
-	TotalAmount int64               `json:"total_amount" example:"300000"`
-	Items       []OrderItemResponse `json:"items"`
-	CreatedAt   int64               `json:"created_at" example:"1617183834"`
-	UpdatedAt   int64               `json:"updated_at" example:"1617183834"`
-	PaidAt      *int64              `json:"paid_at,omitempty" example:"1617183834"`
+	ID              string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status          string                 `json:"status" example:"pending"`
+	TotalAmount     int64                  `json:"total_amount" example:"300000"`
+	Items           []OrderItemResponse    `json:"items"`
+	ShippingAddress *model.ShippingAddress `json:"shipping_address"`
+	CreatedAt       int64                  `json:"created_at" example:"1617183834"`
+	UpdatedAt       int64                  `json:"updated_at" example:"1617183834"`
+	PaidAt          *int64                 `json:"paid_at,omitempty" example:"1617183834"`
+	ExpiresAt       *int64                 `json:"expires_at,omitempty" example:"1617185634"`
+	Version         int64                  `json:"version" example:"1"`
 }
 
 // OrderPagingResponse represents paginated order results
 // @Description Paginated order list
 type OrderPagingResponse struct {
-	Metadata Metadata       `json:"metadata"`
-	Result   []*model.Order `json:"result"`
+	Metadata *Metadata       `json:"metadata,omitempty"`
+	Cursor   *CursorMetadata `json:"cursor,omitempty"`
+	Result   []*model.Order  `json:"result"`
 }
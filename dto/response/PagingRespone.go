@@ -5,3 +5,12 @@ type Metadata struct {
 	Page       int `json:"page"`
 	PerPage    int `json:"per_page"`
 }
+
+// CursorMetadata replaces Metadata on cursor-paginated listings. Cursors are
+// empty at either end of the list, TotalCount is only set on request.
+type CursorMetadata struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+	TotalCount *int   `json:"total_count,omitempty"`
+}
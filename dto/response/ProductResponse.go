@@ -8,17 +8,96 @@ type ProductResponse struct {
 	Description    string   `json:"description"`
 	Image          []string `json:"image"`
 	Stock          int      `json:"stock"`
+	Available      int      `json:"available"`
 	Price          int      `json:"price"`
+	CompareAtPrice *int     `json:"compare_at_price"`
 	Weight         int      `json:"weight"`
 	BasePrice      int      `json:"base_price"`
 	SKU            string   `json:"sku"`
 	Slug           string   `json:"slug"`
 	UniqueCodeType string   `json:"unique_code_type"`
 	Sold           bool     `json:"sold"`
+	RatingAverage  float64  `json:"rating_average"`
+	RatingCount    int      `json:"rating_count"`
 	CreatedAt      int64    `json:"created_at"`
+	Version        int64    `json:"version"`
+}
+
+type ProductSuggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
 
 type PagingResponse struct {
-	Metadata Metadata         `json:"metadata"`
+	Metadata *Metadata        `json:"metadata,omitempty"`
+	Cursor   *CursorMetadata  `json:"cursor,omitempty"`
 	Result   []*model.Product `json:"result"`
+	Facets   *ProductFacets   `json:"facets,omitempty"`
+}
+
+// ProductFacets count the products matching the current filters, for
+// rendering filter sidebars.
+type ProductFacets struct {
+	Categories   []CategoryFacet    `json:"categories"`
+	PriceBuckets []PriceBucketFacet `json:"price_buckets"`
+	Attributes   []AttributeFacet   `json:"attributes"`
+}
+
+type CategoryFacet struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucketFacet covers prices from Min up to but excluding Max. A nil Max
+// is the open-ended last bucket.
+type PriceBucketFacet struct {
+	Min   int   `json:"min"`
+	Max   *int  `json:"max"`
+	Count int64 `json:"count"`
+}
+
+type AttributeFacet struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ProductCodeResponse is a redemption code as shown to admins, with all but
+// its last characters masked.
+type ProductCodeResponse struct {
+	ID          string  `json:"id"`
+	Code        string  `json:"code"`
+	Status      string  `json:"status"`
+	OrderID     *string `json:"order_id"`
+	OrderItemID *string `json:"order_item_id"`
+	ReplacesID  *string `json:"replaces_id"`
+	CreatedAt   int64   `json:"created_at"`
+	AssignedAt  *int64  `json:"assigned_at"`
+	VoidedAt    *int64  `json:"voided_at"`
+}
+
+type ProductCodePagingResponse struct {
+	Metadata Metadata              `json:"metadata"`
+	Result   []ProductCodeResponse `json:"result"`
+}
+
+// ProductCodeUploadResponse counts the codes added to the pool and those
+// skipped because the product already had them.
+type ProductCodeUploadResponse struct {
+	Added      int `json:"added"`
+	Duplicates int `json:"duplicates"`
+	Stock      int `json:"stock"`
+}
+
+type StockMovementPagingResponse struct {
+	Metadata Metadata               `json:"metadata"`
+	Result   []*model.StockMovement `json:"result"`
+}
+
+type PriceChangePagingResponse struct {
+	Metadata Metadata             `json:"metadata"`
+	Result   []*model.PriceChange `json:"result"`
 }
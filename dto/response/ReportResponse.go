@@ -0,0 +1,24 @@
+package response
+
+// SalesReportResponse sums the paid orders placed between From and To. Amounts
+// use the prices charged on the orders, Discount is what running sales took
+// off the regular prices.
+type SalesReportResponse struct {
+	From     int64                `json:"from"`
+	To       int64                `json:"to"`
+	Units    int64                `json:"units"`
+	Revenue  int64                `json:"revenue"`
+	Discount int64                `json:"discount"`
+	Products []ProductSalesReport `json:"products"`
+}
+
+type ProductSalesReport struct {
+	ProductID    string `json:"product_id"`
+	Name         string `json:"name"`
+	SKU          string `json:"sku"`
+	Units        int64  `json:"units"`
+	Revenue      int64  `json:"revenue"`
+	Discount     int64  `json:"discount"`
+	AveragePrice int64  `json:"average_price"`
+	CurrentPrice int    `json:"current_price"`
+}
@@ -0,0 +1,26 @@
+package response
+
+import "nuxatech-nextmedis/model"
+
+type ReviewResponse struct {
+	ID           string             `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ProductID    string             `json:"product_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Author       string             `json:"author" example:"johndoe"`
+	Rating       int                `json:"rating" example:"5"`
+	Title        string             `json:"title" example:"Works as advertised"`
+	Body         string             `json:"body" example:"Arrived quickly and does the job."`
+	Images       model.ReviewImages `json:"images"`
+	HelpfulCount int                `json:"helpful_count" example:"3"`
+	Status       string             `json:"status" example:"approved"`
+	CreatedAt    int64              `json:"created_at" example:"1700000000000"`
+	// Only shown to admins.
+	UserID      string  `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrderID     string  `json:"order_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ModeratedAt *int64  `json:"moderated_at,omitempty" example:"1700000000000"`
+	ModeratedBy *string `json:"moderated_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+type ReviewPagingResponse struct {
+	Metadata Metadata         `json:"metadata"`
+	Result   []ReviewResponse `json:"result"`
+}
@@ -9,3 +9,9 @@ type TransactionResponse struct {
 	Description string `json:"description"`
 	CreatedAt   int64  `json:"created_at"`
 }
+
+type TransactionPagingResponse struct {
+	Metadata *Metadata              `json:"metadata,omitempty"`
+	Cursor   *CursorMetadata        `json:"cursor,omitempty"`
+	Result   []*TransactionResponse `json:"result"`
+}
@@ -6,3 +6,19 @@ type UserResponse struct {
 	Username  string `json:"username"`
 	CreatedAt string `json:"created_at"`
 }
+
+type AdminUserResponse struct {
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	Email             string `json:"email"`
+	Role              string `json:"role"`
+	Status            string `json:"status"`
+	CreatedAt         int64  `json:"created_at"`
+	SuspendedAt       *int64 `json:"suspended_at"`
+	SessionsRevokedAt *int64 `json:"sessions_revoked_at"`
+}
+
+type AdminUserPagingResponse struct {
+	Metadata Metadata            `json:"metadata"`
+	Result   []AdminUserResponse `json:"result"`
+}
@@ -0,0 +1,23 @@
+package response
+
+import "nuxatech-nextmedis/model"
+
+type WishlistResponse struct {
+	ID   string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name string `json:"name" example:"Birthday"`
+	// ShareURL is the public link of a shared wishlist, only shown to its
+	// owner.
+	ShareURL  *string                `json:"share_url,omitempty" example:"http://localhost:9000/api/v1/wishlists/shared/3f2a..."`
+	Items     []WishlistItemResponse `json:"items"`
+	CreatedAt int64                  `json:"created_at" example:"1700000000000"`
+	UpdatedAt int64                  `json:"updated_at" example:"1700000000000"`
+}
+
+type WishlistItemResponse struct {
+	ID      string                `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Product ProductResponse       `json:"product"`
+	Variant *model.ProductVariant `json:"variant,omitempty"`
+	// Price is the current unit price of the product or variant.
+	Price     int   `json:"price" example:"150000"`
+	CreatedAt int64 `json:"created_at" example:"1700000000000"`
+}
@@ -0,0 +1,29 @@
+package filestore
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+)
+
+// Storage keeps uploaded files under slash-separated keys and serves them
+// from public URLs.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// NewStorage returns the backend selected by STORAGE_DRIVER.
+func NewStorage() Storage {
+	if config.Envs.StorageDriver == "s3" {
+		return NewS3Storage(S3Config{
+			Endpoint:  config.Envs.S3Endpoint,
+			Region:    config.Envs.S3Region,
+			Bucket:    config.Envs.S3Bucket,
+			AccessKey: config.Envs.S3AccessKey,
+			SecretKey: config.Envs.S3SecretKey,
+			PublicURL: config.Envs.S3PublicURL,
+		})
+	}
+	return NewLocalStorage(config.Envs.StorageDir, config.Envs.StoragePublicURL)
+}
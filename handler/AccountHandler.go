@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +16,7 @@ type AccountHandler interface {
 	GetAccount(c *gin.Context)
 	Deposit(c *gin.Context)
 	Withdraw(c *gin.Context)
+	GetTransactions(c *gin.Context)
 }
 
 type accountHandler struct {
@@ -125,6 +128,34 @@ func (h *accountHandler) Withdraw(c *gin.Context) {
 	})
 }
 
+func (h *accountHandler) GetTransactions(c *gin.Context) {
+	params := service.TransactionQueryParams{
+		CursorParams: parseCursorParams(c),
+		Page:         utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:        utils.ParseIntWithDefault(c.Query("limit"), 10),
+	}
+
+	transactions, err := h.accountService.GetTransactions(c, utils.GetUserID(c), c.Param("id"), params)
+	if err != nil {
+		status := listErrorStatus(err)
+		if errors.Is(err, service.ErrAccountNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.APIResponse{
+			Success: false,
+			Message: "Failed to get transactions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Transactions retrieved successfully",
+		Data:    transactions,
+	})
+}
+
 func NewAccountHandler(accountService service.AccountService) AccountHandler {
 	return &accountHandler{
 		accountService: accountService,
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler interface {
+	CreateAddress(c *gin.Context)
+	GetAddresses(c *gin.Context)
+	GetAddress(c *gin.Context)
+	UpdateAddress(c *gin.Context)
+	DeleteAddress(c *gin.Context)
+	SetDefaultAddress(c *gin.Context)
+}
+
+type addressHandler struct {
+	addressService service.AddressService
+}
+
+func NewAddressHandler(addressService service.AddressService) AddressHandler {
+	return &addressHandler{
+		addressService: addressService,
+	}
+}
+
+// @Summary Create address
+// @Description Add an address to the current user's address book. The first address becomes the default.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.CreateAddressRequest true "Address details"
+// @Success 201 {object} response.APIResponse{data=model.UserAddress} "Address created"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/addresses [post]
+// @Security BearerAuth
+func (h *addressHandler) CreateAddress(c *gin.Context) {
+	var req request.CreateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(c, utils.GetUserID(c), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Address created successfully",
+		Data:    address,
+	})
+}
+
+// @Summary List addresses
+// @Description List the current user's addresses, default first
+// @Tags addresses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]model.UserAddress} "Addresses retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/addresses [get]
+// @Security BearerAuth
+func (h *addressHandler) GetAddresses(c *gin.Context) {
+	addresses, err := h.addressService.GetAddresses(c, utils.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get addresses",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Addresses retrieved successfully",
+		Data:    addresses,
+	})
+}
+
+// @Summary Get address
+// @Tags addresses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Address ID"
+// @Success 200 {object} response.APIResponse{data=model.UserAddress} "Address retrieved"
+// @Failure 404 {object} response.APIResponse "Address not found"
+// @Router /user/addresses/{id} [get]
+// @Security BearerAuth
+func (h *addressHandler) GetAddress(c *gin.Context) {
+	address, err := h.addressService.GetAddress(c, utils.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Address retrieved successfully",
+		Data:    address,
+	})
+}
+
+// @Summary Update address
+// @Description Partially update an address. Existing orders keep their own copy.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Address ID"
+// @Param request body request.UpdateAddressRequest true "Fields to change"
+// @Success 200 {object} response.APIResponse{data=model.UserAddress} "Address updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /user/addresses/{id} [patch]
+// @Security BearerAuth
+func (h *addressHandler) UpdateAddress(c *gin.Context) {
+	var req request.UpdateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c, utils.GetUserID(c), c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Address updated successfully",
+		Data:    address,
+	})
+}
+
+// @Summary Delete address
+// @Tags addresses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Address ID"
+// @Success 200 {object} response.APIResponse "Address deleted"
+// @Failure 404 {object} response.APIResponse "Address not found"
+// @Router /user/addresses/{id} [delete]
+// @Security BearerAuth
+func (h *addressHandler) DeleteAddress(c *gin.Context) {
+	if err := h.addressService.DeleteAddress(c, utils.GetUserID(c), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Address deleted successfully",
+	})
+}
+
+// @Summary Set default address
+// @Tags addresses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Address ID"
+// @Success 200 {object} response.APIResponse{data=model.UserAddress} "Default address set"
+// @Failure 404 {object} response.APIResponse "Address not found"
+// @Router /user/addresses/{id}/default [post]
+// @Security BearerAuth
+func (h *addressHandler) SetDefaultAddress(c *gin.Context) {
+	address, err := h.addressService.SetDefaultAddress(c, utils.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to set default address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Default address set successfully",
+		Data:    address,
+	})
+}
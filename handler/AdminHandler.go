@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler interface {
+	ImpersonateUser(c *gin.Context)
+	ListUsers(c *gin.Context)
+	SuspendUser(c *gin.Context)
+	ReactivateUser(c *gin.Context)
+	ForceLogout(c *gin.Context)
+}
+
+type adminHandler struct {
+	authService service.AuthService
+	userService service.UserService
+}
+
+func NewAdminHandler(authService service.AuthService, userService service.UserService) AdminHandler {
+	return &adminHandler{
+		authService: authService,
+		userService: userService,
+	}
+}
+
+// @Summary Impersonate user
+// @Description Issue a short-lived access token acting as the target user. Money-moving endpoints are blocked for this token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID" format(uuid)
+// @Param request body request.ImpersonateRequest true "Reason for impersonation"
+// @Success 201 {object} response.APIResponse{data=response.ImpersonationResponse} "Impersonation token issued"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/users/{id}/impersonate [post]
+// @Security BearerAuth
+func (h *adminHandler) ImpersonateUser(c *gin.Context) {
+	var req request.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	actorID := utils.GetActorID(c)
+	result, err := h.authService.Impersonate(c, actorID, c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to impersonate user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Impersonation token issued",
+		Data:    result,
+	})
+}
+
+// @Summary List users
+// @Description Search users with combined filters, sorting and pagination
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param q query string false "Partial match on username or email"
+// @Param username query string false "Partial username"
+// @Param email query string false "Partial email"
+// @Param role query string false "customer or admin"
+// @Param status query string false "active or suspended"
+// @Param created_from query int false "Created at or after (unix millis)"
+// @Param created_to query int false "Created at or before (unix millis)"
+// @Param sort_by query string false "created_at, username or email" default(created_at)
+// @Param sort_order query string false "asc or desc" default(desc)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.AdminUserPagingResponse} "Users retrieved"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/users [get]
+// @Security BearerAuth
+func (h *adminHandler) ListUsers(c *gin.Context) {
+	params := service.AdminUserQueryParams{
+		Page:      utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:     utils.ParseIntWithDefault(c.Query("limit"), 20),
+		Query:     c.Query("q"),
+		Username:  c.Query("username"),
+		Email:     c.Query("email"),
+		Role:      c.Query("role"),
+		Status:    c.Query("status"),
+		SortBy:    c.Query("sort_by"),
+		SortOrder: c.Query("sort_order"),
+	}
+
+	var err error
+	if from := c.Query("created_from"); from != "" {
+		if params.CreatedFrom, err = strconv.ParseInt(from, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid created_from parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+	if to := c.Query("created_to"); to != "" {
+		if params.CreatedTo, err = strconv.ParseInt(to, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid created_to parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	users, err := h.userService.SearchUsers(c, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get users",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Users retrieved successfully",
+		Data:    users,
+	})
+}
+
+// @Summary Suspend user
+// @Description Block the user from signing in and end all of their sessions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID" format(uuid)
+// @Param request body request.SuspendUserRequest true "Reason for suspension"
+// @Success 200 {object} response.APIResponse{data=response.AdminUserResponse} "User suspended"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/users/{id}/suspend [post]
+// @Security BearerAuth
+func (h *adminHandler) SuspendUser(c *gin.Context) {
+	var req request.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.SuspendUser(c, utils.GetActorID(c), c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to suspend user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "User suspended successfully",
+		Data:    user,
+	})
+}
+
+// @Summary Reactivate user
+// @Description Lift a suspension. Sessions ended by the suspension stay ended.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.APIResponse{data=response.AdminUserResponse} "User reactivated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/users/{id}/reactivate [post]
+// @Security BearerAuth
+func (h *adminHandler) ReactivateUser(c *gin.Context) {
+	user, err := h.userService.ReactivateUser(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to reactivate user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "User reactivated successfully",
+		Data:    user,
+	})
+}
+
+// @Summary Force logout user
+// @Description Revoke every refresh token and invalidate outstanding access tokens of the user
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} response.APIResponse "User logged out"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/users/{id}/logout [post]
+// @Security BearerAuth
+func (h *adminHandler) ForceLogout(c *gin.Context) {
+	if err := h.userService.ForceLogout(c, c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to log out user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "User logged out successfully",
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApiTokenHandler interface {
+	CreateToken(c *gin.Context)
+	ListTokens(c *gin.Context)
+	RevokeToken(c *gin.Context)
+}
+
+type apiTokenHandler struct {
+	apiTokenService service.ApiTokenService
+}
+
+func NewApiTokenHandler(apiTokenService service.ApiTokenService) ApiTokenHandler {
+	return &apiTokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// @Summary Create API token
+// @Description Create a named, scoped personal access token. The token value is only returned once.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.CreateApiTokenRequest true "Token details"
+// @Success 201 {object} response.APIResponse{data=response.CreatedApiTokenResponse} "Token created"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/tokens [post]
+// @Security BearerAuth
+func (h *apiTokenHandler) CreateToken(c *gin.Context) {
+	var req request.CreateApiTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	token, err := h.apiTokenService.CreateToken(c, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create token",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Token created successfully",
+		Data:    token,
+	})
+}
+
+// @Summary List API tokens
+// @Description List the current user's personal access tokens
+// @Tags tokens
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]response.ApiTokenResponse} "Tokens retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/tokens [get]
+// @Security BearerAuth
+func (h *apiTokenHandler) ListTokens(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	tokens, err := h.apiTokenService.ListTokens(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get tokens",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Tokens retrieved successfully",
+		Data:    tokens,
+	})
+}
+
+// @Summary Revoke API token
+// @Description Revoke one of the current user's personal access tokens
+// @Tags tokens
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Token ID" format(uuid)
+// @Success 200 {object} response.APIResponse "Token revoked"
+// @Failure 404 {object} response.APIResponse "Token not found"
+// @Router /user/tokens/{id} [delete]
+// @Security BearerAuth
+func (h *apiTokenHandler) RevokeToken(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	tokenID := c.Param("id")
+
+	if err := h.apiTokenService.RevokeToken(c, userID, tokenID); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to revoke token",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Token revoked successfully",
+	})
+}
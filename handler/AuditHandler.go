@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler interface {
+	QueryEvents(c *gin.Context)
+	GetSecurityActivity(c *gin.Context)
+}
+
+type auditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) AuditHandler {
+	return &auditHandler{
+		auditService: auditService,
+	}
+}
+
+// @Summary Query audit events
+// @Description Search the security audit log
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param actor_id query string false "Actor user ID"
+// @Param action query string false "Action, e.g. auth.login"
+// @Param target_type query string false "Target type, e.g. user, account, order"
+// @Param target_id query string false "Target ID"
+// @Param outcome query string false "success or failure"
+// @Param from query int false "Created at or after (unix millis)"
+// @Param to query int false "Created at or before (unix millis)"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.AuditPagingResponse} "Events retrieved"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /admin/audit-events [get]
+// @Security BearerAuth
+func (h *auditHandler) QueryEvents(c *gin.Context) {
+	params := service.AuditQueryParams{
+		Page:       utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:      utils.ParseIntWithDefault(c.Query("limit"), 20),
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Outcome:    c.Query("outcome"),
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if params.From, err = strconv.ParseInt(from, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid from parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if params.To, err = strconv.ParseInt(to, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid to parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	events, err := h.auditService.QueryEvents(c, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get audit events",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Audit events retrieved successfully",
+		Data:    events,
+	})
+}
+
+// @Summary Recent security activity
+// @Description List recent logins, failed logins, token refreshes and logouts for the current user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "Number of events" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=[]model.AuditEvent} "Activity retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/me/security-activity [get]
+// @Security BearerAuth
+func (h *auditHandler) GetSecurityActivity(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	limit := utils.ParseIntWithDefault(c.Query("limit"), 20)
+
+	events, err := h.auditService.RecentSecurityActivity(c, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get security activity",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Security activity retrieved successfully",
+		Data:    events,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/service"
-	"nuxatech-nextmedis/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -153,9 +152,27 @@ func (h *authHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// @Summary Logout
+// @Description Revoke the given refresh token
+// @Tags auth
+// @Produce json
+// @Param Authorization header string true "Bearer refresh_token"
+// @Success 200 {object} response.APIResponse "Logged out"
+// @Failure 401 {object} response.APIResponse "Invalid refresh token"
+// @Router /auth/logout [delete]
+// @Security BearerAuth
 func (h *authHandler) Logout(c *gin.Context) {
-	userID := utils.GetUserID(c)
-	err := h.authService.Logout(c, userID)
+	splitToken := strings.Split(c.GetHeader("Authorization"), "Bearer ")
+	if len(splitToken) != 2 {
+		c.JSON(http.StatusUnauthorized, response.APIResponse{
+			Success: false,
+			Message: "Invalid token format",
+			Error:   "invalid token format",
+		})
+		return
+	}
+
+	err := h.authService.Logout(c, splitToken[1])
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.APIResponse{
 			Success: false,
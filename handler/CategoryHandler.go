@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler interface {
+	GetCategoryTree(c *gin.Context)
+	GetCategoryProducts(c *gin.Context)
+	CreateCategory(c *gin.Context)
+	UpdateCategory(c *gin.Context)
+	MoveCategory(c *gin.Context)
+	DeleteCategory(c *gin.Context)
+}
+
+type categoryHandler struct {
+	categoryService service.CategoryService
+	productService  service.ProductService
+}
+
+func NewCategoryHandler(categoryService service.CategoryService, productService service.ProductService) CategoryHandler {
+	return &categoryHandler{
+		categoryService: categoryService,
+		productService:  productService,
+	}
+}
+
+// @Summary Get category tree
+// @Description Get all categories nested under their parents, ordered by position
+// @Tags categories
+// @Produce json
+// @Success 200 {object} response.APIResponse{data=[]response.CategoryResponse} "Categories retrieved"
+// @Router /category [get]
+func (h *categoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.categoryService.GetCategoryTree(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get categories",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondWithContentETag(c, response.APIResponse{
+		Success: true,
+		Message: "Categories retrieved successfully",
+		Data:    tree,
+	})
+}
+
+// @Summary Get category products
+// @Description Get paginated products of a category, including its subcategories
+// @Tags categories
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(10) minimum(1) maximum(100)
+// @Param search query string false "Search term"
+// @Param min_price query int false "Minimum price"
+// @Param max_price query int false "Maximum price"
+// @Param in_stock query bool false "Only products in stock"
+// @Param sort query string false "Sort order" Enums(relevance, price_asc, price_desc, newest, best_selling, name_asc, name_desc)
+// @Param facets query bool false "Include facet counts"
+// @Success 200 {object} response.APIResponse{data=response.ProductPagingResponse} "Products retrieved"
+// @Failure 400 {object} response.APIResponse "Invalid parameters"
+// @Failure 404 {object} response.APIResponse "Category not found"
+// @Router /category/{slug}/products [get]
+func (h *categoryHandler) GetCategoryProducts(c *gin.Context) {
+	params, err := parseProductQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	params.Category = c.Param("slug")
+
+	products, err := h.productService.GetAllProducts(c, params)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get category products",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondWithContentETag(c, response.APIResponse{
+		Success: true,
+		Message: "Success to get product",
+		Data:    products,
+	})
+}
+
+// @Summary Create category
+// @Description Create a category, optionally under a parent
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.CreateCategoryRequest true "Category details"
+// @Success 201 {object} response.APIResponse{data=model.Category} "Category created"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Forbidden"
+// @Router /admin/categories [post]
+// @Security BearerAuth
+func (h *categoryHandler) CreateCategory(c *gin.Context) {
+	var req request.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+// @Summary Update category
+// @Description Update a category's name, slug, description or position
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Category ID"
+// @Param request body request.UpdateCategoryRequest true "Fields to update"
+// @Success 200 {object} response.APIResponse{data=model.Category} "Category updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /admin/categories/{id} [patch]
+// @Security BearerAuth
+func (h *categoryHandler) UpdateCategory(c *gin.Context) {
+	var req request.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+// @Summary Move category
+// @Description Move a category and its subtree under another parent, or to the root when parent_id is null
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Category ID"
+// @Param request body request.MoveCategoryRequest true "New parent and position"
+// @Success 200 {object} response.APIResponse{data=model.Category} "Category moved"
+// @Failure 400 {object} response.APIResponse "Invalid move"
+// @Router /admin/categories/{id}/move [post]
+// @Security BearerAuth
+func (h *categoryHandler) MoveCategory(c *gin.Context) {
+	var req request.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	category, err := h.categoryService.MoveCategory(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to move category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Category moved successfully",
+		Data:    category,
+	})
+}
+
+// @Summary Delete category
+// @Description Delete a category without subcategories. Its products are kept.
+// @Tags categories
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.APIResponse "Category deleted"
+// @Failure 400 {object} response.APIResponse "Category still has subcategories"
+// @Router /admin/categories/{id} [delete]
+// @Security BearerAuth
+func (h *categoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.categoryService.DeleteCategory(c, c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Category deleted successfully",
+	})
+}
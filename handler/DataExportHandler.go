@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DataExportHandler interface {
+	RequestExport(c *gin.Context)
+	GetExport(c *gin.Context)
+	Download(c *gin.Context)
+}
+
+type dataExportHandler struct {
+	exportService service.DataExportService
+}
+
+func NewDataExportHandler(exportService service.DataExportService) DataExportHandler {
+	return &dataExportHandler{
+		exportService: exportService,
+	}
+}
+
+// @Summary Request personal data export
+// @Description Queue a zip archive (JSON and CSV) of everything held about the current user. The user is notified when it is ready.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 202 {object} response.APIResponse{data=response.DataExportResponse} "Export queued"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/me/export [post]
+// @Security BearerAuth
+func (h *dataExportHandler) RequestExport(c *gin.Context) {
+	userID := utils.GetUserID(c)
+
+	export, err := h.exportService.RequestExport(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to request export",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.APIResponse{
+		Success: true,
+		Message: "Export requested successfully",
+		Data:    export,
+	})
+}
+
+// @Summary Get personal data export
+// @Description Get the status of a data export, including a signed download link once ready
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Export ID"
+// @Success 200 {object} response.APIResponse{data=response.DataExportResponse} "Export retrieved"
+// @Failure 404 {object} response.APIResponse "Export not found"
+// @Router /user/me/export/{id} [get]
+// @Security BearerAuth
+func (h *dataExportHandler) GetExport(c *gin.Context) {
+	userID := utils.GetUserID(c)
+
+	export, err := h.exportService.GetExport(c, userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get export",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Export retrieved successfully",
+		Data:    export,
+	})
+}
+
+// @Summary Download personal data export
+// @Description Download an export archive through a signed, time-limited link
+// @Tags users
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param expires query int true "Link expiry (unix millis)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file "Export archive"
+// @Failure 403 {object} response.APIResponse "Invalid or expired link"
+// @Router /exports/{id}/download [get]
+func (h *dataExportHandler) Download(c *gin.Context) {
+	export, err := h.exportService.OpenDownload(c, c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.APIResponse{
+			Success: false,
+			Message: "Failed to download export",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.FileAttachment(export.FilePath, "data-export-"+export.ID+".zip")
+}
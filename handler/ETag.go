@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithETag writes body with the given ETag, or a bare 304 when the
+// client's If-None-Match already holds it.
+func respondWithETag(c *gin.Context, etag string, body response.APIResponse) {
+	c.Header("ETag", etag)
+	if utils.ETagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// respondWithContentETag is respondWithETag for unversioned responses such as
+// listings, the weak ETag is derived from the encoded body.
+func respondWithContentETag(c *gin.Context, body response.APIResponse) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to encode response",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	etag := utils.ContentETag(data)
+	c.Header("ETag", etag)
+	if utils.ETagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ifMatchVersion reads the version from the If-Match header that mutating
+// requests must send. When it returns false the response has been written.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, response.APIResponse{
+			Success: false,
+			Message: "If-Match header is required",
+			Error:   "send the ETag from the last GET in If-Match",
+		})
+		return 0, false
+	}
+
+	version, err := utils.ParseVersionETag(header)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, response.APIResponse{
+			Success: false,
+			Message: "Precondition failed",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+
+	return version, true
+}
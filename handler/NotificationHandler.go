@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler interface {
+	GetNotifications(c *gin.Context)
+	MarkRead(c *gin.Context)
+}
+
+type notificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) NotificationHandler {
+	return &notificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// @Summary List notifications
+// @Description List the current user's notifications, newest first
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.NotificationPagingResponse} "Notifications retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/notifications [get]
+// @Security BearerAuth
+func (h *notificationHandler) GetNotifications(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	params := service.NotificationQueryParams{
+		Page:       utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:      utils.ParseIntWithDefault(c.Query("limit"), 20),
+		UnreadOnly: c.Query("unread") == "true",
+	}
+
+	notifications, err := h.notificationService.GetNotifications(c, userID, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get notifications",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Notifications retrieved successfully",
+		Data:    notifications,
+	})
+}
+
+// @Summary Mark notification as read
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Notification ID"
+// @Success 200 {object} response.APIResponse "Notification marked as read"
+// @Failure 404 {object} response.APIResponse "Notification not found"
+// @Router /user/notifications/{id}/read [post]
+// @Security BearerAuth
+func (h *notificationHandler) MarkRead(c *gin.Context) {
+	userID := utils.GetUserID(c)
+
+	if err := h.notificationService.MarkRead(c, userID, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to mark notification as read",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Notification marked as read",
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler interface {
+	Login(c *gin.Context)
+	Callback(c *gin.Context)
+	ListIdentities(c *gin.Context)
+}
+
+type oidcHandler struct {
+	oidcService service.OIDCService
+}
+
+// oidcLoginCookie ties a callback to the browser that started the login.
+const (
+	oidcLoginCookie     = "oidc_login"
+	oidcLoginCookiePath = "/api/v1/auth/oidc"
+)
+
+func NewOIDCHandler(oidcService service.OIDCService) OIDCHandler {
+	return &oidcHandler{
+		oidcService: oidcService,
+	}
+}
+
+// @Summary Start OIDC login
+// @Description Redirect to the external identity provider. Pass redirect=false to get the URL as JSON instead. The login must be completed by the same browser, which keeps the login cookie set here.
+// @Tags auth
+// @Produce json
+// @Param redirect query bool false "Redirect to the provider" default(true)
+// @Success 200 {object} response.APIResponse{data=response.OIDCLoginResponse} "Authorization URL"
+// @Success 302 "Redirect to identity provider"
+// @Failure 503 {object} response.APIResponse "OIDC login not configured"
+// @Router /auth/oidc/login [get]
+func (h *oidcHandler) Login(c *gin.Context) {
+	result, loginState, err := h.oidcService.AuthorizationURL(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, response.APIResponse{
+			Success: false,
+			Message: "OIDC login unavailable",
+			Error:   err.Error(),
+		})
+		return
+	}
+	setOIDCLoginCookie(c, loginState, int(service.OIDCLoginTTL.Seconds()))
+
+	if c.Query("redirect") == "false" {
+		c.JSON(http.StatusOK, response.APIResponse{
+			Success: true,
+			Message: "Authorization URL created",
+			Data:    result,
+		})
+		return
+	}
+
+	c.Redirect(http.StatusFound, result.AuthorizationURL)
+}
+
+// @Summary OIDC callback
+// @Description Complete the authorization code flow and issue a token pair
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} response.APIResponse{data=response.LoginResponse} "Login successful"
+// @Failure 401 {object} response.APIResponse "Login failed"
+// @Router /auth/oidc/callback [get]
+func (h *oidcHandler) Callback(c *gin.Context) {
+	loginState, _ := c.Cookie(oidcLoginCookie)
+	setOIDCLoginCookie(c, "", -1)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, response.APIResponse{
+			Success: false,
+			Message: "Login failed",
+			Error:   providerErr,
+		})
+		return
+	}
+
+	result, err := h.oidcService.HandleCallback(c.Request.Context(), c.Query("code"), c.Query("state"), loginState)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.APIResponse{
+			Success: false,
+			Message: "Login failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Login successful",
+		Data:    result,
+	})
+}
+
+// setOIDCLoginCookie sets the login cookie, or clears it with a negative
+// maxAge. It is sent on the top-level redirect back from the provider.
+func setOIDCLoginCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.HasPrefix(config.Envs.OIDCRedirectURL, "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcLoginCookie, value, maxAge, oidcLoginCookiePath, "", secure, true)
+}
+
+// @Summary List linked identities
+// @Description List external identity provider accounts linked to the current user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]response.UserIdentityResponse} "Identities retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/identities [get]
+// @Security BearerAuth
+func (h *oidcHandler) ListIdentities(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	identities, err := h.oidcService.ListIdentities(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get identities",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Identities retrieved successfully",
+		Data:    identities,
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
@@ -16,6 +17,8 @@ type OrderHandler interface {
 	GetOrder(c *gin.Context)
 	UpdateOrderStatus(c *gin.Context)
 	GetUserOrders(c *gin.Context)
+	AdminGetOrder(c *gin.Context)
+	AdminUpdateOrderStatus(c *gin.Context)
 }
 
 type orderHandler struct {
@@ -75,7 +78,9 @@ func (h *orderHandler) CreateOrder(c *gin.Context) {
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Order ID" format(uuid)
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.APIResponse{data=response.OrderResponse} "Order retrieved successfully"
+// @Success 304 "Not modified"
 // @Failure 401 {object} response.APIResponse "Unauthorized"
 // @Failure 404 {object} response.APIResponse "Order not found"
 // @Router /orders/{id} [get]
@@ -85,6 +90,28 @@ func (h *orderHandler) GetOrder(c *gin.Context) {
 	orderID := c.Param("id")
 
 	order, err := h.orderService.GetOrder(c, userID, orderID)
+	h.respondWithOrder(c, order, err)
+}
+
+// @Summary Get any order
+// @Description Get an order of any user, e.g. to get its ETag before changing its status
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID" format(uuid)
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} response.APIResponse{data=response.OrderResponse} "Order retrieved successfully"
+// @Success 304 "Not modified"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Failure 404 {object} response.APIResponse "Order not found"
+// @Router /admin/orders/{id} [get]
+// @Security BearerAuth
+func (h *orderHandler) AdminGetOrder(c *gin.Context) {
+	order, err := h.orderService.AdminGetOrder(c, c.Param("id"))
+	h.respondWithOrder(c, order, err)
+}
+
+func (h *orderHandler) respondWithOrder(c *gin.Context, order *response.OrderResponse, err error) {
 	if err != nil {
 		c.JSON(http.StatusNotFound, response.APIResponse{
 			Success: false,
@@ -94,28 +121,60 @@ func (h *orderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.APIResponse{
+	respondWithETag(c, utils.VersionETag(order.Version), response.APIResponse{
 		Success: true,
 		Message: "Order retrieved successfully",
 		Data:    order,
 	})
 }
 
-// @Summary Update order status
-// @Description Update the status of an existing order
+// @Summary Cancel order
+// @Description Cancel a pending order. Other status changes are made by admins.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Order ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being updated"
 // @Param request body request.UpdateOrderStatusRequest true "Status update request"
 // @Success 200 {object} response.APIResponse{data=response.OrderResponse} "Order status updated successfully"
 // @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 412 {object} response.APIResponse "Order has been modified"
+// @Failure 428 {object} response.APIResponse "If-Match header is required"
 // @Failure 401 {object} response.APIResponse "Unauthorized"
 // @Failure 404 {object} response.APIResponse "Order not found"
 // @Router /orders/{id}/status [put]
 // @Security BearerAuth
 func (h *orderHandler) UpdateOrderStatus(c *gin.Context) {
+	userID := utils.GetUserID(c)
+	h.updateOrderStatus(c, func(orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+		return h.orderService.UpdateOrderStatus(c, userID, orderID, version, req)
+	})
+}
+
+// @Summary Update order status
+// @Description Mark any order paid, shipped, complete or canceled. Paying an order commits its stock and assigns its license codes.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param request body request.UpdateOrderStatusRequest true "Status update request"
+// @Success 200 {object} response.APIResponse{data=response.OrderResponse} "Order status updated successfully"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Failure 412 {object} response.APIResponse "Order has been modified"
+// @Failure 428 {object} response.APIResponse "If-Match header is required"
+// @Router /admin/orders/{id}/status [put]
+// @Security BearerAuth
+func (h *orderHandler) AdminUpdateOrderStatus(c *gin.Context) {
+	h.updateOrderStatus(c, func(orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+		return h.orderService.AdminUpdateOrderStatus(c, orderID, version, req)
+	})
+}
+
+func (h *orderHandler) updateOrderStatus(c *gin.Context, update func(orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error)) {
 	var req request.UpdateOrderStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, response.APIResponse{
@@ -126,10 +185,20 @@ func (h *orderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	userID := utils.GetUserID(c)
-	orderID := c.Param("id")
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
-	order, err := h.orderService.UpdateOrderStatus(c, userID, orderID, &req)
+	order, err := update(c.Param("id"), version, &req)
+	if errors.Is(err, service.ErrVersionMismatch) {
+		c.JSON(http.StatusPreconditionFailed, response.APIResponse{
+			Success: false,
+			Message: "Order has been modified",
+			Error:   err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.APIResponse{
 			Success: false,
@@ -139,6 +208,7 @@ func (h *orderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", utils.VersionETag(order.Version))
 	c.JSON(http.StatusOK, response.APIResponse{
 		Success: true,
 		Message: "Order status updated successfully",
@@ -155,7 +225,11 @@ func (h *orderHandler) UpdateOrderStatus(c *gin.Context) {
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(10) minimum(1) maximum(100)
 // @Param search query string false "Search term"
+// @Param pagination query string false "Set to cursor for keyset pagination" Enums(page, cursor)
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page"
+// @Param include_total query bool false "Count all orders in cursor mode"
 // @Success 200 {object} response.APIResponse{data=response.OrderPagingResponse} "Orders retrieved successfully"
+// @Failure 400 {object} response.APIResponse "Invalid cursor"
 // @Failure 401 {object} response.APIResponse "Unauthorized"
 // @Failure 404 {object} response.APIResponse "No orders found"
 // @Router /orders [get]
@@ -164,14 +238,15 @@ func (h *orderHandler) GetUserOrders(c *gin.Context) {
 	userID := utils.GetUserID(c)
 
 	params := service.ProductQueryParams{
-		Page:   utils.ParseIntWithDefault(c.Query("page"), 1),
-		Limit:  utils.ParseIntWithDefault(c.Query("limit"), 10),
-		Search: c.Query("search"),
+		CursorParams: parseCursorParams(c),
+		Page:         utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:        utils.ParseIntWithDefault(c.Query("limit"), 10),
+		Search:       c.Query("search"),
 	}
 
 	orders, err := h.orderService.GetUserOrders(c, userID, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.APIResponse{
+		c.JSON(listErrorStatus(err), response.APIResponse{
 			Success: false,
 			Message: "Failed to get orders",
 			Error:   err.Error(),
@@ -187,7 +262,7 @@ func (h *orderHandler) GetUserOrders(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.APIResponse{
+	respondWithContentETag(c, response.APIResponse{
 		Success: true,
 		Message: "Orders retrieved successfully",
 		Data:    orders,
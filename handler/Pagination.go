@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseCursorParams reads the cursor pagination parameters. pagination=cursor
+// requests the first page, later pages pass back next_cursor or prev_cursor.
+func parseCursorParams(c *gin.Context) service.CursorParams {
+	return service.CursorParams{
+		UseCursor:    c.Query("pagination") == "cursor",
+		Cursor:       c.Query("cursor"),
+		IncludeTotal: c.Query("include_total") == "true",
+	}
+}
+
+// listErrorStatus tells client mistakes such as a bad cursor apart from
+// server errors.
+func listErrorStatus(err error) int {
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
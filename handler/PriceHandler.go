@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHandler interface {
+	GetPriceHistory(c *gin.Context)
+	GetSchedules(c *gin.Context)
+	CreateSchedule(c *gin.Context)
+	CancelSchedule(c *gin.Context)
+}
+
+type priceHandler struct {
+	priceService service.PriceService
+}
+
+func NewPriceHandler(priceService service.PriceService) PriceHandler {
+	return &priceHandler{
+		priceService: priceService,
+	}
+}
+
+// @Summary Get product price history
+// @Description List the price changes of a product and its variants, newest first
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param variant_id query string false "Only changes of this variant"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.PriceChangePagingResponse} "Price history retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/prices [get]
+// @Security BearerAuth
+func (h *priceHandler) GetPriceHistory(c *gin.Context) {
+	params := service.PriceHistoryParams{
+		Page:      utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:     utils.ParseIntWithDefault(c.Query("limit"), 20),
+		VariantID: c.Query("variant_id"),
+	}
+
+	history, err := h.priceService.GetPriceHistory(c, c.Param("id"), params)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get price history",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Price history retrieved successfully",
+		Data:    history,
+	})
+}
+
+// @Summary List price schedules
+// @Description List the upcoming and running price schedules of a product by start time
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param all query bool false "Include ended and canceled schedules"
+// @Success 200 {object} response.APIResponse{data=[]model.PriceSchedule} "Price schedules retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/price-schedules [get]
+// @Security BearerAuth
+func (h *priceHandler) GetSchedules(c *gin.Context) {
+	schedules, err := h.priceService.GetSchedules(c, c.Param("id"), c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get price schedules",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Price schedules retrieved successfully",
+		Data:    schedules,
+	})
+}
+
+// @Summary Schedule a price change
+// @Description Set the price of a product, or of one of its variants, at starts_at. With ends_at it is a sale: the regular price is shown as compare_at_price while it runs and comes back at ends_at. Schedules of the same product or variant cannot overlap, and one starting now or in the past applies at once.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.CreatePriceScheduleRequest true "Price schedule"
+// @Success 201 {object} response.APIResponse{data=model.PriceSchedule} "Price change scheduled"
+// @Failure 400 {object} response.APIResponse "Invalid or overlapping schedule"
+// @Router /product/{id}/price-schedules [post]
+// @Security BearerAuth
+func (h *priceHandler) CreateSchedule(c *gin.Context) {
+	var req request.CreatePriceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.priceService.CreateSchedule(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to schedule price change",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Price change scheduled successfully",
+		Data:    schedule,
+	})
+}
+
+// @Summary Cancel a price schedule
+// @Description Drop a schedule that has not started, or end a running sale now and restore the regular price
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param scheduleId path string true "Price schedule ID"
+// @Success 200 {object} response.APIResponse{data=model.PriceSchedule} "Price schedule canceled"
+// @Failure 400 {object} response.APIResponse "Schedule not found or already ended"
+// @Router /product/{id}/price-schedules/{scheduleId} [delete]
+// @Security BearerAuth
+func (h *priceHandler) CancelSchedule(c *gin.Context) {
+	schedule, err := h.priceService.CancelSchedule(c, c.Param("id"), c.Param("scheduleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to cancel price schedule",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Price schedule canceled successfully",
+		Data:    schedule,
+	})
+}
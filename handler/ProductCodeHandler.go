@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductCodeHandler interface {
+	UploadCodes(c *gin.Context)
+	GetCodes(c *gin.Context)
+	VoidCode(c *gin.Context)
+	ReissueCode(c *gin.Context)
+}
+
+type productCodeHandler struct {
+	codeService service.ProductCodeService
+}
+
+func NewProductCodeHandler(codeService service.ProductCodeService) ProductCodeHandler {
+	return &productCodeHandler{
+		codeService: codeService,
+	}
+}
+
+// @Summary Upload redemption codes
+// @Description Add codes to the pool of a digital product, either as JSON or as a text file with one code per line in the "file" field. At most 10000 codes per upload. Codes the product already has are skipped, every code added is a unit of stock.
+// @Tags products
+// @Accept json,multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.UploadProductCodesRequest false "Codes"
+// @Param file formData file false "Text file with one code per line"
+// @Success 201 {object} response.APIResponse{data=response.ProductCodeUploadResponse} "Codes uploaded"
+// @Failure 400 {object} response.APIResponse "Invalid codes or product is not digital"
+// @Failure 413 {object} response.APIResponse "Upload too large"
+// @Router /product/{id}/codes [post]
+// @Security BearerAuth
+func (h *productCodeHandler) UploadCodes(c *gin.Context) {
+	var result *response.ProductCodeUploadResponse
+	var err error
+
+	if c.ContentType() == "multipart/form-data" {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(config.Envs.UploadMaxBytes)+1<<20)
+		file, formErr := c.FormFile("file")
+		if formErr != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(formErr, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, response.APIResponse{
+					Success: false,
+					Message: "Upload too large",
+					Error:   formErr.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid request",
+				Error:   formErr.Error(),
+			})
+			return
+		}
+		result, err = h.codeService.UploadCodeFile(c, c.Param("id"), file)
+	} else {
+		var req request.UploadProductCodesRequest
+		if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid request",
+				Error:   bindErr.Error(),
+			})
+			return
+		}
+		result, err = h.codeService.UploadCodes(c, c.Param("id"), req)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to upload codes",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Codes uploaded successfully",
+		Data:    result,
+	})
+}
+
+// @Summary List redemption codes
+// @Description List the codes of a digital product, newest first. Codes are masked except for their last characters.
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param status query string false "available, assigned or voided"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.ProductCodePagingResponse} "Codes retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/codes [get]
+// @Security BearerAuth
+func (h *productCodeHandler) GetCodes(c *gin.Context) {
+	params := service.ProductCodeParams{
+		Page:   utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:  utils.ParseIntWithDefault(c.Query("limit"), 20),
+		Status: c.Query("status"),
+	}
+
+	codes, err := h.codeService.GetCodes(c, c.Param("id"), params)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get codes",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Codes retrieved successfully",
+		Data:    codes,
+	})
+}
+
+// @Summary Void a redemption code
+// @Description Withdraw an available code from the pool, e.g. one that leaked. The product loses a unit of stock.
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param codeId path string true "Code ID"
+// @Success 200 {object} response.APIResponse{data=response.ProductCodeResponse} "Code voided"
+// @Failure 400 {object} response.APIResponse "Code is not available"
+// @Router /product/{id}/codes/{codeId}/void [post]
+// @Security BearerAuth
+func (h *productCodeHandler) VoidCode(c *gin.Context) {
+	code, err := h.codeService.VoidCode(c, c.Param("id"), c.Param("codeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to void code",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Code voided successfully",
+		Data:    code,
+	})
+}
+
+// @Summary Reissue a redemption code
+// @Description Void a code assigned to an order and assign the order item a replacement from the pool. The buyer sees the new code in their order detail.
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param codeId path string true "Code ID"
+// @Success 200 {object} response.APIResponse{data=response.ProductCodeResponse} "Code reissued"
+// @Failure 400 {object} response.APIResponse "Code is not assigned or the pool is empty"
+// @Router /product/{id}/codes/{codeId}/reissue [post]
+// @Security BearerAuth
+func (h *productCodeHandler) ReissueCode(c *gin.Context) {
+	code, err := h.codeService.ReissueCode(c, c.Param("id"), c.Param("codeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to reissue code",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Code reissued successfully",
+		Data:    code,
+	})
+}
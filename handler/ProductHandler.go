@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/service"
 	"nuxatech-nextmedis/utils"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +17,11 @@ type ProductHandler interface {
 	GetAllProducts(c *gin.Context)
 	CreateProduct(c *gin.Context)
 	GetProduct(c *gin.Context)
+	ReplaceProduct(c *gin.Context)
+	UpdateProduct(c *gin.Context)
+	DeleteProduct(c *gin.Context)
+	RestoreProduct(c *gin.Context)
+	SuggestProducts(c *gin.Context)
 }
 
 type productHandler struct {
@@ -27,7 +34,9 @@ type productHandler struct {
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID" format(uuid)
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.APIResponse{data=response.ProductResponse} "Product retrieved"
+// @Success 304 "Not modified"
 // @Failure 404 {object} response.APIResponse "Product not found"
 // @Router /product/{id} [get]
 func (p *productHandler) GetProduct(c *gin.Context) {
@@ -43,7 +52,7 @@ func (p *productHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.APIResponse{
+	respondWithETag(c, utils.VersionETag(product.Version), response.APIResponse{
 		Success: true,
 		Data:    product,
 		Message: "Success to get product",
@@ -100,28 +109,118 @@ func (p *productHandler) CreateProduct(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(10) minimum(1) maximum(100)
-// @Param search query string false "Search term"
+// @Param search query string false "Search term, results are ordered by relevance and carry highlighted snippets"
+// @Param category query string false "Category slug, includes subcategories"
+// @Param min_price query int false "Minimum price"
+// @Param max_price query int false "Maximum price"
+// @Param in_stock query bool false "Only products in stock"
+// @Param attr[name] query string false "Variant option filter, e.g. attr[Color]=Red"
+// @Param created_after query int false "Only products created after this time (unix millis)"
+// @Param sort query string false "Sort order" Enums(relevance, price_asc, price_desc, newest, best_selling, name_asc, name_desc)
+// @Param facets query bool false "Include facet counts"
+// @Param pagination query string false "Set to cursor for keyset pagination, supported by the newest, price and name sorts" Enums(page, cursor)
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page"
+// @Param include_total query bool false "Count all matches in cursor mode"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.APIResponse{data=response.ProductPagingResponse} "Products retrieved"
+// @Success 304 "Not modified"
 // @Failure 400 {object} response.APIResponse "Invalid parameters"
 // @Router /products [get]
 func (p *productHandler) GetAllProducts(c *gin.Context) {
-	params := service.ProductQueryParams{
-		Page:   utils.ParseIntWithDefault(c.Query("page"), 1),
-		Limit:  utils.ParseIntWithDefault(c.Query("limit"), 10),
-		Search: c.Query("search"),
+	params, err := parseProductQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid parameters",
+			Error:   err.Error(),
+		})
+		return
 	}
+	params.Category = c.Query("category")
 
 	products, err := p.productService.GetAllProducts(c, params)
-	if len(products.Result) == 0 {
+	if err != nil {
+		c.JSON(listErrorStatus(err), response.APIResponse{
+			Success: false,
+			Message: "Failed to get product data",
+			Error:   err.Error(),
+		})
+		return
+	} else if len(products.Result) == 0 {
 		c.JSON(http.StatusNotFound, response.APIResponse{
 			Success: false,
 			Message: "No product found",
 		})
 		return
-	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, response.APIResponse{
+	}
+
+	respondWithContentETag(c, response.APIResponse{
+		Success: true,
+		Data:    products,
+		Message: "Success to get product data",
+	})
+
+}
+
+// parseProductQuery reads the catalog listing parameters shared by product
+// and category listings.
+func parseProductQuery(c *gin.Context) (service.ProductQueryParams, error) {
+	params := service.ProductQueryParams{
+		CursorParams: parseCursorParams(c),
+		Page:         utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:        utils.ParseIntWithDefault(c.Query("limit"), 10),
+		Search:       c.Query("search"),
+		InStock:      c.Query("in_stock") == "true",
+		Attributes:   c.QueryMap("attr"),
+		Sort:         c.Query("sort"),
+		WithFacets:   c.Query("facets") == "true",
+	}
+
+	var err error
+	if params.MinPrice, err = parsePriceQuery(c, "min_price"); err != nil {
+		return params, err
+	}
+	if params.MaxPrice, err = parsePriceQuery(c, "max_price"); err != nil {
+		return params, err
+	}
+	if after := c.Query("created_after"); after != "" {
+		createdAfter, err := strconv.ParseInt(after, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid created_after parameter")
+		}
+		params.CreatedAfter = createdAfter
+	}
+
+	return params, nil
+}
+
+func parsePriceQuery(c *gin.Context, key string) (*int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.Atoi(value)
+	if err != nil || price < 0 {
+		return nil, fmt.Errorf("invalid %s parameter", key)
+	}
+	return &price, nil
+}
+
+// @Summary Product search suggestions
+// @Description Autocomplete product names, prefix matches first, then fuzzy matches
+// @Tags products
+// @Produce json
+// @Param q query string true "Partial search input" minlength(2)
+// @Param limit query int false "Number of suggestions" default(5) minimum(1) maximum(20)
+// @Success 200 {object} response.APIResponse{data=[]response.ProductSuggestion} "Suggestions retrieved"
+// @Failure 400 {object} response.APIResponse "Input too short"
+// @Router /product/suggestions [get]
+func (p *productHandler) SuggestProducts(c *gin.Context) {
+	suggestions, err := p.productService.SuggestProducts(c, c.Query("q"), utils.ParseIntWithDefault(c.Query("limit"), 5))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
 			Success: false,
-			Message: "Failed to get product data",
+			Message: "Failed to get suggestions",
 			Error:   err.Error(),
 		})
 		return
@@ -129,10 +228,176 @@ func (p *productHandler) GetAllProducts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.APIResponse{
 		Success: true,
-		Data:    products,
-		Message: "Success to get product data",
+		Data:    suggestions,
+		Message: "Success to get suggestions",
 	})
+}
 
+// @Summary Replace product
+// @Description Replace every editable field of a product. The slug follows the name.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being replaced"
+// @Param request body request.CreateProductRequest true "Product details"
+// @Success 200 {object} response.APIResponse{data=response.ProductResponse} "Product updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 412 {object} response.APIResponse "Product has been modified"
+// @Failure 428 {object} response.APIResponse "If-Match header is required"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /product/{id} [put]
+// @Security BearerAuth
+func (p *productHandler) ReplaceProduct(c *gin.Context) {
+	var req request.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	update := req.ToUpdate()
+	p.updateProduct(c, &update)
+}
+
+// @Summary Update product
+// @Description Partially update a product. Changing the name regenerates the slug.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param request body request.UpdateProductRequest true "Fields to change"
+// @Success 200 {object} response.APIResponse{data=response.ProductResponse} "Product updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 412 {object} response.APIResponse "Product has been modified"
+// @Failure 428 {object} response.APIResponse "If-Match header is required"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /product/{id} [patch]
+// @Security BearerAuth
+func (p *productHandler) UpdateProduct(c *gin.Context) {
+	var req request.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	p.updateProduct(c, &req)
+}
+
+func (p *productHandler) updateProduct(c *gin.Context, req *request.UpdateProductRequest) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	product, err := p.productService.UpdateProduct(c, c.Param("id"), version, req)
+	if errors.Is(err, service.ErrVersionMismatch) {
+		c.JSON(http.StatusPreconditionFailed, response.APIResponse{
+			Success: false,
+			Message: "Product has been modified",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update product",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", utils.VersionETag(product.Version))
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Data:    product,
+		Message: "Success to update product",
+	})
+}
+
+// @Summary Delete product
+// @Description Soft-delete a product, hiding it from listings and carts
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being deleted"
+// @Success 200 {object} response.APIResponse "Product deleted"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Failure 412 {object} response.APIResponse "Product has been modified"
+// @Failure 428 {object} response.APIResponse "If-Match header is required"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /product/{id} [delete]
+// @Security BearerAuth
+func (p *productHandler) DeleteProduct(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := p.productService.DeleteProduct(c, c.Param("id"), version)
+	if errors.Is(err, service.ErrVersionMismatch) {
+		c.JSON(http.StatusPreconditionFailed, response.APIResponse{
+			Success: false,
+			Message: "Product has been modified",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete product",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Success to delete product",
+	})
+}
+
+// @Summary Restore product
+// @Description Restore a soft-deleted product
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID" format(uuid)
+// @Success 200 {object} response.APIResponse{data=response.ProductResponse} "Product restored"
+// @Failure 404 {object} response.APIResponse "Deleted product not found"
+// @Failure 403 {object} response.APIResponse "Admin access required"
+// @Router /product/{id}/restore [post]
+// @Security BearerAuth
+func (p *productHandler) RestoreProduct(c *gin.Context) {
+	product, err := p.productService.RestoreProduct(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to restore product",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", utils.VersionETag(product.Version))
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Data:    product,
+		Message: "Success to restore product",
+	})
 }
 
 func NewProductHandler(ps service.ProductService) ProductHandler {
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductImageHandler interface {
+	GetImages(c *gin.Context)
+	UploadImages(c *gin.Context)
+	ReorderImages(c *gin.Context)
+	DeleteImage(c *gin.Context)
+}
+
+type productImageHandler struct {
+	imageService service.ProductImageService
+}
+
+// maxUploadFiles caps the files accepted in one upload request.
+const maxUploadFiles = 10
+
+func NewProductImageHandler(imageService service.ProductImageService) ProductImageHandler {
+	return &productImageHandler{
+		imageService: imageService,
+	}
+}
+
+// @Summary List product images
+// @Description Get the image gallery of a product in display order, with thumbnail URLs.
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.APIResponse{data=[]model.ProductImage} "Images retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/images [get]
+func (h *productImageHandler) GetImages(c *gin.Context) {
+	images, err := h.imageService.GetImages(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get images",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Images retrieved successfully",
+		Data:    images,
+	})
+}
+
+// @Summary Upload product images
+// @Description Upload up to 10 JPEG, PNG or GIF files in the "images" field. The type is detected from the content. Small, medium and large thumbnails are generated and the images are appended to the gallery.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param images formData file true "Image files"
+// @Success 201 {object} response.APIResponse{data=[]model.ProductImage} "Images uploaded"
+// @Failure 400 {object} response.APIResponse "Invalid image"
+// @Failure 413 {object} response.APIResponse "Upload too large"
+// @Router /product/{id}/images [post]
+// @Security BearerAuth
+func (h *productImageHandler) UploadImages(c *gin.Context) {
+	limit := int64(config.Envs.UploadMaxBytes)*maxUploadFiles + 1<<20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, response.APIResponse{
+				Success: false,
+				Message: "Upload too large",
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+	defer form.RemoveAll()
+
+	files := form.File["images"]
+	if len(files) > maxUploadFiles {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   "too many files in one upload",
+		})
+		return
+	}
+
+	images, err := h.imageService.UploadImages(c, c.Param("id"), files)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to upload images",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Images uploaded successfully",
+		Data:    images,
+	})
+}
+
+// @Summary Reorder product images
+// @Description Set the gallery order. image_ids must list every image of the product once; the first one becomes the main image.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.ReorderProductImagesRequest true "Image IDs in the new order"
+// @Success 200 {object} response.APIResponse{data=[]model.ProductImage} "Images reordered"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /product/{id}/images/order [put]
+// @Security BearerAuth
+func (h *productImageHandler) ReorderImages(c *gin.Context) {
+	var req request.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	images, err := h.imageService.ReorderImages(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to reorder images",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Images reordered successfully",
+		Data:    images,
+	})
+}
+
+// @Summary Delete product image
+// @Description Remove an image and its thumbnails from the gallery and from storage.
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} response.APIResponse "Image deleted"
+// @Failure 404 {object} response.APIResponse "Image not found"
+// @Router /product/{id}/images/{imageId} [delete]
+// @Security BearerAuth
+func (h *productImageHandler) DeleteImage(c *gin.Context) {
+	if err := h.imageService.DeleteImage(c, c.Param("id"), c.Param("imageId")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete image",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Image deleted successfully",
+	})
+}
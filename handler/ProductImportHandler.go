@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductImportHandler interface {
+	StartImport(c *gin.Context)
+	GetImport(c *gin.Context)
+	ExportProducts(c *gin.Context)
+}
+
+type productImportHandler struct {
+	importService service.ProductImportService
+}
+
+func NewProductImportHandler(importService service.ProductImportService) ProductImportHandler {
+	return &productImportHandler{
+		importService: importService,
+	}
+}
+
+// @Summary Import products
+// @Description Upload a CSV or NDJSON file in the "file" field to create or update products by SKU in the background. Rows are validated like product creation. CSV needs a header with sku and name; image and category_ids hold "|"-separated lists. Columns or fields left out keep their current value on update. Stock and images are ignored for products managed by variants or an image gallery.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param file formData file true "CSV or NDJSON file"
+// @Param format formData string false "csv or ndjson, defaults to the file extension"
+// @Success 202 {object} response.APIResponse{data=model.ProductImport} "Import queued"
+// @Failure 400 {object} response.APIResponse "Invalid file"
+// @Failure 413 {object} response.APIResponse "File too large"
+// @Router /admin/products/imports [post]
+// @Security BearerAuth
+func (h *productImportHandler) StartImport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(config.Envs.ImportMaxBytes)+1<<20)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, response.APIResponse{
+				Success: false,
+				Message: "File too large",
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	productImport, err := h.importService.StartImport(c, utils.GetUserID(c), c.PostForm("format"), file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to start import",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.APIResponse{
+		Success: true,
+		Message: "Import queued successfully",
+		Data:    productImport,
+	})
+}
+
+// @Summary Get product import
+// @Description Get the progress of an import and the errors of rows that were skipped
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Import ID"
+// @Success 200 {object} response.APIResponse{data=model.ProductImport} "Import retrieved"
+// @Failure 404 {object} response.APIResponse "Import not found"
+// @Router /admin/products/imports/{id} [get]
+// @Security BearerAuth
+func (h *productImportHandler) GetImport(c *gin.Context) {
+	productImport, err := h.importService.GetImport(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get import",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Import retrieved successfully",
+		Data:    productImport,
+	})
+}
+
+// @Summary Export products
+// @Description Stream the whole catalog as CSV or NDJSON, in the format accepted by the import
+// @Tags products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "csv (default) or ndjson"
+// @Success 200 {file} file "Product catalog"
+// @Failure 400 {object} response.APIResponse "Invalid format"
+// @Router /admin/products/export [get]
+// @Security BearerAuth
+func (h *productImportHandler) ExportProducts(c *gin.Context) {
+	format, err := service.ProductImportFormat(c.DefaultQuery("format", model.ProductImportFormatCSV), "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == model.ProductImportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	filename := "products-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// The status is already sent, a failure can only cut the stream short.
+	if err := h.importService.ExportProducts(c, format, c.Writer); err != nil {
+		log.Printf("Failed to export products: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductReviewHandler interface {
+	CreateReview(c *gin.Context)
+	GetProductReviews(c *gin.Context)
+	GetReviewQueue(c *gin.Context)
+	ModerateReview(c *gin.Context)
+	VoteHelpful(c *gin.Context)
+	RemoveHelpfulVote(c *gin.Context)
+}
+
+type productReviewHandler struct {
+	reviewService service.ProductReviewService
+}
+
+// maxReviewImages caps the images accepted with one review.
+const maxReviewImages = 5
+
+func NewProductReviewHandler(reviewService service.ProductReviewService) ProductReviewHandler {
+	return &productReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// @Summary Review a product
+// @Description Post a 1 to 5 rating with a text, once per product. Only customers with a complete order containing the product can review it. Send a multipart form with up to 5 JPEG, PNG or GIF files in the "images" field to attach photos. The review is public once approved by a moderator.
+// @Tags reviews
+// @Accept json,multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.CreateReviewRequest true "Review"
+// @Param images formData file false "Image files"
+// @Success 201 {object} response.APIResponse{data=response.ReviewResponse} "Review submitted"
+// @Failure 400 {object} response.APIResponse "Invalid review or not a verified buyer"
+// @Failure 413 {object} response.APIResponse "Upload too large"
+// @Router /product/{id}/reviews [post]
+// @Security BearerAuth
+func (h *productReviewHandler) CreateReview(c *gin.Context) {
+	var files []*multipart.FileHeader
+	if c.ContentType() == "multipart/form-data" {
+		limit := int64(config.Envs.UploadMaxBytes)*maxReviewImages + 1<<20
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		form, err := c.MultipartForm()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, response.APIResponse{
+					Success: false,
+					Message: "Upload too large",
+					Error:   err.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid request",
+				Error:   err.Error(),
+			})
+			return
+		}
+		files = form.File["images"]
+	}
+
+	var req request.CreateReviewRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(c, c.GetString("user_id"), c.Param("id"), req, files)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to submit review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Review submitted for moderation",
+		Data:    review,
+	})
+}
+
+// @Summary List product reviews
+// @Description List the approved reviews of a product. The aggregate rating is on the product itself.
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param rating query int false "Only reviews with this rating" minimum(1) maximum(5)
+// @Param with_images query bool false "Only reviews with images"
+// @Param sort query string false "newest, oldest, rating_desc, rating_asc or helpful" default(newest)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.ReviewPagingResponse} "Reviews retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/reviews [get]
+func (h *productReviewHandler) GetProductReviews(c *gin.Context) {
+	reviews, err := h.reviewService.GetProductReviews(c, c.Param("id"), reviewQueryParams(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get reviews",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Reviews retrieved successfully",
+		Data:    reviews,
+	})
+}
+
+// @Summary List reviews for moderation
+// @Description List reviews by moderation status, pending ones oldest first by default.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "pending, approved or hidden" default(pending)
+// @Param product_id query string false "Only reviews of this product"
+// @Param rating query int false "Only reviews with this rating" minimum(1) maximum(5)
+// @Param with_images query bool false "Only reviews with images"
+// @Param sort query string false "newest, oldest, rating_desc, rating_asc or helpful" default(oldest)
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.ReviewPagingResponse} "Reviews retrieved"
+// @Failure 500 {object} response.APIResponse "Internal server error"
+// @Router /admin/reviews [get]
+// @Security BearerAuth
+func (h *productReviewHandler) GetReviewQueue(c *gin.Context) {
+	params := reviewQueryParams(c)
+	params.Status = c.Query("status")
+	params.ProductID = c.Query("product_id")
+
+	reviews, err := h.reviewService.GetReviewQueue(c, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get reviews",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Reviews retrieved successfully",
+		Data:    reviews,
+	})
+}
+
+// @Summary Moderate a review
+// @Description Approve a review, making it public and counting it in the product rating, or hide it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review ID"
+// @Param request body request.ModerateReviewRequest true "Moderation decision"
+// @Success 200 {object} response.APIResponse{data=response.ReviewResponse} "Review moderated"
+// @Failure 400 {object} response.APIResponse "Invalid request or review not found"
+// @Router /admin/reviews/{id}/moderate [post]
+// @Security BearerAuth
+func (h *productReviewHandler) ModerateReview(c *gin.Context) {
+	var req request.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to moderate review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Review moderated successfully",
+		Data:    review,
+	})
+}
+
+// @Summary Mark a review as helpful
+// @Description Vote for an approved review of another customer. Voting twice has no effect.
+// @Tags reviews
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review ID"
+// @Success 200 {object} response.APIResponse "Vote recorded"
+// @Failure 400 {object} response.APIResponse "Review not found or own review"
+// @Router /reviews/{id}/helpful [post]
+// @Security BearerAuth
+func (h *productReviewHandler) VoteHelpful(c *gin.Context) {
+	if err := h.reviewService.VoteHelpful(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to vote",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Vote recorded successfully",
+	})
+}
+
+// @Summary Remove a helpful vote
+// @Description Take back a helpful vote on a review.
+// @Tags reviews
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review ID"
+// @Success 200 {object} response.APIResponse "Vote removed"
+// @Failure 404 {object} response.APIResponse "Review not found"
+// @Router /reviews/{id}/helpful [delete]
+// @Security BearerAuth
+func (h *productReviewHandler) RemoveHelpfulVote(c *gin.Context) {
+	if err := h.reviewService.RemoveHelpfulVote(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to remove vote",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Vote removed successfully",
+	})
+}
+
+func reviewQueryParams(c *gin.Context) service.ReviewQueryParams {
+	return service.ReviewQueryParams{
+		Page:       utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:      utils.ParseIntWithDefault(c.Query("limit"), 20),
+		Rating:     utils.ParseIntWithDefault(c.Query("rating"), 0),
+		WithImages: c.Query("with_images") == "true",
+		Sort:       c.Query("sort"),
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductVariantHandler interface {
+	SetOptions(c *gin.Context)
+	GetVariants(c *gin.Context)
+	CreateVariant(c *gin.Context)
+	UpdateVariant(c *gin.Context)
+	DeleteVariant(c *gin.Context)
+}
+
+type productVariantHandler struct {
+	variantService service.ProductVariantService
+}
+
+func NewProductVariantHandler(variantService service.ProductVariantService) ProductVariantHandler {
+	return &productVariantHandler{
+		variantService: variantService,
+	}
+}
+
+// @Summary Set product options
+// @Description Replace the option types (e.g. Size, Color) of a product. Existing variants must fit the new options.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.SetProductOptionsRequest true "Option types"
+// @Success 200 {object} response.APIResponse{data=[]model.ProductOption} "Options updated"
+// @Failure 400 {object} response.APIResponse "Invalid options"
+// @Router /product/{id}/options [put]
+// @Security BearerAuth
+func (h *productVariantHandler) SetOptions(c *gin.Context) {
+	var req request.SetProductOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	options, err := h.variantService.SetOptions(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to set product options",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Product options updated successfully",
+		Data:    options,
+	})
+}
+
+// @Summary List product variants
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.APIResponse{data=[]model.ProductVariant} "Variants retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/variants [get]
+func (h *productVariantHandler) GetVariants(c *gin.Context) {
+	variants, err := h.variantService.GetVariants(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get variants",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Variants retrieved successfully",
+		Data:    variants,
+	})
+}
+
+// @Summary Create product variant
+// @Description Add a variant with its own SKU, price, stock and images. It must pick one value per product option.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.CreateVariantRequest true "Variant details"
+// @Success 201 {object} response.APIResponse{data=model.ProductVariant} "Variant created"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /product/{id}/variants [post]
+// @Security BearerAuth
+func (h *productVariantHandler) CreateVariant(c *gin.Context) {
+	var req request.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	variant, err := h.variantService.CreateVariant(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create variant",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Variant created successfully",
+		Data:    variant,
+	})
+}
+
+// @Summary Update product variant
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body request.UpdateVariantRequest true "Fields to update"
+// @Success 200 {object} response.APIResponse{data=model.ProductVariant} "Variant updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /product/{id}/variants/{variantId} [patch]
+// @Security BearerAuth
+func (h *productVariantHandler) UpdateVariant(c *gin.Context) {
+	var req request.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	variant, err := h.variantService.UpdateVariant(c, c.Param("id"), c.Param("variantId"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update variant",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Variant updated successfully",
+		Data:    variant,
+	})
+}
+
+// @Summary Delete product variant
+// @Description Take a variant off sale. Past orders keep referencing it.
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} response.APIResponse "Variant deleted"
+// @Failure 404 {object} response.APIResponse "Variant not found"
+// @Router /product/{id}/variants/{variantId} [delete]
+// @Security BearerAuth
+func (h *productVariantHandler) DeleteVariant(c *gin.Context) {
+	if err := h.variantService.DeleteVariant(c, c.Param("id"), c.Param("variantId")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete variant",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Variant deleted successfully",
+	})
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler interface {
+	GetSalesReport(c *gin.Context)
+}
+
+type reportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) ReportHandler {
+	return &reportHandler{
+		reportService: reportService,
+	}
+}
+
+// @Summary Sales report
+// @Description Units sold and revenue per product for paid, shipped and complete orders, at the prices charged when the orders were placed. Discount is what running sales took off the regular prices. Defaults to the last 30 days.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query int false "Orders placed at or after (unix millis)"
+// @Param to query int false "Orders placed at or before (unix millis)"
+// @Success 200 {object} response.APIResponse{data=response.SalesReportResponse} "Report retrieved"
+// @Failure 400 {object} response.APIResponse "Invalid period"
+// @Router /admin/reports/sales [get]
+// @Security BearerAuth
+func (h *reportHandler) GetSalesReport(c *gin.Context) {
+	var from, to int64
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid from parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid to parameter",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	report, err := h.reportService.GetSalesReport(c, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to get sales report",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Sales report retrieved successfully",
+		Data:    report,
+	})
+}
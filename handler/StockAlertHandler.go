@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockAlertHandler interface {
+	Subscribe(c *gin.Context)
+	GetSubscriptions(c *gin.Context)
+	Unsubscribe(c *gin.Context)
+}
+
+type stockAlertHandler struct {
+	stockAlertService service.StockAlertService
+}
+
+func NewStockAlertHandler(stockAlertService service.StockAlertService) StockAlertHandler {
+	return &stockAlertHandler{
+		stockAlertService: stockAlertService,
+	}
+}
+
+// @Summary Subscribe to back in stock notification
+// @Description Get notified in-app and by email once an out of stock product, or variant, is available again
+// @Tags stock-subscriptions
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.StockSubscriptionRequest true "Product to watch"
+// @Success 201 {object} response.APIResponse{data=model.StockSubscription} "Subscribed"
+// @Failure 400 {object} response.APIResponse "Invalid request or product in stock"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/stock-subscriptions [post]
+// @Security BearerAuth
+func (h *stockAlertHandler) Subscribe(c *gin.Context) {
+	var req request.StockSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	subscription, err := h.stockAlertService.Subscribe(c, utils.GetUserID(c), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to subscribe",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Subscribed successfully",
+		Data:    subscription,
+	})
+}
+
+// @Summary List back in stock subscriptions
+// @Description List the current user's subscriptions that have not fired yet
+// @Tags stock-subscriptions
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]model.StockSubscription} "Subscriptions retrieved"
+// @Failure 401 {object} response.APIResponse "Unauthorized"
+// @Router /user/stock-subscriptions [get]
+// @Security BearerAuth
+func (h *stockAlertHandler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.stockAlertService.GetSubscriptions(c, utils.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get subscriptions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Subscriptions retrieved successfully",
+		Data:    subscriptions,
+	})
+}
+
+// @Summary Unsubscribe from back in stock notification
+// @Tags stock-subscriptions
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} response.APIResponse "Unsubscribed"
+// @Failure 404 {object} response.APIResponse "Subscription not found"
+// @Router /user/stock-subscriptions/{id} [delete]
+// @Security BearerAuth
+func (h *stockAlertHandler) Unsubscribe(c *gin.Context) {
+	if err := h.stockAlertService.Unsubscribe(c, utils.GetUserID(c), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to unsubscribe",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Unsubscribed successfully",
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockHandler interface {
+	AdjustStock(c *gin.Context)
+	GetStockHistory(c *gin.Context)
+	GetStockLevels(c *gin.Context)
+}
+
+type stockHandler struct {
+	stockService service.StockService
+}
+
+func NewStockHandler(stockService service.StockService) StockHandler {
+	return &stockHandler{
+		stockService: stockService,
+	}
+}
+
+// @Summary Adjust product stock
+// @Description Add or remove stock by a signed quantity, e.g. after a recount or a return. Products sold in variants are adjusted per variant, and the default warehouse is used unless warehouse_id is given.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body request.StockAdjustmentRequest true "Adjustment"
+// @Success 201 {object} response.APIResponse{data=model.StockMovement} "Stock adjusted"
+// @Failure 400 {object} response.APIResponse "Invalid adjustment"
+// @Router /product/{id}/stock/adjustments [post]
+// @Security BearerAuth
+func (h *stockHandler) AdjustStock(c *gin.Context) {
+	var req request.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	movement, err := h.stockService.AdjustStock(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to adjust stock",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Stock adjusted successfully",
+		Data:    movement,
+	})
+}
+
+// @Summary Get product stock history
+// @Description List the stock movements of a product and its variants, newest first
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param variant_id query string false "Only movements of this variant"
+// @Param warehouse_id query string false "Only movements of this warehouse"
+// @Param reason query string false "initial, order_placed, order_canceled, adjustment, import, return, transfer, code_upload, code_void or code_reissue"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.APIResponse{data=response.StockMovementPagingResponse} "Stock history retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/stock/movements [get]
+// @Security BearerAuth
+func (h *stockHandler) GetStockHistory(c *gin.Context) {
+	params := service.StockHistoryParams{
+		Page:        utils.ParseIntWithDefault(c.Query("page"), 1),
+		Limit:       utils.ParseIntWithDefault(c.Query("limit"), 20),
+		VariantID:   c.Query("variant_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		Reason:      c.Query("reason"),
+	}
+
+	history, err := h.stockService.GetStockHistory(c, c.Param("id"), params)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get stock history",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Stock history retrieved successfully",
+		Data:    history,
+	})
+}
+
+// @Summary Get product stock levels
+// @Description List the stock of a product and its variants at each warehouse
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.APIResponse{data=[]model.WarehouseStock} "Stock levels retrieved"
+// @Failure 404 {object} response.APIResponse "Product not found"
+// @Router /product/{id}/stock/levels [get]
+// @Security BearerAuth
+func (h *stockHandler) GetStockLevels(c *gin.Context) {
+	levels, err := h.stockService.GetStockLevels(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get stock levels",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Stock levels retrieved successfully",
+		Data:    levels,
+	})
+}
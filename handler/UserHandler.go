@@ -14,11 +14,12 @@ type UserHandler interface {
 	CreateUser(c *gin.Context)
 	GetUser(c *gin.Context)
 	FindUser(c *gin.Context)
-	// UpdateUser(c *gin.Context)
+	UpdateUser(c *gin.Context)
+	ChangePassword(c *gin.Context)
 	FindUserByEmail(c *gin.Context)
 	FindByUsername(c *gin.Context)
 	FindUserAfterDate(c *gin.Context)
-	// DeleteUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
 }
 
 type userHandler struct {
@@ -200,3 +201,121 @@ func (h *userHandler) CreateUser(c *gin.Context) {
 		Message: "User created successfully",
 	})
 }
+
+// @Summary Update user profile
+// @Description Partially update the current user's profile
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.UpdateUserRequest true "Profile fields"
+// @Success 200 {object} response.APIResponse{data=model.User} "Profile updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /user/me [patch]
+// @Security BearerAuth
+func (h *userHandler) UpdateUser(c *gin.Context) {
+	var req request.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userId := utils.GetUserID(c)
+	user, err := h.userService.UpdateUser(c, userId, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "User updated successfully",
+		Data:    user,
+	})
+}
+
+// @Summary Change password
+// @Description Change the current user's password. Other sessions are revoked and a new token pair is returned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.APIResponse{data=response.TokenResponse} "Password changed"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /user/me/password [post]
+// @Security BearerAuth
+func (h *userHandler) ChangePassword(c *gin.Context) {
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userId := utils.GetUserID(c)
+	tokens, err := h.userService.ChangePassword(c, userId, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to change password",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Password changed successfully",
+		Data:    tokens,
+	})
+}
+
+// @Summary Delete account
+// @Description Delete the current user's account. Personal data is anonymized, orders are kept.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.DeleteUserRequest true "Password confirmation"
+// @Success 200 {object} response.APIResponse "Account deleted"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /user/me [delete]
+// @Security BearerAuth
+func (h *userHandler) DeleteUser(c *gin.Context) {
+	var req request.DeleteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userId := utils.GetUserID(c)
+	if err := h.userService.DeleteUser(c, userId, req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "User deleted successfully",
+	})
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseHandler interface {
+	GetWarehouses(c *gin.Context)
+	CreateWarehouse(c *gin.Context)
+	UpdateWarehouse(c *gin.Context)
+	TransferStock(c *gin.Context)
+}
+
+type warehouseHandler struct {
+	warehouseService service.WarehouseService
+}
+
+func NewWarehouseHandler(warehouseService service.WarehouseService) WarehouseHandler {
+	return &warehouseHandler{
+		warehouseService: warehouseService,
+	}
+}
+
+// @Summary List warehouses
+// @Description List all warehouses in fulfilment priority order
+// @Tags warehouses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]model.Warehouse} "Warehouses retrieved"
+// @Failure 403 {object} response.APIResponse "Forbidden"
+// @Router /admin/warehouses [get]
+// @Security BearerAuth
+func (h *warehouseHandler) GetWarehouses(c *gin.Context) {
+	warehouses, err := h.warehouseService.GetWarehouses(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get warehouses",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Warehouses retrieved successfully",
+		Data:    warehouses,
+	})
+}
+
+// @Summary Create warehouse
+// @Description Create a warehouse. Marking it as the default replaces the current default.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.CreateWarehouseRequest true "Warehouse details"
+// @Success 201 {object} response.APIResponse{data=model.Warehouse} "Warehouse created"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Failure 403 {object} response.APIResponse "Forbidden"
+// @Router /admin/warehouses [post]
+// @Security BearerAuth
+func (h *warehouseHandler) CreateWarehouse(c *gin.Context) {
+	var req request.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	warehouse, err := h.warehouseService.CreateWarehouse(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create warehouse",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Warehouse created successfully",
+		Data:    warehouse,
+	})
+}
+
+// @Summary Update warehouse
+// @Description Update a warehouse. Inactive warehouses are not used for new orders.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Warehouse ID"
+// @Param request body request.UpdateWarehouseRequest true "Fields to update"
+// @Success 200 {object} response.APIResponse{data=model.Warehouse} "Warehouse updated"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /admin/warehouses/{id} [patch]
+// @Security BearerAuth
+func (h *warehouseHandler) UpdateWarehouse(c *gin.Context) {
+	var req request.UpdateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	warehouse, err := h.warehouseService.UpdateWarehouse(c, c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to update warehouse",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Warehouse updated successfully",
+		Data:    warehouse,
+	})
+}
+
+// @Summary Transfer stock between warehouses
+// @Description Move unreserved stock of a product or variant from one warehouse to another. Both sides are recorded as transfer movements.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.StockTransferRequest true "Transfer"
+// @Success 201 {object} response.APIResponse{data=[]model.StockMovement} "Stock transferred"
+// @Failure 400 {object} response.APIResponse "Invalid transfer"
+// @Router /admin/warehouses/transfers [post]
+// @Security BearerAuth
+func (h *warehouseHandler) TransferStock(c *gin.Context) {
+	var req request.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	movements, err := h.warehouseService.TransferStock(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to transfer stock",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Stock transferred successfully",
+		Data:    movements,
+	})
+}
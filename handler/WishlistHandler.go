@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"net/http"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandler interface {
+	GetWishlists(c *gin.Context)
+	GetWishlist(c *gin.Context)
+	GetSharedWishlist(c *gin.Context)
+	CreateWishlist(c *gin.Context)
+	RenameWishlist(c *gin.Context)
+	DeleteWishlist(c *gin.Context)
+	ShareWishlist(c *gin.Context)
+	UnshareWishlist(c *gin.Context)
+	AddItem(c *gin.Context)
+	RemoveItem(c *gin.Context)
+	MoveToCart(c *gin.Context)
+}
+
+type wishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandler(wishlistService service.WishlistService) WishlistHandler {
+	return &wishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// @Summary List wishlists
+// @Description Get the wishlists of the current user with their items
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} response.APIResponse{data=[]response.WishlistResponse} "Wishlists retrieved"
+// @Failure 500 {object} response.APIResponse "Internal server error"
+// @Router /user/wishlists [get]
+// @Security BearerAuth
+func (h *wishlistHandler) GetWishlists(c *gin.Context) {
+	wishlists, err := h.wishlistService.GetWishlists(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIResponse{
+			Success: false,
+			Message: "Failed to get wishlists",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlists retrieved successfully",
+		Data:    wishlists,
+	})
+}
+
+// @Summary Get a wishlist
+// @Description Get a wishlist of the current user with its items
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist retrieved"
+// @Failure 404 {object} response.APIResponse "Wishlist not found"
+// @Router /user/wishlists/{id} [get]
+// @Security BearerAuth
+func (h *wishlistHandler) GetWishlist(c *gin.Context) {
+	wishlist, err := h.wishlistService.GetWishlist(c, c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist retrieved successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Get a shared wishlist
+// @Description Get a wishlist through its public link. No authentication is needed.
+// @Tags wishlists
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist retrieved"
+// @Failure 404 {object} response.APIResponse "Wishlist not found or no longer shared"
+// @Router /wishlists/shared/{token} [get]
+func (h *wishlistHandler) GetSharedWishlist(c *gin.Context) {
+	wishlist, err := h.wishlistService.GetSharedWishlist(c, c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to get wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist retrieved successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Create a wishlist
+// @Description Create a named wishlist, up to 20 per user
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body request.CreateWishlistRequest true "Wishlist"
+// @Success 201 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist created"
+// @Failure 400 {object} response.APIResponse "Invalid request or name already used"
+// @Router /user/wishlists [post]
+// @Security BearerAuth
+func (h *wishlistHandler) CreateWishlist(c *gin.Context) {
+	var req request.CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	wishlist, err := h.wishlistService.CreateWishlist(c, c.GetString("user_id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to create wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Wishlist created successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Rename a wishlist
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Param request body request.UpdateWishlistRequest true "New name"
+// @Success 200 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist renamed"
+// @Failure 400 {object} response.APIResponse "Invalid request or wishlist not found"
+// @Router /user/wishlists/{id} [patch]
+// @Security BearerAuth
+func (h *wishlistHandler) RenameWishlist(c *gin.Context) {
+	var req request.UpdateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	wishlist, err := h.wishlistService.RenameWishlist(c, c.GetString("user_id"), c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to rename wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist renamed successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Delete a wishlist
+// @Description Delete a wishlist and its items. Its public link stops working.
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} response.APIResponse "Wishlist deleted"
+// @Failure 404 {object} response.APIResponse "Wishlist not found"
+// @Router /user/wishlists/{id} [delete]
+// @Security BearerAuth
+func (h *wishlistHandler) DeleteWishlist(c *gin.Context) {
+	if err := h.wishlistService.DeleteWishlist(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to delete wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist deleted successfully",
+	})
+}
+
+// @Summary Share a wishlist
+// @Description Create a public link to the wishlist, returned as share_url. Sharing a shared wishlist keeps its link.
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist shared"
+// @Failure 404 {object} response.APIResponse "Wishlist not found"
+// @Router /user/wishlists/{id}/share [post]
+// @Security BearerAuth
+func (h *wishlistHandler) ShareWishlist(c *gin.Context) {
+	wishlist, err := h.wishlistService.ShareWishlist(c, c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to share wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist shared successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Stop sharing a wishlist
+// @Description Revoke the public link of the wishlist
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} response.APIResponse{data=response.WishlistResponse} "Wishlist unshared"
+// @Failure 404 {object} response.APIResponse "Wishlist not found"
+// @Router /user/wishlists/{id}/share [delete]
+// @Security BearerAuth
+func (h *wishlistHandler) UnshareWishlist(c *gin.Context) {
+	wishlist, err := h.wishlistService.UnshareWishlist(c, c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to unshare wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Wishlist unshared successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Add a product to a wishlist
+// @Description Save a product, or one of its variants, in the wishlist. You are notified when its price drops.
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Param request body request.AddWishlistItemRequest true "Product"
+// @Success 201 {object} response.APIResponse{data=response.WishlistResponse} "Product added"
+// @Failure 400 {object} response.APIResponse "Invalid request"
+// @Router /user/wishlists/{id}/items [post]
+// @Security BearerAuth
+func (h *wishlistHandler) AddItem(c *gin.Context) {
+	var req request.AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	wishlist, err := h.wishlistService.AddItem(c, c.GetString("user_id"), c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to add product to wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Success: true,
+		Message: "Product added to wishlist successfully",
+		Data:    wishlist,
+	})
+}
+
+// @Summary Remove a product from a wishlist
+// @Tags wishlists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Param itemId path string true "Wishlist item ID"
+// @Success 200 {object} response.APIResponse "Product removed"
+// @Failure 404 {object} response.APIResponse "Wishlist or item not found"
+// @Router /user/wishlists/{id}/items/{itemId} [delete]
+// @Security BearerAuth
+func (h *wishlistHandler) RemoveItem(c *gin.Context) {
+	if err := h.wishlistService.RemoveItem(c, c.GetString("user_id"), c.Param("id"), c.Param("itemId")); err != nil {
+		c.JSON(http.StatusNotFound, response.APIResponse{
+			Success: false,
+			Message: "Failed to remove product from wishlist",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Product removed from wishlist successfully",
+	})
+}
+
+// @Summary Move a wishlist item to the cart
+// @Description Add the item to the cart, 1 unit unless a quantity is given, and remove it from the wishlist
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Wishlist ID"
+// @Param itemId path string true "Wishlist item ID"
+// @Param request body request.MoveToCartRequest false "Quantity"
+// @Success 200 {object} response.APIResponse{data=response.CartResponse} "Item moved to cart"
+// @Failure 400 {object} response.APIResponse "Item not found or insufficient stock"
+// @Router /user/wishlists/{id}/items/{itemId}/cart [post]
+// @Security BearerAuth
+func (h *wishlistHandler) MoveToCart(c *gin.Context) {
+	var req request.MoveToCartRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIResponse{
+				Success: false,
+				Message: "Invalid request",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	cart, err := h.wishlistService.MoveToCart(c, c.GetString("user_id"), c.Param("id"), c.Param("itemId"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIResponse{
+			Success: false,
+			Message: "Failed to move item to cart",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Success: true,
+		Message: "Item moved to cart successfully",
+		Data:    cart,
+	})
+}
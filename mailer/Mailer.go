@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer returns the backend selected by MAIL_DRIVER.
+func NewMailer() Mailer {
+	if config.Envs.MailDriver == "smtp" {
+		return NewSMTPMailer(SMTPConfig{
+			Host:     config.Envs.SMTPHost,
+			Port:     config.Envs.SMTPPort,
+			Username: config.Envs.SMTPUsername,
+			Password: config.Envs.SMTPPassword,
+			From:     config.Envs.MailFrom,
+		})
+	}
+	return NewLogMailer()
+}
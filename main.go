@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/filestore"
 	"nuxatech-nextmedis/handler"
+	"nuxatech-nextmedis/mailer"
 	"nuxatech-nextmedis/middleware"
 	"nuxatech-nextmedis/repository"
 	"nuxatech-nextmedis/route"
@@ -34,6 +38,10 @@ import (
 // @name Authorization
 
 func main() {
+	if err := config.Envs.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	docs.SwaggerInfo.Title = "Nextmedis API"
 	docs.SwaggerInfo.Description = "API Documentation for Nextmedis Application"
 	docs.SwaggerInfo.Version = "1.0"
@@ -49,13 +57,75 @@ func main() {
 	accountRepository := repository.NewAccountRepository()
 	orderRepository := repository.NewOrderRepository()
 	transactionRepository := repository.NewTransactionRepository()
+	apiTokenRepository := repository.NewApiTokenRepository()
+	userIdentityRepository := repository.NewUserIdentityRepository()
+	auditRepository := repository.NewAuditRepository()
+	dataExportRepository := repository.NewDataExportRepository()
+	notificationRepository := repository.NewNotificationRepository()
+	addressRepository := repository.NewAddressRepository()
+	categoryRepository := repository.NewCategoryRepository()
+	productVariantRepository := repository.NewProductVariantRepository()
+	productImageRepository := repository.NewProductImageRepository()
+	productImportRepository := repository.NewProductImportRepository()
+	stockMovementRepository := repository.NewStockMovementRepository()
+	stockReservationRepository := repository.NewStockReservationRepository()
+	warehouseRepository := repository.NewWarehouseRepository()
+	stockSubscriptionRepository := repository.NewStockSubscriptionRepository()
+	productCodeRepository := repository.NewProductCodeRepository()
+	productReviewRepository := repository.NewProductReviewRepository()
+	wishlistRepository := repository.NewWishlistRepository()
+	priceRepository := repository.NewPriceRepository()
+	reportRepository := repository.NewReportRepository()
+	fileStorage := filestore.NewStorage()
 
-	userService := service.NewUserService(userRepository)
-	authService := service.NewAuthService(userRepository, tokenRepository)
-	productService := service.NewProductService(productRepository)
-	cartService := service.NewCartService(cartRepository, productRepository)
-	accountService := service.NewAccountService(accountRepository, transactionRepository)
-	orderService := service.NewOrderService(orderRepository, cartRepository, productRepository, accountRepository)
+	auditService := service.NewAuditService(auditRepository)
+	authService := service.NewAuthService(userRepository, tokenRepository, auditService)
+	userService := service.NewUserService(
+		userRepository,
+		tokenRepository,
+		apiTokenRepository,
+		userIdentityRepository,
+		accountRepository,
+		cartRepository,
+		addressRepository,
+		dataExportRepository,
+		authService,
+		auditService,
+	)
+	productService := service.NewProductService(productRepository, categoryRepository, auditService)
+	categoryService := service.NewCategoryService(categoryRepository)
+	productVariantService := service.NewProductVariantService(productRepository, productVariantRepository, auditService)
+	productImageService := service.NewProductImageService(productRepository, productImageRepository, fileStorage, auditService)
+	productImportService := service.NewProductImportService(productImportRepository, productRepository, productService, auditService)
+	notificationService := service.NewNotificationService(notificationRepository, userRepository, mailer.NewMailer())
+	stockAlertService := service.NewStockAlertService(stockSubscriptionRepository, productRepository, productVariantRepository, userRepository, notificationService)
+	cartService := service.NewCartService(cartRepository, productRepository, productVariantRepository)
+	accountService := service.NewAccountService(accountRepository, transactionRepository, auditService)
+	orderService := service.NewOrderService(orderRepository, cartRepository, productRepository, productVariantRepository, stockMovementRepository, stockReservationRepository, warehouseRepository, productCodeRepository, accountRepository, addressRepository, auditService, stockAlertService)
+	stockService := service.NewStockService(stockMovementRepository, productRepository, productVariantRepository, warehouseRepository, auditService)
+	warehouseService := service.NewWarehouseService(warehouseRepository, stockMovementRepository, productRepository, productVariantRepository, auditService)
+	productCodeService := service.NewProductCodeService(productCodeRepository, productRepository, warehouseRepository, auditService)
+	productReviewService := service.NewProductReviewService(productReviewRepository, productRepository, fileStorage, auditService)
+	wishlistService := service.NewWishlistService(wishlistRepository, productRepository, productVariantRepository, cartService, notificationService)
+	priceService := service.NewPriceService(priceRepository, productRepository, productVariantRepository, auditService)
+	reportService := service.NewReportService(reportRepository)
+	apiTokenService := service.NewApiTokenService(apiTokenRepository, userRepository)
+	oidcService := service.NewOIDCService(userRepository, userIdentityRepository, authService, auditService)
+	addressService := service.NewAddressService(addressRepository, service.NewAddressValidator())
+	dataExportService := service.NewDataExportService(
+		dataExportRepository,
+		userRepository,
+		tokenRepository,
+		apiTokenRepository,
+		userIdentityRepository,
+		cartRepository,
+		orderRepository,
+		accountRepository,
+		transactionRepository,
+		addressRepository,
+		notificationService,
+		auditService,
+	)
 
 	userHandler := handler.NewUserHandler(userService)
 	authHadler := handler.NewAuthHandler(authService)
@@ -63,8 +133,38 @@ func main() {
 	cartHandler := handler.NewCartHandler(cartService)
 	accountHandler := handler.NewAccountHandler(accountService)
 	orderHandler := handler.NewOrderHandler(orderService)
+	apiTokenHandler := handler.NewApiTokenHandler(apiTokenService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	adminHandler := handler.NewAdminHandler(authService, userService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	dataExportHandler := handler.NewDataExportHandler(dataExportService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	addressHandler := handler.NewAddressHandler(addressService)
+	categoryHandler := handler.NewCategoryHandler(categoryService, productService)
+	productVariantHandler := handler.NewProductVariantHandler(productVariantService)
+	productImageHandler := handler.NewProductImageHandler(productImageService)
+	productImportHandler := handler.NewProductImportHandler(productImportService)
+	stockHandler := handler.NewStockHandler(stockService)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseService)
+	stockAlertHandler := handler.NewStockAlertHandler(stockAlertService)
+	productCodeHandler := handler.NewProductCodeHandler(productCodeService)
+	productReviewHandler := handler.NewProductReviewHandler(productReviewService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService)
+	priceHandler := handler.NewPriceHandler(priceService)
+	reportHandler := handler.NewReportHandler(reportService)
 
 	middleware.SetAuthService(authService)
+	middleware.SetApiTokenService(apiTokenService)
+	middleware.SetAuditService(auditService)
+
+	go dataExportService.Run(context.Background())
+	go productImageService.Run(context.Background())
+	go productImportService.Run(context.Background())
+	go orderService.Run(context.Background())
+	go notificationService.Run(context.Background())
+	go stockAlertService.Run(context.Background())
+	go wishlistService.Run(context.Background())
+	go priceService.Run(context.Background())
 
 	server := route.SetupRoutes(
 		userHandler,
@@ -73,9 +173,31 @@ func main() {
 		cartHandler,
 		accountHandler,
 		orderHandler,
+		apiTokenHandler,
+		oidcHandler,
+		adminHandler,
+		auditHandler,
+		dataExportHandler,
+		notificationHandler,
+		addressHandler,
+		categoryHandler,
+		productVariantHandler,
+		productImageHandler,
+		productImportHandler,
+		stockHandler,
+		warehouseHandler,
+		stockAlertHandler,
+		productCodeHandler,
+		productReviewHandler,
+		wishlistHandler,
+		priceHandler,
+		reportHandler,
 	)
 	server.LoadHTMLGlob("./public/html/*")
 	server.Static("/public", "./public")
+	if config.Envs.StorageDriver != "s3" {
+		server.Static("/uploads", config.Envs.StorageDir)
+	}
 	server.GET("/", func(c *gin.Context) {
 		c.HTML(http.StatusOK, "index.html", gin.H{
 			"apiVersion": "v1",
@@ -1,18 +1,35 @@
 package middleware
 
 import (
+	"nuxatech-nextmedis/model"
 	"nuxatech-nextmedis/service"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	AuthTypeSession       = "session"
+	AuthTypeApiToken      = "api_token"
+	AuthTypeImpersonation = "impersonation"
+)
+
 var authService service.AuthService
+var apiTokenService service.ApiTokenService
+var auditService service.AuditService
 
 func SetAuthService(service service.AuthService) {
 	authService = service
 }
 
+func SetApiTokenService(service service.ApiTokenService) {
+	apiTokenService = service
+}
+
+func SetAuditService(service service.AuditService) {
+	auditService = service
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -28,15 +45,133 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := splitToken[1]
+		if strings.HasPrefix(tokenString, model.ApiTokenPrefix) {
+			token, user, err := apiTokenService.ValidateToken(c, tokenString)
+			if err != nil {
+				c.AbortWithStatusJSON(401, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.Set("user_id", user.ID)
+			c.Set("username", user.Username)
+			c.Set("email", user.Email)
+			c.Set("role", user.Role)
+			c.Set("auth_type", AuthTypeApiToken)
+			c.Set("scopes", token.Scopes)
+			setRequestActor(c)
+
+			c.Next()
+			return
+		}
+
 		payload, err := authService.ValidateAccessToken(tokenString)
 		if err != nil {
 			c.AbortWithStatusJSON(401, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.Set("user_id", payload.UserID)
-		c.Set("username", payload.Username)
-		c.Set("email", payload.Email)
+		user, err := authService.VerifySession(c, payload)
+		if err != nil {
+			c.AbortWithStatusJSON(401, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The stored user wins over the claims, so a role change applies at
+		// once rather than when the token expires.
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("email", user.Email)
+		c.Set("role", user.Role)
+
+		if payload.Actor == nil {
+			c.Set("auth_type", AuthTypeSession)
+			setRequestActor(c)
+			c.Next()
+			return
+		}
+
+		c.Set("auth_type", AuthTypeImpersonation)
+		c.Set("actor_id", payload.Actor.UserID)
+		c.Set("actor_username", payload.Actor.Username)
+		setRequestActor(c)
+
+		c.Next()
+
+		auditService.Record(c, &model.AuditEvent{
+			ActorID:    &payload.Actor.UserID,
+			Action:     model.AuditActionImpersonationRequest,
+			TargetType: "user",
+			TargetID:   payload.UserID,
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			Outcome:    auditOutcome(c.Writer.Status()),
+			Metadata: model.AuditMetadata{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+				"status": c.Writer.Status(),
+			},
+		})
+	}
+}
+
+// AdminOnly must run after AuthMiddleware. Impersonation tokens never pass,
+// even though the impersonated user cannot be an admin anyway.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != model.RoleAdmin || c.GetString("auth_type") == AuthTypeImpersonation {
+			c.AbortWithStatusJSON(403, gin.H{"error": "admin access required"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BlockImpersonation guards money-moving endpoints from impersonated sessions.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") == AuthTypeImpersonation {
+			c.AbortWithStatusJSON(403, gin.H{"error": "this action is not allowed while impersonating"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func auditOutcome(status int) string {
+	if status >= 400 {
+		return model.AuditOutcomeFailure
+	}
+	return model.AuditOutcomeSuccess
+}
+
+// RequireScope only restricts API token requests. Session (JWT) requests act
+// with the full rights of the signed-in user.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != AuthTypeApiToken {
+			c.Next()
+			return
+		}
+
+		scopes, _ := c.Get("scopes")
+		if tokenScopes, ok := scopes.(model.TokenScopes); !ok || !tokenScopes.Has(scope) {
+			c.AbortWithStatusJSON(403, gin.H{"error": "token is missing required scope: " + scope})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SessionOnly rejects API tokens, e.g. so a token cannot mint further tokens.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != AuthTypeSession {
+			c.AbortWithStatusJSON(403, gin.H{"error": "this endpoint requires a user session"})
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"nuxatech-nextmedis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta stores the client IP and user agent on the request context.
+// AuthMiddleware fills in the actor once the caller is known.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := &utils.RequestMeta{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(utils.WithRequestMeta(c.Request.Context(), meta))
+
+		c.Next()
+	}
+}
+
+func setRequestActor(c *gin.Context) {
+	if meta := utils.RequestMetaFromContext(c.Request.Context()); meta != nil {
+		meta.ActorID = utils.GetActorID(c)
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+const ApiTokenPrefix = "nxt_"
+
+const (
+	ScopeProductsWrite = "products:write"
+	ScopeCartRead      = "cart:read"
+	ScopeCartWrite     = "cart:write"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+	ScopeWalletRead    = "wallet:read"
+	ScopeWalletWrite   = "wallet:write"
+	ScopeUserRead      = "user:read"
+)
+
+var ApiTokenScopes = []string{
+	ScopeProductsWrite,
+	ScopeCartRead,
+	ScopeCartWrite,
+	ScopeOrdersRead,
+	ScopeOrdersWrite,
+	ScopeWalletRead,
+	ScopeWalletWrite,
+	ScopeUserRead,
+}
+
+type TokenScopes []string
+
+type ApiToken struct {
+	ID         string      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     string      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string      `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string      `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Prefix     string      `gorm:"type:varchar(16);not null" json:"prefix"`
+	Scopes     TokenScopes `gorm:"type:jsonb;not null" json:"scopes"`
+	ExpiresAt  int64       `gorm:"type:bigint;not null" json:"expires_at"`
+	LastUsedAt *int64      `gorm:"type:bigint" json:"last_used_at"`
+	RevokedAt  *int64      `gorm:"type:bigint" json:"revoked_at"`
+	CreatedAt  int64       `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (t ApiToken) TableName() string {
+	return "api_tokens"
+}
+
+func (s TokenScopes) Has(scope string) bool {
+	return slices.Contains(s, scope)
+}
+
+func (s TokenScopes) Value() (driver.Value, error) {
+	if s == nil {
+		s = TokenScopes{}
+	}
+	jsonData, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (s *TokenScopes) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan scopes: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, s)
+}
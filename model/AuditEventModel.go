@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+const (
+	AuditActionRegister             = "auth.register"
+	AuditActionLogin                = "auth.login"
+	AuditActionLoginFailed          = "auth.login_failed"
+	AuditActionTokenRefresh         = "auth.token_refresh"
+	AuditActionLogout               = "auth.logout"
+	AuditActionIdentityLink         = "auth.identity_link"
+	AuditActionProfileUpdate        = "user.profile_update"
+	AuditActionPasswordChange       = "user.password_change"
+	AuditActionUserDelete           = "user.delete"
+	AuditActionDataExportRequest    = "user.data_export"
+	AuditActionWalletCreate         = "wallet.create"
+	AuditActionWalletDeposit        = "wallet.deposit"
+	AuditActionWalletWithdraw       = "wallet.withdraw"
+	AuditActionOrderCreate          = "order.create"
+	AuditActionOrderStatusUpdate    = "order.status_update"
+	AuditActionOrderExpire          = "order.expire"
+	AuditActionImpersonationStart   = "impersonation.start"
+	AuditActionImpersonationRequest = "impersonation.request"
+	AuditActionProductUpdate        = "product.update"
+	AuditActionProductDelete        = "product.delete"
+	AuditActionProductRestore       = "product.restore"
+	AuditActionVariantCreate        = "product.variant_create"
+	AuditActionVariantUpdate        = "product.variant_update"
+	AuditActionVariantDelete        = "product.variant_delete"
+	AuditActionImageUpload          = "product.image_upload"
+	AuditActionImageDelete          = "product.image_delete"
+	AuditActionProductImport        = "product.import"
+	AuditActionStockAdjust          = "product.stock_adjust"
+	AuditActionStockTransfer        = "product.stock_transfer"
+	AuditActionCodeUpload           = "product.code_upload"
+	AuditActionCodeVoid             = "product.code_void"
+	AuditActionCodeReissue          = "product.code_reissue"
+	AuditActionPriceSchedule        = "product.price_schedule"
+	AuditActionPriceScheduleCancel  = "product.price_schedule_cancel"
+	AuditActionWarehouseCreate      = "warehouse.create"
+	AuditActionWarehouseUpdate      = "warehouse.update"
+	AuditActionReviewModerate       = "review.moderate"
+	AuditActionUserSuspend          = "admin.user_suspend"
+	AuditActionUserReactivate       = "admin.user_reactivate"
+	AuditActionUserForceLogout      = "admin.user_force_logout"
+)
+
+// SecurityAuditActions are shown to users as their own security activity.
+var SecurityAuditActions = []string{
+	AuditActionRegister,
+	AuditActionLogin,
+	AuditActionLoginFailed,
+	AuditActionTokenRefresh,
+	AuditActionLogout,
+	AuditActionIdentityLink,
+	AuditActionPasswordChange,
+	AuditActionImpersonationStart,
+	AuditActionUserSuspend,
+	AuditActionUserReactivate,
+	AuditActionUserForceLogout,
+}
+
+type AuditMetadata map[string]interface{}
+
+// AuditEvent rows are append-only, the repository never updates or deletes them.
+type AuditEvent struct {
+	ID         string        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ActorID    *string       `gorm:"type:uuid;index" json:"actor_id"`
+	Action     string        `gorm:"type:varchar(100);not null;index" json:"action"`
+	TargetType string        `gorm:"type:varchar(50)" json:"target_type"`
+	TargetID   string        `gorm:"type:varchar(100)" json:"target_id"`
+	IP         string        `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string        `gorm:"type:text" json:"user_agent"`
+	Outcome    string        `gorm:"type:varchar(20);not null" json:"outcome"`
+	Metadata   AuditMetadata `gorm:"type:jsonb" json:"metadata"`
+	CreatedAt  int64         `gorm:"type:bigint;not null;index" json:"created_at"`
+}
+
+func (e AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+func (m AuditMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	jsonData, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (m *AuditMetadata) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan metadata: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, m)
+}
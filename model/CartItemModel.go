@@ -5,7 +5,10 @@ type CartItem struct {
 	CartID    string  `gorm:"type:uuid;not null" json:"cart_id"`
 	ProductID string  `gorm:"type:uuid;not null" json:"product_id"`
 	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
-	Quantity  int     `gorm:"type:int;not null" json:"quantity"`
-	CreatedAt int64   `gorm:"type:bigint;not null" json:"created_at"`
-	UpdatedAt int64   `gorm:"type:bigint;not null" json:"updated_at"`
+	// VariantID is set for products sold in variants.
+	VariantID *string         `gorm:"type:uuid" json:"variant_id"`
+	Variant   *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+	Quantity  int             `gorm:"type:int;not null" json:"quantity"`
+	CreatedAt int64           `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt int64           `gorm:"type:bigint;not null" json:"updated_at"`
 }
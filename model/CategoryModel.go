@@ -0,0 +1,25 @@
+package model
+
+type Category struct {
+	ID          string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ParentID    *string `gorm:"type:uuid;index" json:"parent_id"`
+	Name        string  `gorm:"type:varchar(255);not null" json:"name"`
+	Slug        string  `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
+	Description string  `gorm:"type:text" json:"description"`
+	Position    int     `gorm:"type:int;not null;default:0" json:"position"`
+	CreatedAt   int64   `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt   int64   `gorm:"type:bigint;not null" json:"updated_at"`
+}
+
+func (c Category) TableName() string {
+	return "categories"
+}
+
+type ProductCategory struct {
+	ProductID  string `gorm:"type:uuid;primary_key" json:"product_id"`
+	CategoryID string `gorm:"type:uuid;primary_key" json:"category_id"`
+}
+
+func (pc ProductCategory) TableName() string {
+	return "product_categories"
+}
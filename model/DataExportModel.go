@@ -0,0 +1,25 @@
+package model
+
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+	DataExportStatusExpired    = "expired"
+)
+
+type DataExport struct {
+	ID          string `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      string `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status      string `gorm:"type:varchar(20);not null" json:"status"`
+	FilePath    string `gorm:"type:text" json:"-"`
+	FileSize    int64  `gorm:"type:bigint;not null;default:0" json:"file_size"`
+	Error       string `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   int64  `gorm:"type:bigint;not null" json:"created_at"`
+	CompletedAt *int64 `gorm:"type:bigint" json:"completed_at"`
+	ExpiresAt   *int64 `gorm:"type:bigint" json:"expires_at"`
+}
+
+func (e DataExport) TableName() string {
+	return "data_exports"
+}
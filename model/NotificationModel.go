@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	NotificationTypeDataExportReady = "data_export.ready"
+	NotificationTypeLowStock        = "stock.low"
+	NotificationTypeBackInStock     = "stock.back_in_stock"
+	NotificationTypePriceDrop       = "wishlist.price_drop"
+)
+
+// Email delivery states. Notifications that are not emailed have none.
+const (
+	NotificationEmailPending = "pending"
+	NotificationEmailSending = "sending"
+	NotificationEmailSent    = "sent"
+	NotificationEmailFailed  = "failed"
+)
+
+type NotificationData map[string]interface{}
+
+type Notification struct {
+	ID     string           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID string           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type   string           `gorm:"type:varchar(100);not null" json:"type"`
+	Title  string           `gorm:"type:varchar(255);not null" json:"title"`
+	Body   string           `gorm:"type:text" json:"body"`
+	Data   NotificationData `gorm:"type:jsonb" json:"data"`
+	ReadAt *int64           `gorm:"type:bigint" json:"read_at"`
+	// EmailStatus tracks the copy sent by email, for types that have one.
+	EmailStatus   string `gorm:"type:varchar(20)" json:"email_status,omitempty"`
+	EmailAttempts int    `gorm:"type:int;not null;default:0" json:"-"`
+	EmailedAt     *int64 `gorm:"type:bigint" json:"emailed_at,omitempty"`
+	CreatedAt     int64  `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (n Notification) TableName() string {
+	return "notifications"
+}
+
+func (d NotificationData) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+	jsonData, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (d *NotificationData) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan notification data: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, d)
+}
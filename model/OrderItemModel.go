@@ -1,11 +1,19 @@
 package model
 
 type OrderItem struct {
-	ID        string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	OrderID   string  `gorm:"type:uuid;not null;index" json:"order_id"`
-	ProductID string  `gorm:"type:uuid;not null" json:"product_id"`
-	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
-	Quantity  int     `gorm:"not null" json:"quantity"`
-	Price     int64   `gorm:"type:bigint;not null" json:"price"`
-	CreatedAt int64   `gorm:"type:bigint;not null" json:"created_at"`
+	ID        string          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID   string          `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID string          `gorm:"type:uuid;not null" json:"product_id"`
+	Product   Product         `gorm:"foreignKey:ProductID" json:"product"`
+	VariantID *string         `gorm:"type:uuid" json:"variant_id"`
+	Variant   *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+	// WarehouseID is the warehouse the item ships from. A cart item split
+	// across warehouses becomes one order item per warehouse.
+	WarehouseID *string `gorm:"type:uuid" json:"warehouse_id"`
+	Quantity    int     `gorm:"not null" json:"quantity"`
+	Price       int64   `gorm:"type:bigint;not null" json:"price"`
+	CreatedAt   int64   `gorm:"type:bigint;not null" json:"created_at"`
+	// Codes are the redemption codes of a digital product, only loaded for
+	// the buyer's order detail.
+	Codes []ProductCode `gorm:"foreignKey:OrderItemID" json:"-"`
 }
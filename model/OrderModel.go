@@ -13,14 +13,21 @@ const (
 )
 
 type Order struct {
-	ID          string         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	UserID      string         `gorm:"type:uuid;not null;index" json:"user_id"`
-	CartID      string         `gorm:"type:uuid;not null" json:"cart_id"`
-	Status      OrderStatus    `gorm:"type:varchar(20);not null" json:"status"`
-	TotalAmount int64          `gorm:"type:bigint;not null" json:"total_amount"`
-	Items       []OrderItem    `gorm:"foreignKey:OrderID" json:"items"`
-	CreatedAt   int64          `gorm:"type:bigint;not null" json:"created_at"`
-	UpdatedAt   int64          `gorm:"type:bigint;not null" json:"updated_at"`
-	PaidAt      *int64         `gorm:"type:bigint" json:"paid_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	ID          string      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      string      `gorm:"type:uuid;not null;index" json:"user_id"`
+	CartID      string      `gorm:"type:uuid;not null" json:"cart_id"`
+	Status      OrderStatus `gorm:"type:varchar(20);not null" json:"status"`
+	TotalAmount int64       `gorm:"type:bigint;not null" json:"total_amount"`
+	// ShippingAddress is a snapshot taken when the order was placed.
+	ShippingAddress *ShippingAddress `gorm:"type:jsonb" json:"shipping_address"`
+	Items           []OrderItem      `gorm:"foreignKey:OrderID" json:"items"`
+	CreatedAt       int64            `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt       int64            `gorm:"type:bigint;not null" json:"updated_at"`
+	PaidAt          *int64           `gorm:"type:bigint" json:"paid_at"`
+	// ExpiresAt is the payment deadline of a pending order, its stock is
+	// released and the order canceled after it.
+	ExpiresAt *int64 `gorm:"type:bigint" json:"expires_at"`
+	// Version is bumped on every write and exposed as the ETag.
+	Version   int64          `gorm:"type:bigint;not null;default:1" json:"version"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
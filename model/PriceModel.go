@@ -0,0 +1,62 @@
+package model
+
+const (
+	PriceReasonInitial   = "initial"
+	PriceReasonManual    = "manual"
+	PriceReasonImport    = "import"
+	PriceReasonScheduled = "scheduled"
+	PriceReasonSaleStart = "sale_start"
+	PriceReasonSaleEnd   = "sale_end"
+)
+
+// PriceChange is one entry of the price history. Price, and CompareAtPrice,
+// are those of the product, or of the variant when VariantID is set, from
+// CreatedAt until the next change.
+type PriceChange struct {
+	ID             string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID      string  `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID      *string `gorm:"type:uuid" json:"variant_id"`
+	Price          int     `gorm:"type:int;not null" json:"price"`
+	PreviousPrice  *int    `gorm:"type:int" json:"previous_price"`
+	CompareAtPrice *int    `gorm:"type:int" json:"compare_at_price"`
+	Reason         string  `gorm:"type:varchar(20);not null" json:"reason"`
+	ReferenceType  string  `gorm:"type:varchar(50)" json:"reference_type,omitempty"`
+	ReferenceID    string  `gorm:"type:varchar(100)" json:"reference_id,omitempty"`
+	ActorID        *string `gorm:"type:uuid" json:"actor_id"`
+	CreatedAt      int64   `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (c PriceChange) TableName() string {
+	return "price_changes"
+}
+
+const (
+	PriceScheduleStatusScheduled = "scheduled"
+	PriceScheduleStatusActive    = "active"
+	PriceScheduleStatusEnded     = "ended"
+	PriceScheduleStatusCanceled  = "canceled"
+)
+
+// PriceSchedule sets the price of a product, or of one of its variants, at
+// StartsAt. With EndsAt it is a sale: the price it replaced becomes the
+// compare-at price while it runs and is restored at EndsAt. Without EndsAt
+// the new price stays.
+type PriceSchedule struct {
+	ID        string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID string  `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID *string `gorm:"type:uuid" json:"variant_id"`
+	Price     int     `gorm:"type:int;not null" json:"price"`
+	StartsAt  int64   `gorm:"type:bigint;not null" json:"starts_at"`
+	EndsAt    *int64  `gorm:"type:bigint" json:"ends_at"`
+	Status    string  `gorm:"type:varchar(20);not null" json:"status"`
+	// RegularPrice is the price replaced when the schedule started.
+	RegularPrice *int    `gorm:"type:int" json:"regular_price"`
+	CreatedBy    *string `gorm:"type:uuid" json:"created_by"`
+	CreatedAt    int64   `gorm:"type:bigint;not null" json:"created_at"`
+	StartedAt    *int64  `gorm:"type:bigint" json:"started_at"`
+	EndedAt      *int64  `gorm:"type:bigint" json:"ended_at"`
+}
+
+func (s PriceSchedule) TableName() string {
+	return "price_schedules"
+}
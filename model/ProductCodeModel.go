@@ -0,0 +1,33 @@
+package model
+
+const (
+	ProductCodeTypeVoucher    = "voucher"
+	ProductCodeTypeLicenseKey = "license_key"
+)
+
+const (
+	ProductCodeAvailable = "available"
+	ProductCodeAssigned  = "assigned"
+	ProductCodeVoided    = "voided"
+)
+
+// ProductCode is one redemption code of a digital product. The stock of a
+// digital product is its number of available codes, and each unit sold is
+// assigned one code when the order is paid.
+type ProductCode struct {
+	ID          string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID   string  `gorm:"type:uuid;not null" json:"product_id"`
+	Code        string  `gorm:"type:varchar(255);not null" json:"code"`
+	Status      string  `gorm:"type:varchar(20);not null" json:"status"`
+	OrderID     *string `gorm:"type:uuid" json:"order_id"`
+	OrderItemID *string `gorm:"type:uuid" json:"order_item_id"`
+	// ReplacesID is the voided code this one was reissued for.
+	ReplacesID *string `gorm:"type:uuid" json:"replaces_id"`
+	CreatedAt  int64   `gorm:"type:bigint;not null" json:"created_at"`
+	AssignedAt *int64  `gorm:"type:bigint" json:"assigned_at"`
+	VoidedAt   *int64  `gorm:"type:bigint" json:"voided_at"`
+}
+
+func (c ProductCode) TableName() string {
+	return "product_codes"
+}
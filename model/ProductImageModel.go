@@ -0,0 +1,92 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// ImageFile is one stored rendition of an uploaded image.
+type ImageFile struct {
+	Key    string `json:"-"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageThumbnails maps a thumbnail size name (small, medium, large) to its file.
+type ImageThumbnails map[string]ImageFile
+
+// ProductImage is an uploaded image in a product gallery, ordered by Position.
+type ProductImage struct {
+	ID          string          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID   string          `gorm:"type:uuid;not null;index" json:"product_id"`
+	Position    int             `gorm:"type:int;not null;default:0" json:"position"`
+	StorageKey  string          `gorm:"type:varchar(255);not null" json:"-"`
+	URL         string          `gorm:"type:text;not null" json:"url"`
+	ContentType string          `gorm:"type:varchar(50);not null" json:"content_type"`
+	Size        int64           `gorm:"type:bigint;not null" json:"size"`
+	Width       int             `gorm:"type:int;not null" json:"width"`
+	Height      int             `gorm:"type:int;not null" json:"height"`
+	Thumbnails  ImageThumbnails `gorm:"type:jsonb" json:"thumbnails"`
+	CreatedAt   int64           `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (pi ProductImage) TableName() string {
+	return "product_images"
+}
+
+// StorageKeys lists every stored file of the image, original first.
+func (pi ProductImage) StorageKeys() []string {
+	keys := []string{pi.StorageKey}
+	for _, thumbnail := range pi.Thumbnails {
+		keys = append(keys, thumbnail.Key)
+	}
+	return keys
+}
+
+func (it ImageThumbnails) Value() (driver.Value, error) {
+	// Key is hidden from API responses but has to be persisted.
+	stored := make(map[string]map[string]interface{}, len(it))
+	for name, file := range it {
+		stored[name] = map[string]interface{}{
+			"key":    file.Key,
+			"url":    file.URL,
+			"width":  file.Width,
+			"height": file.Height,
+		}
+	}
+	jsonData, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (it *ImageThumbnails) Scan(value interface{}) error {
+	if value == nil {
+		*it = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan thumbnails: value is not []byte")
+	}
+
+	var stored map[string]struct {
+		Key    string `json:"key"`
+		URL    string `json:"url"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}
+	if err := json.Unmarshal(byteValue, &stored); err != nil {
+		return err
+	}
+
+	*it = make(ImageThumbnails, len(stored))
+	for name, file := range stored {
+		(*it)[name] = ImageFile{Key: file.Key, URL: file.URL, Width: file.Width, Height: file.Height}
+	}
+	return nil
+}
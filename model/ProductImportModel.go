@@ -0,0 +1,75 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	ProductImportStatusPending    = "pending"
+	ProductImportStatusProcessing = "processing"
+	ProductImportStatusCompleted  = "completed"
+	ProductImportStatusFailed     = "failed"
+)
+
+const (
+	ProductImportFormatCSV    = "csv"
+	ProductImportFormatNDJSON = "ndjson"
+)
+
+// ImportRowError reports why a row of an import was skipped. Row is 1-based
+// and counts data rows only, so the CSV header is not row 1.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+type ImportRowErrors []ImportRowError
+
+// ProductImport is a bulk upsert of products by SKU, processed in the background.
+type ProductImport struct {
+	ID            string          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID        string          `gorm:"type:uuid;not null;index" json:"user_id"`
+	Format        string          `gorm:"type:varchar(10);not null" json:"format"`
+	Status        string          `gorm:"type:varchar(20);not null" json:"status"`
+	FilePath      string          `gorm:"type:text" json:"-"`
+	ProcessedRows int             `gorm:"type:int;not null;default:0" json:"processed_rows"`
+	CreatedCount  int             `gorm:"type:int;not null;default:0" json:"created_count"`
+	UpdatedCount  int             `gorm:"type:int;not null;default:0" json:"updated_count"`
+	FailedCount   int             `gorm:"type:int;not null;default:0" json:"failed_count"`
+	RowErrors     ImportRowErrors `gorm:"type:jsonb" json:"row_errors"`
+	Error         string          `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt     int64           `gorm:"type:bigint;not null" json:"created_at"`
+	CompletedAt   *int64          `gorm:"type:bigint" json:"completed_at"`
+}
+
+func (i ProductImport) TableName() string {
+	return "product_imports"
+}
+
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	jsonData, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (e *ImportRowErrors) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan row errors: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, e)
+}
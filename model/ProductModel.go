@@ -12,21 +12,50 @@ type LocalProductOption []string
 type LocalProductImages []string
 
 type Product struct {
-	ID             string             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" db:"id" json:"id"`
-	Name           string             `gorm:"type:varchar(255);not null" db:"name" json:"name"`
-	Description    string             `gorm:"type:text" db:"description" json:"description"`
-	Image          LocalProductImages `gorm:"type:jsonb" db:"image" json:"image"`
-	Stock          int                `gorm:"type:int" db:"stock" json:"stock"`
-	Price          int                `gorm:"type:int" db:"price" json:"price"`
-	Weight         int                `gorm:"type:int" db:"weight" json:"weight"`
-	BasePrice      int                `gorm:"type:int" db:"base_price" json:"base_price"`
-	SKU            string             `gorm:"type:varchar(100)" db:"sku" json:"sku"`
-	Slug           string             `gorm:"type:varchar(255)" db:"slug" json:"slug"`
-	UniqueCodeType string             `gorm:"type:varchar(100)" db:"unique_code_type" json:"unique_code_type"`
-	Sold           bool               `gorm:"type:boolean;default:false" db:"sold" json:"sold"`
-	CreatedAt      int64              `gorm:"type:bigint;not null" db:"created_at" json:"created_at"`
-	DeletedAt      gorm.DeletedAt     `gorm:"" db:"deleted_at" json:"deleted_at"`
-	table          string             `gorm:"-"`
+	ID          string             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" db:"id" json:"id"`
+	Name        string             `gorm:"type:varchar(255);not null" db:"name" json:"name"`
+	Description string             `gorm:"type:text" db:"description" json:"description"`
+	Image       LocalProductImages `gorm:"type:jsonb" db:"image" json:"image"`
+	Stock       int                `gorm:"type:int" db:"stock" json:"stock"`
+	Price       int                `gorm:"type:int" db:"price" json:"price"`
+	// CompareAtPrice is the regular price while a sale is running, see
+	// PriceSchedule.
+	CompareAtPrice *int   `gorm:"type:int" db:"compare_at_price" json:"compare_at_price"`
+	Weight         int    `gorm:"type:int" db:"weight" json:"weight"`
+	BasePrice      int    `gorm:"type:int" db:"base_price" json:"base_price"`
+	SKU            string `gorm:"type:varchar(100)" db:"sku" json:"sku"`
+	Slug           string `gorm:"type:varchar(255)" db:"slug" json:"slug"`
+	// UniqueCodeType marks a digital product sold as redemption codes, see
+	// ProductCode. Its stock is the number of available codes.
+	UniqueCodeType string `gorm:"type:varchar(100)" db:"unique_code_type" json:"unique_code_type"`
+	Sold           bool   `gorm:"type:boolean;default:false" db:"sold" json:"sold"`
+	// VariantCount caches the number of live variants. Products with variants
+	// keep Stock as the sum of their variants' stock.
+	VariantCount int `gorm:"type:int;not null;default:0" db:"variant_count" json:"variant_count"`
+	// Reserved is held by unpaid orders, Available is what can still be sold.
+	Reserved  int `gorm:"type:int;not null;default:0" db:"reserved" json:"reserved"`
+	Available int `gorm:"->;-:migration" db:"available" json:"available"`
+	// ReorderThreshold alerts admins when an order takes Available below it,
+	// 0 disables the alert.
+	ReorderThreshold int `gorm:"type:int;not null;default:0" db:"reorder_threshold" json:"reorder_threshold"`
+	// RatingAverage and RatingCount cache the approved reviews.
+	RatingAverage float64 `gorm:"type:numeric(3,2);not null;default:0" db:"rating_average" json:"rating_average"`
+	RatingCount   int     `gorm:"type:int;not null;default:0" db:"rating_count" json:"rating_count"`
+	CreatedAt     int64   `gorm:"type:bigint;not null" db:"created_at" json:"created_at"`
+	// Version is bumped on every write and exposed as the ETag.
+	Version    int64            `gorm:"type:bigint;not null;default:1" db:"version" json:"version"`
+	DeletedAt  gorm.DeletedAt   `gorm:"" db:"deleted_at" json:"deleted_at"`
+	Categories []Category       `gorm:"many2many:product_categories" json:"categories,omitempty"`
+	Options    []ProductOption  `gorm:"foreignKey:ProductID" json:"options,omitempty"`
+	Variants   []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	// Images is the uploaded gallery. Once it has entries, Image mirrors
+	// their URLs in gallery order.
+	Images []ProductImage `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	// Only filled by searches. Highlights wrap matches in <mark> tags.
+	SearchRank    float64 `gorm:"->;-:migration" json:"search_rank,omitempty"`
+	NameHighlight string  `gorm:"->;-:migration" json:"name_highlight,omitempty"`
+	Snippet       string  `gorm:"->;-:migration" json:"snippet,omitempty"`
+	table         string  `gorm:"-"`
 }
 
 func (p Product) TableName() string {
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusHidden   = "hidden"
+)
+
+// ReviewImages are the photos attached to a review.
+type ReviewImages []ImageFile
+
+// ProductReview is a rating left by a customer with a complete order
+// containing the product. Only approved reviews are public and count towards
+// the product's rating.
+type ProductReview struct {
+	ID        string       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID string       `gorm:"type:uuid;not null" json:"product_id"`
+	UserID    string       `gorm:"type:uuid;not null" json:"user_id"`
+	User      *User        `gorm:"foreignKey:UserID" json:"-"`
+	OrderID   string       `gorm:"type:uuid;not null" json:"order_id"`
+	Rating    int          `gorm:"type:int;not null" json:"rating"`
+	Title     string       `gorm:"type:varchar(150)" json:"title"`
+	Body      string       `gorm:"type:text" json:"body"`
+	Images    ReviewImages `gorm:"type:jsonb" json:"images"`
+	Status    string       `gorm:"type:varchar(20);not null" json:"status"`
+	// HelpfulCount caches the number of helpful votes.
+	HelpfulCount int     `gorm:"type:int;not null;default:0" json:"helpful_count"`
+	ModeratedBy  *string `gorm:"type:uuid" json:"moderated_by"`
+	ModeratedAt  *int64  `gorm:"type:bigint" json:"moderated_at"`
+	CreatedAt    int64   `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (r ProductReview) TableName() string {
+	return "product_reviews"
+}
+
+// StorageKeys lists the stored files of the review's images.
+func (r ProductReview) StorageKeys() []string {
+	keys := make([]string, 0, len(r.Images))
+	for _, image := range r.Images {
+		keys = append(keys, image.Key)
+	}
+	return keys
+}
+
+// ReviewVote is a customer marking a review as helpful, at most once.
+type ReviewVote struct {
+	ReviewID  string `gorm:"type:uuid;primary_key" json:"review_id"`
+	UserID    string `gorm:"type:uuid;primary_key" json:"user_id"`
+	CreatedAt int64  `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (v ReviewVote) TableName() string {
+	return "review_votes"
+}
+
+func (ri ReviewImages) Value() (driver.Value, error) {
+	// Key is hidden from API responses but has to be persisted.
+	stored := make([]map[string]interface{}, len(ri))
+	for i, file := range ri {
+		stored[i] = map[string]interface{}{
+			"key":    file.Key,
+			"url":    file.URL,
+			"width":  file.Width,
+			"height": file.Height,
+		}
+	}
+	jsonData, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (ri *ReviewImages) Scan(value interface{}) error {
+	if value == nil {
+		*ri = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan review images: value is not []byte")
+	}
+
+	var stored []struct {
+		Key    string `json:"key"`
+		URL    string `json:"url"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}
+	if err := json.Unmarshal(byteValue, &stored); err != nil {
+		return err
+	}
+
+	*ri = make(ReviewImages, len(stored))
+	for i, file := range stored {
+		(*ri)[i] = ImageFile{Key: file.Key, URL: file.URL, Width: file.Width, Height: file.Height}
+	}
+	return nil
+}
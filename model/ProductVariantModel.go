@@ -0,0 +1,105 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ProductOption is an option type of a product, e.g. Size with the values
+// S, M and L. Every variant picks exactly one value per option.
+type ProductOption struct {
+	ID        string             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID string             `gorm:"type:uuid;not null;index" json:"product_id"`
+	Name      string             `gorm:"type:varchar(100);not null" json:"name"`
+	Values    LocalProductOption `gorm:"type:jsonb;not null" json:"values"`
+	Position  int                `gorm:"type:int;not null;default:0" json:"position"`
+}
+
+func (o ProductOption) TableName() string {
+	return "product_options"
+}
+
+// VariantOptions maps an option name to the value chosen by a variant.
+type VariantOptions map[string]string
+
+type ProductVariant struct {
+	ID        string         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID string         `gorm:"type:uuid;not null;index" json:"product_id"`
+	SKU       string         `gorm:"type:varchar(100);not null" json:"sku"`
+	Options   VariantOptions `gorm:"type:jsonb;not null" json:"options"`
+	Price     int            `gorm:"type:int;not null" json:"price"`
+	// CompareAtPrice is the regular price while a sale is running.
+	CompareAtPrice *int               `gorm:"type:int" json:"compare_at_price"`
+	Stock          int                `gorm:"type:int;not null" json:"stock"`
+	Reserved       int                `gorm:"type:int;not null;default:0" json:"reserved"`
+	Available      int                `gorm:"->;-:migration" json:"available"`
+	Image          LocalProductImages `gorm:"type:jsonb" json:"image"`
+	Position       int                `gorm:"type:int;not null;default:0" json:"position"`
+	CreatedAt      int64              `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt      int64              `gorm:"type:bigint;not null" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt     `gorm:"index" json:"-"`
+}
+
+func (v ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// Matches reports whether both option sets pick the same values.
+func (vo VariantOptions) Matches(other VariantOptions) bool {
+	if len(vo) != len(other) {
+		return false
+	}
+	for name, value := range vo {
+		if other[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (vo VariantOptions) Value() (driver.Value, error) {
+	jsonData, err := json.Marshal(vo)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (vo *VariantOptions) Scan(value interface{}) error {
+	if value == nil {
+		*vo = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan options: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, vo)
+}
+
+func (lpo LocalProductOption) Value() (driver.Value, error) {
+	jsonData, err := json.Marshal(lpo)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (lpo *LocalProductOption) Scan(value interface{}) error {
+	if value == nil {
+		*lpo = nil
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan option values: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, lpo)
+}
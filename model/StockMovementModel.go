@@ -0,0 +1,37 @@
+package model
+
+const (
+	StockReasonInitial       = "initial"
+	StockReasonOrderPlaced   = "order_placed"
+	StockReasonOrderCanceled = "order_canceled"
+	StockReasonAdjustment    = "adjustment"
+	StockReasonImport        = "import"
+	StockReasonReturn        = "return"
+	StockReasonTransfer      = "transfer"
+	StockReasonCodeUpload    = "code_upload"
+	StockReasonCodeVoid      = "code_void"
+	StockReasonCodeReissue   = "code_reissue"
+)
+
+// StockMovement is one entry of the inventory ledger. Quantity is signed,
+// StockAfter is the stock of the product, or of the variant when VariantID is
+// set, right after the movement. The stock columns are a cache of the sum of
+// these movements, and so is the stock of each warehouse.
+type StockMovement struct {
+	ID            string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ProductID     string  `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID     *string `gorm:"type:uuid" json:"variant_id"`
+	WarehouseID   *string `gorm:"type:uuid" json:"warehouse_id"`
+	Quantity      int     `gorm:"type:int;not null" json:"quantity"`
+	StockAfter    int     `gorm:"type:int;not null" json:"stock_after"`
+	Reason        string  `gorm:"type:varchar(20);not null" json:"reason"`
+	ReferenceType string  `gorm:"type:varchar(50)" json:"reference_type,omitempty"`
+	ReferenceID   string  `gorm:"type:varchar(100)" json:"reference_id,omitempty"`
+	Note          string  `gorm:"type:text" json:"note,omitempty"`
+	ActorID       *string `gorm:"type:uuid" json:"actor_id"`
+	CreatedAt     int64   `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (m StockMovement) TableName() string {
+	return "stock_movements"
+}
@@ -0,0 +1,28 @@
+package model
+
+const (
+	StockReservationActive    = "active"
+	StockReservationCommitted = "committed"
+	StockReservationReleased  = "released"
+)
+
+// StockReservation holds stock for a pending order until it is paid or its
+// deadline passes. Active reservations are cached in the reserved column of
+// the product, variant and warehouse stock.
+type StockReservation struct {
+	ID        string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID   string  `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID string  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID *string `gorm:"type:uuid" json:"variant_id"`
+	// WarehouseID is the warehouse the stock was allocated from.
+	WarehouseID *string `gorm:"type:uuid" json:"warehouse_id"`
+	Quantity    int     `gorm:"type:int;not null" json:"quantity"`
+	Status      string  `gorm:"type:varchar(20);not null" json:"status"`
+	ExpiresAt   int64   `gorm:"type:bigint;not null" json:"expires_at"`
+	CreatedAt   int64   `gorm:"type:bigint;not null" json:"created_at"`
+	ClosedAt    *int64  `gorm:"type:bigint" json:"closed_at"`
+}
+
+func (r StockReservation) TableName() string {
+	return "stock_reservations"
+}
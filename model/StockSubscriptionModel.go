@@ -0,0 +1,17 @@
+package model
+
+// StockSubscription asks to be notified when an out of stock product, or one
+// of its variants, is back in stock. It is closed once notified.
+type StockSubscription struct {
+	ID         string  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     string  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ProductID  string  `gorm:"type:uuid;not null" json:"product_id"`
+	Product    Product `gorm:"foreignKey:ProductID" json:"product"`
+	VariantID  *string `gorm:"type:uuid" json:"variant_id"`
+	CreatedAt  int64   `gorm:"type:bigint;not null" json:"created_at"`
+	NotifiedAt *int64  `gorm:"type:bigint" json:"notified_at"`
+}
+
+func (s StockSubscription) TableName() string {
+	return "stock_subscriptions"
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+type UserAddress struct {
+	ID            string `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID        string `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label         string `gorm:"type:varchar(50)" json:"label"`
+	RecipientName string `gorm:"type:varchar(255);not null" json:"recipient_name"`
+	Phone         string `gorm:"type:varchar(30)" json:"phone"`
+	Line1         string `gorm:"type:varchar(255);not null" json:"line1"`
+	Line2         string `gorm:"type:varchar(255)" json:"line2"`
+	City          string `gorm:"type:varchar(100);not null" json:"city"`
+	Region        string `gorm:"type:varchar(100)" json:"region"`
+	PostalCode    string `gorm:"type:varchar(20)" json:"postal_code"`
+	CountryCode   string `gorm:"type:char(2);not null" json:"country_code"`
+	IsDefault     bool   `gorm:"type:boolean;not null;default:false" json:"is_default"`
+	CreatedAt     int64  `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt     int64  `gorm:"type:bigint;not null" json:"updated_at"`
+}
+
+func (a UserAddress) TableName() string {
+	return "user_addresses"
+}
+
+// ShippingAddress is the copy of a UserAddress stored on an order, so later
+// edits to the address book do not change past orders.
+type ShippingAddress struct {
+	AddressID     string `json:"address_id"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2"`
+	City          string `json:"city"`
+	Region        string `json:"region"`
+	PostalCode    string `json:"postal_code"`
+	CountryCode   string `json:"country_code"`
+}
+
+func (a UserAddress) Snapshot() *ShippingAddress {
+	return &ShippingAddress{
+		AddressID:     a.ID,
+		RecipientName: a.RecipientName,
+		Phone:         a.Phone,
+		Line1:         a.Line1,
+		Line2:         a.Line2,
+		City:          a.City,
+		Region:        a.Region,
+		PostalCode:    a.PostalCode,
+		CountryCode:   a.CountryCode,
+	}
+}
+
+func (s ShippingAddress) Value() (driver.Value, error) {
+	jsonData, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonData), nil
+}
+
+func (s *ShippingAddress) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan shipping address: value is not []byte")
+	}
+
+	return json.Unmarshal(byteValue, s)
+}
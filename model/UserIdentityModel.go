@@ -0,0 +1,15 @@
+package model
+
+type UserIdentity struct {
+	ID          string `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      string `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider    string `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email       string `gorm:"type:varchar(255)" json:"email"`
+	CreatedAt   int64  `gorm:"type:bigint;not null" json:"created_at"`
+	LastLoginAt int64  `gorm:"type:bigint;not null" json:"last_login_at"`
+}
+
+func (i UserIdentity) TableName() string {
+	return "user_identities"
+}
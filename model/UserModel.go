@@ -1,12 +1,31 @@
 package model
 
+import "gorm.io/gorm"
+
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
 type User struct {
-	ID        string `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Username  string `gorm:"not null;uniqueIndex" db:"username" json:"username"`
-	Email     string `gorm:"not null;uniqueIndex" db:"email" json:"email"`
-	Password  string `gorm:"not null" db:"password" json:"-"`
-	CreatedAt int64  `gorm:"not null" db:"created_at" json:"created_at"`
-	table     string `gorm:"-"`
+	ID        string         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Username  string         `gorm:"not null;uniqueIndex" db:"username" json:"username"`
+	Email     string         `gorm:"not null;uniqueIndex" db:"email" json:"email"`
+	Password  string         `gorm:"not null" db:"password" json:"-"`
+	Role      string         `gorm:"type:varchar(20);not null;default:customer" db:"role" json:"role"`
+	Status    string         `gorm:"type:varchar(20);not null;default:active" db:"status" json:"status"`
+	CreatedAt int64          `gorm:"not null" db:"created_at" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" db:"deleted_at" json:"deleted_at"`
+	// SuspendedAt is set while Status is suspended.
+	SuspendedAt *int64 `db:"suspended_at" json:"suspended_at"`
+	// SessionsRevokedAt invalidates every access token issued before it.
+	SessionsRevokedAt *int64 `db:"sessions_revoked_at" json:"sessions_revoked_at"`
+	table             string `gorm:"-"`
 }
 
 func (p User) TableName() string {
@@ -15,3 +34,7 @@ func (p User) TableName() string {
 	}
 	return "users"
 }
+
+func (p User) IsSuspended() bool {
+	return p.Status == UserStatusSuspended
+}
@@ -0,0 +1,47 @@
+package model
+
+const (
+	FulfilmentClosest  = "closest"
+	FulfilmentPriority = "priority"
+	FulfilmentSplit    = "split"
+)
+
+// Warehouse is a location stock is shipped from. Lower priorities ship first.
+// Stock changes that name no warehouse, like a product edit, go to the default
+// one.
+type Warehouse struct {
+	ID          string `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Code        string `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	City        string `gorm:"type:varchar(100)" json:"city"`
+	Region      string `gorm:"type:varchar(100)" json:"region"`
+	PostalCode  string `gorm:"type:varchar(20)" json:"postal_code"`
+	CountryCode string `gorm:"type:char(2);not null" json:"country_code"`
+	Priority    int    `gorm:"type:int;not null;default:0" json:"priority"`
+	Active      bool   `gorm:"not null;default:true" json:"active"`
+	IsDefault   bool   `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt   int64  `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt   int64  `gorm:"type:bigint;not null" json:"updated_at"`
+}
+
+func (w Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// WarehouseStock is the stock of a product, or of one of its variants, held at
+// a warehouse. The stock of the product or variant is the sum over warehouses.
+type WarehouseStock struct {
+	ID          string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WarehouseID string     `gorm:"type:uuid;not null" json:"warehouse_id"`
+	Warehouse   *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	ProductID   string     `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID   *string    `gorm:"type:uuid" json:"variant_id"`
+	Quantity    int        `gorm:"type:int;not null;default:0" json:"quantity"`
+	Reserved    int        `gorm:"type:int;not null;default:0" json:"reserved"`
+	Available   int        `gorm:"->;-:migration" json:"available"`
+	UpdatedAt   int64      `gorm:"type:bigint;not null" json:"updated_at"`
+}
+
+func (s WarehouseStock) TableName() string {
+	return "warehouse_stocks"
+}
@@ -0,0 +1,38 @@
+package model
+
+// Wishlist is a named list of products a user saved for later. A wishlist
+// with a ShareToken can be viewed by anyone holding the link.
+type Wishlist struct {
+	ID         string         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	ShareToken *string        `gorm:"type:varchar(64)" json:"-"`
+	Items      []WishlistItem `gorm:"foreignKey:WishlistID" json:"items,omitempty"`
+	CreatedAt  int64          `gorm:"type:bigint;not null" json:"created_at"`
+	UpdatedAt  int64          `gorm:"type:bigint;not null" json:"updated_at"`
+}
+
+func (w Wishlist) TableName() string {
+	return "wishlists"
+}
+
+// WishlistItem is a product, or one of its variants, saved in a wishlist.
+// LastPrice is the price the user last saw, a lower price triggers a price
+// drop notification.
+type WishlistItem struct {
+	ID         string          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WishlistID string          `gorm:"type:uuid;not null" json:"wishlist_id"`
+	Wishlist   *Wishlist       `gorm:"foreignKey:WishlistID" json:"-"`
+	ProductID  string          `gorm:"type:uuid;not null" json:"product_id"`
+	Product    Product         `gorm:"foreignKey:ProductID" json:"product"`
+	VariantID  *string         `gorm:"type:uuid" json:"variant_id"`
+	Variant    *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+	LastPrice  int             `gorm:"type:int;not null" json:"last_price"`
+	// CurrentPrice is only filled by the price drop sweep.
+	CurrentPrice int   `gorm:"->;-:migration" json:"-"`
+	CreatedAt    int64 `gorm:"type:bigint;not null" json:"created_at"`
+}
+
+func (i WishlistItem) TableName() string {
+	return "wishlist_items"
+}
@@ -16,6 +16,9 @@ type AccountRepository interface {
 	UpdateBalance(ctx context.Context, tx *gorm.DB, id string, newBalance int64) error
 	CreateTransaction(ctx context.Context, transaction *model.Transaction) error
 	GetAccountByUserID(ctx context.Context, userID string) (*model.Account, error)
+	GetAccountsByUserID(ctx context.Context, userID string) ([]*model.Account, error)
+	LockUserAccounts(ctx context.Context, tx *gorm.DB, userID string) ([]*model.Account, error)
+	CloseUserAccounts(ctx context.Context, tx *gorm.DB, userID string) error
 }
 
 type accountRepository struct {
@@ -75,6 +78,36 @@ func (r *accountRepository) GetAccountByUserID(ctx context.Context, userID strin
 	return &account, nil
 }
 
+func (r *accountRepository) GetAccountsByUserID(ctx context.Context, userID string) ([]*model.Account, error) {
+	var accounts []*model.Account
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// LockUserAccounts is GetAccountsByUserID holding the rows until tx ends, so
+// balances cannot change meanwhile.
+func (r *accountRepository) LockUserAccounts(ctx context.Context, tx *gorm.DB, userID string) ([]*model.Account, error) {
+	var accounts []*model.Account
+	err := tx.WithContext(ctx).
+		Set("gorm:for_update", true).
+		Where("user_id = ?", userID).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *accountRepository) CloseUserAccounts(ctx context.Context, tx *gorm.DB, userID string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.Account{}).Error
+}
+
 func NewAccountRepository() AccountRepository {
 	return &accountRepository{db: config.GetDB()}
 }
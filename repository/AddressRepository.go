@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type AddressRepository interface {
+	BeginTx(ctx context.Context) *gorm.DB
+	CreateAddress(ctx context.Context, tx *gorm.DB, address *model.UserAddress) error
+	GetAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error)
+	GetUserAddresses(ctx context.Context, userID string) ([]*model.UserAddress, error)
+	GetDefaultAddress(ctx context.Context, userID string) (*model.UserAddress, error)
+	FindOldestAddress(ctx context.Context, tx *gorm.DB, userID string) (*model.UserAddress, error)
+	CountUserAddresses(ctx context.Context, userID string) (int64, error)
+	UpdateAddress(ctx context.Context, tx *gorm.DB, address *model.UserAddress) error
+	DeleteAddress(ctx context.Context, tx *gorm.DB, userID string, id string) error
+	ClearDefault(ctx context.Context, tx *gorm.DB, userID string) error
+	DeleteUserAddresses(ctx context.Context, tx *gorm.DB, userID string) error
+}
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository() AddressRepository {
+	return &addressRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *addressRepository) BeginTx(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Begin()
+}
+
+func (r *addressRepository) CreateAddress(ctx context.Context, tx *gorm.DB, address *model.UserAddress) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Create(address).Error
+}
+
+func (r *addressRepository) GetAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error) {
+	var address model.UserAddress
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) GetUserAddresses(ctx context.Context, userID string) ([]*model.UserAddress, error) {
+	var addresses []*model.UserAddress
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at ASC").
+		Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *addressRepository) GetDefaultAddress(ctx context.Context, userID string) (*model.UserAddress, error) {
+	var address model.UserAddress
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) FindOldestAddress(ctx context.Context, tx *gorm.DB, userID string) (*model.UserAddress, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	var address model.UserAddress
+	err := db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) CountUserAddresses(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.UserAddress{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *addressRepository) UpdateAddress(ctx context.Context, tx *gorm.DB, address *model.UserAddress) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Save(address).Error
+}
+
+func (r *addressRepository) DeleteAddress(ctx context.Context, tx *gorm.DB, userID string, id string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	result := db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.UserAddress{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *addressRepository) ClearDefault(ctx context.Context, tx *gorm.DB, userID string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Model(&model.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
+
+func (r *addressRepository) DeleteUserAddresses(ctx context.Context, tx *gorm.DB, userID string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserAddress{}).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type ApiTokenRepository interface {
+	CreateToken(ctx context.Context, token *model.ApiToken) error
+	FindByHash(ctx context.Context, hash string) (*model.ApiToken, error)
+	FindUserTokens(ctx context.Context, userID string) ([]*model.ApiToken, error)
+	RevokeToken(ctx context.Context, userID string, id string, revokedAt int64) error
+	TouchToken(ctx context.Context, id string, usedAt int64) error
+	RevokeAllUserTokens(ctx context.Context, userID string, revokedAt int64) error
+}
+
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewApiTokenRepository() ApiTokenRepository {
+	return &apiTokenRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *apiTokenRepository) CreateToken(ctx context.Context, token *model.ApiToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *apiTokenRepository) FindByHash(ctx context.Context, hash string) (*model.ApiToken, error) {
+	var token model.ApiToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) FindUserTokens(ctx context.Context, userID string) ([]*model.ApiToken, error) {
+	var tokens []*model.ApiToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) RevokeToken(ctx context.Context, userID string, id string, revokedAt int64) error {
+	result := r.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", revokedAt)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *apiTokenRepository) TouchToken(ctx context.Context, id string, usedAt int64) error {
+	return r.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
+
+func (r *apiTokenRepository) RevokeAllUserTokens(ctx context.Context, userID string, revokedAt int64) error {
+	return r.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	From       int64
+	To         int64
+}
+
+type AuditRepository interface {
+	CreateEvent(ctx context.Context, event *model.AuditEvent) error
+	FindEvents(ctx context.Context, filter AuditFilter, page, limit int) ([]*model.AuditEvent, int64, error)
+	FindUserSecurityEvents(ctx context.Context, userID string, actions []string, limit int) ([]*model.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository() AuditRepository {
+	return &auditRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *auditRepository) CreateEvent(ctx context.Context, event *model.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *auditRepository) FindEvents(ctx context.Context, filter AuditFilter, page, limit int) ([]*model.AuditEvent, int64, error) {
+	var events []*model.AuditEvent
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditEvent{})
+
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.From > 0 {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if filter.To > 0 {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *auditRepository) FindUserSecurityEvents(ctx context.Context, userID string, actions []string, limit int) ([]*model.AuditEvent, error) {
+	var events []*model.AuditEvent
+	err := r.db.WithContext(ctx).
+		Where("action IN ?", actions).
+		Where("actor_id = ? OR (target_type = ? AND target_id = ?)", userID, "user", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -14,7 +14,9 @@ type CartRepository interface {
 	AddItem(ctx context.Context, cartItem *model.CartItem) error
 	UpdateItem(ctx context.Context, cartItem *model.CartItem) error
 	RemoveItem(ctx context.Context, cartItemID string) error
-	GetCartItem(ctx context.Context, cartID string, productID string) (*model.CartItem, error)
+	GetCartItem(ctx context.Context, cartID string, productID string, variantID *string) (*model.CartItem, error)
+	DeleteUserCart(ctx context.Context, tx *gorm.DB, userID string) error
+	FindUserCart(ctx context.Context, userID string) (*model.Cart, error)
 }
 
 type cartRepository struct {
@@ -23,7 +25,15 @@ type cartRepository struct {
 
 func (r *cartRepository) GetCart(ctx context.Context, userID string) (*model.Cart, error) {
 	var cart model.Cart
-	err := r.db.Preload("Items.Product").Where("user_id = ?", userID).First(&cart).Error
+	// Items of soft-deleted products and variants stay in the table but are hidden.
+	err := r.db.
+		Preload("Items", "product_id IN (?) AND (variant_id IS NULL OR variant_id IN (?))",
+			r.db.Model(&model.Product{}).Select("id"),
+			r.db.Model(&model.ProductVariant{}).Select("id")).
+		Preload("Items.Product").
+		Preload("Items.Variant").
+		Where("user_id = ?", userID).
+		First(&cart).Error
 	if err == gorm.ErrRecordNotFound {
 		cart = model.Cart{
 			UserID:    userID,
@@ -51,15 +61,49 @@ func (r *cartRepository) RemoveItem(ctx context.Context, cartItemID string) erro
 	return r.db.Delete(&model.CartItem{}, cartItemID).Error
 }
 
-func (r *cartRepository) GetCartItem(ctx context.Context, cartID string, productID string) (*model.CartItem, error) {
+func (r *cartRepository) GetCartItem(ctx context.Context, cartID string, productID string, variantID *string) (*model.CartItem, error) {
 	var cartItem model.CartItem
-	err := r.db.Where("cart_id = ? AND product_id = ?", cartID, productID).First(&cartItem).Error
+	query := r.db.Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	err := query.First(&cartItem).Error
 	if err != nil {
 		return nil, err
 	}
 	return &cartItem, nil
 }
 
+// FindUserCart is like GetCart but never creates a cart.
+func (r *cartRepository) FindUserCart(ctx context.Context, userID string) (*model.Cart, error) {
+	var cart model.Cart
+	err := r.db.WithContext(ctx).
+		Preload("Items.Product").
+		Preload("Items.Variant", unscoped).
+		Where("user_id = ?", userID).
+		First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) DeleteUserCart(ctx context.Context, tx *gorm.DB, userID string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	if err := db.WithContext(ctx).
+		Where("cart_id IN (SELECT id FROM carts WHERE user_id = ?)", userID).
+		Delete(&model.CartItem{}).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.Cart{}).Error
+}
+
 func NewCartRepository() CartRepository {
 	return &cartRepository{db: config.GetDB()}
 }
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *model.Category) error
+	GetCategory(ctx context.Context, id string) (*model.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error)
+	GetAllCategories(ctx context.Context) ([]*model.Category, error)
+	GetCategoriesByIDs(ctx context.Context, ids []string) ([]*model.Category, error)
+	UpdateCategory(ctx context.Context, category *model.Category) error
+	DeleteCategory(ctx context.Context, id string) error
+	SlugExists(ctx context.Context, slug string, excludeID string) (bool, error)
+	CountChildren(ctx context.Context, id string) (int64, error)
+	GetDescendantIDs(ctx context.Context, id string) ([]string, error)
+	SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository() CategoryRepository {
+	return &categoryRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, category *model.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *categoryRepository) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.WithContext(ctx).First(&category, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.WithContext(ctx).First(&category, "slug = ?", slug).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetAllCategories(ctx context.Context) ([]*model.Category, error) {
+	var categories []*model.Category
+	err := r.db.WithContext(ctx).
+		Order("position ASC, name ASC").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) GetCategoriesByIDs(ctx context.Context, ids []string) ([]*model.Category, error) {
+	var categories []*model.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, category *model.Category) error {
+	return r.db.WithContext(ctx).Save(category).Error
+}
+
+// DeleteCategory removes the category and its product memberships.
+func (r *categoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", id).Delete(&model.ProductCategory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&model.Category{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *categoryRepository) SlugExists(ctx context.Context, slug string, excludeID string) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&model.Category{}).Where("slug = ?", slug)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *categoryRepository) CountChildren(ctx context.Context, id string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Category{}).
+		Where("parent_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
+
+// GetDescendantIDs returns the category itself and every category below it.
+func (r *categoryRepository) GetDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree`, id).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *categoryRepository) SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+		rows := make([]model.ProductCategory, len(categoryIDs))
+		for i, categoryID := range categoryIDs {
+			rows[i] = model.ProductCategory{ProductID: productID, CategoryID: categoryID}
+		}
+		return tx.Create(&rows).Error
+	})
+}
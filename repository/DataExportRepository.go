@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	CreateExport(ctx context.Context, export *model.DataExport) error
+	GetExport(ctx context.Context, id string) (*model.DataExport, error)
+	UpdateExport(ctx context.Context, export *model.DataExport) error
+	FindByStatus(ctx context.Context, statuses []string) ([]*model.DataExport, error)
+	FindExpired(ctx context.Context, now int64) ([]*model.DataExport, error)
+	FindActiveUserExport(ctx context.Context, userID string) (*model.DataExport, error)
+	ClaimExport(ctx context.Context, id string) (bool, error)
+	FinishExport(ctx context.Context, export *model.DataExport) (bool, error)
+	ExpireUserExports(ctx context.Context, tx *gorm.DB, userID string) ([]string, error)
+	ResetProcessing(ctx context.Context) error
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository() DataExportRepository {
+	return &dataExportRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *dataExportRepository) CreateExport(ctx context.Context, export *model.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+func (r *dataExportRepository) GetExport(ctx context.Context, id string) (*model.DataExport, error) {
+	var export model.DataExport
+	if err := r.db.WithContext(ctx).First(&export, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) UpdateExport(ctx context.Context, export *model.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+func (r *dataExportRepository) FindByStatus(ctx context.Context, statuses []string) ([]*model.DataExport, error) {
+	var exports []*model.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", statuses).
+		Order("created_at ASC").
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *dataExportRepository) FindExpired(ctx context.Context, now int64) ([]*model.DataExport, error) {
+	var exports []*model.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", model.DataExportStatusReady, now).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *dataExportRepository) FindActiveUserExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	var export model.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status IN ?", userID, []string{model.DataExportStatusPending, model.DataExportStatusProcessing}).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ClaimExport moves a pending export to processing, reporting false when
+// another worker got there first.
+func (r *dataExportRepository) ClaimExport(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.DataExport{}).
+		Where("id = ? AND status = ?", id, model.DataExportStatusPending).
+		Update("status", model.DataExportStatusProcessing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FinishExport stores a built export, reporting false when it stopped
+// processing meanwhile, e.g. because its user was deleted.
+func (r *dataExportRepository) FinishExport(ctx context.Context, export *model.DataExport) (bool, error) {
+	result := r.db.WithContext(ctx).Model(export).
+		Where("status = ?", model.DataExportStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       export.Status,
+			"file_path":    export.FilePath,
+			"file_size":    export.FileSize,
+			"completed_at": export.CompletedAt,
+			"expires_at":   export.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpireUserExports expires every pending, running or downloadable export of
+// the user and returns the archive files to remove.
+func (r *dataExportRepository) ExpireUserExports(ctx context.Context, tx *gorm.DB, userID string) ([]string, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	active := []string{model.DataExportStatusPending, model.DataExportStatusProcessing, model.DataExportStatusReady}
+	var paths []string
+	err := db.WithContext(ctx).Model(&model.DataExport{}).
+		Where("user_id = ? AND status IN ? AND file_path <> ''", userID, active).
+		Pluck("file_path", &paths).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.WithContext(ctx).Model(&model.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, active).
+		Updates(map[string]interface{}{
+			"status":    model.DataExportStatusExpired,
+			"file_path": "",
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// ResetProcessing requeues exports that were interrupted by a restart.
+func (r *dataExportRepository) ResetProcessing(ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.DataExport{}).
+		Where("status = ?", model.DataExportStatusProcessing).
+		Update("status", model.DataExportStatusPending).Error
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *model.Notification) error
+	GetUserNotifications(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]*model.Notification, int64, error)
+	MarkRead(ctx context.Context, userID string, id string, readAt int64) error
+	FindPendingEmails(ctx context.Context, limit int) ([]string, error)
+	ClaimEmail(ctx context.Context, id string) (*model.Notification, error)
+	UpdateEmailStatus(ctx context.Context, notification *model.Notification) error
+	ResetSendingEmails(ctx context.Context) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository() NotificationRepository {
+	return &notificationRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, notification *model.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *notificationRepository) GetUserNotifications(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]*model.Notification, int64, error) {
+	var notifications []*model.Notification
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID string, id string, readAt int64) error {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", readAt)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// FindPendingEmails returns the IDs of notifications waiting to be emailed,
+// oldest first.
+func (r *notificationRepository) FindPendingEmails(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("email_status = ?", model.NotificationEmailPending).
+		Order("created_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ClaimEmail moves a pending email to sending and returns its notification,
+// so only one worker delivers it.
+func (r *notificationRepository) ClaimEmail(ctx context.Context, id string) (*model.Notification, error) {
+	var notification model.Notification
+	result := r.db.WithContext(ctx).Model(&notification).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND email_status = ?", id, model.NotificationEmailPending).
+		Update("email_status", model.NotificationEmailSending)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) UpdateEmailStatus(ctx context.Context, notification *model.Notification) error {
+	return r.db.WithContext(ctx).Model(notification).
+		Select("email_status", "email_attempts", "emailed_at").
+		Updates(notification).Error
+}
+
+// ResetSendingEmails puts emails left sending by a stopped worker back in the
+// queue.
+func (r *notificationRepository) ResetSendingEmails(ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("email_status = ?", model.NotificationEmailSending).
+		Update("email_status", model.NotificationEmailPending).Error
+}
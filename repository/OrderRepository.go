@@ -4,6 +4,7 @@ import (
 	"context"
 	"nuxatech-nextmedis/config"
 	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/utils"
 
 	"gorm.io/gorm"
 )
@@ -12,8 +13,12 @@ type OrderRepository interface {
 	BeginTx(ctx context.Context) *gorm.DB
 	CreateOrder(ctx context.Context, tx *gorm.DB, order *model.Order) error
 	GetOrder(ctx context.Context, id string) (*model.Order, error)
-	UpdateOrder(ctx context.Context, order *model.Order) error
+	UpdateOrder(ctx context.Context, tx *gorm.DB, order *model.Order) error
 	GetUserOrders(ctx context.Context, userID string, page, limit int) ([]*model.Order, int64, error)
+	GetUserOrdersAfter(ctx context.Context, userID string, cursor *utils.Cursor, limit int) ([]*model.Order, *CursorPage, error)
+	CountUserOrders(ctx context.Context, userID string) (int64, error)
+	GetAllUserOrders(ctx context.Context, userID string) ([]*model.Order, error)
+	FindExpiredOrders(ctx context.Context, now int64, limit int) ([]*model.Order, error)
 }
 
 type orderRepository struct {
@@ -32,13 +37,16 @@ func (r *orderRepository) CreateOrder(ctx context.Context, tx *gorm.DB, order *m
 
 	return db.Transaction(func(tx *gorm.DB) error {
 		orderOnly := &model.Order{
-			ID:          order.ID,
-			UserID:      order.UserID,
-			CartID:      order.CartID,
-			Status:      order.Status,
-			TotalAmount: order.TotalAmount,
-			CreatedAt:   order.CreatedAt,
-			UpdatedAt:   order.UpdatedAt,
+			ID:              order.ID,
+			UserID:          order.UserID,
+			CartID:          order.CartID,
+			Status:          order.Status,
+			TotalAmount:     order.TotalAmount,
+			ShippingAddress: order.ShippingAddress,
+			CreatedAt:       order.CreatedAt,
+			UpdatedAt:       order.UpdatedAt,
+			ExpiresAt:       order.ExpiresAt,
+			Version:         order.Version,
 		}
 
 		if err := tx.Create(orderOnly).Error; err != nil {
@@ -48,11 +56,13 @@ func (r *orderRepository) CreateOrder(ctx context.Context, tx *gorm.DB, order *m
 		items := make([]model.OrderItem, len(order.Items))
 		for i, item := range order.Items {
 			items[i] = model.OrderItem{
-				OrderID:   orderOnly.ID,
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				Price:     item.Price,
-				CreatedAt: item.CreatedAt,
+				OrderID:     orderOnly.ID,
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				WarehouseID: item.WarehouseID,
+				Quantity:    item.Quantity,
+				Price:       item.Price,
+				CreatedAt:   item.CreatedAt,
 			}
 		}
 
@@ -64,7 +74,8 @@ func (r *orderRepository) CreateOrder(ctx context.Context, tx *gorm.DB, order *m
 
 		if err := tx.Where("id = ?", orderOnly.ID).
 			Preload("Items").
-			Preload("Items.Product").
+			Preload("Items.Product", unscoped).
+			Preload("Items.Variant", unscoped).
 			Find(order).Error; err != nil {
 			return err
 		}
@@ -77,7 +88,9 @@ func (r *orderRepository) GetOrder(ctx context.Context, id string) (*model.Order
 	var order model.Order
 	err := r.db.WithContext(ctx).
 		Where("id = ?", id).
-		Preload("Items.Product").
+		Preload("Items.Product", unscoped).
+		Preload("Items.Variant", unscoped).
+		Preload("Items.Codes", "status = ?", model.ProductCodeAssigned).
 		Find(&order).Error
 	if err != nil {
 		return nil, err
@@ -91,8 +104,31 @@ func (r *orderRepository) GetOrder(ctx context.Context, id string) (*model.Order
 	return &order, nil
 }
 
-func (r *orderRepository) UpdateOrder(ctx context.Context, order *model.Order) error {
-	return r.db.WithContext(ctx).Save(order).Error
+// UpdateOrder saves the order only if nobody else wrote it since it was read,
+// and bumps its version. gorm.ErrRecordNotFound signals a version conflict.
+func (r *orderRepository) UpdateOrder(ctx context.Context, tx *gorm.DB, order *model.Order) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	expected := order.Version
+	order.Version++
+
+	result := db.WithContext(ctx).Model(order).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("Items", "created_at").
+		Updates(order)
+	if result.Error != nil {
+		order.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		order.Version = expected
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 func (r *orderRepository) GetUserOrders(ctx context.Context, userID string, page, limit int) ([]*model.Order, int64, error) {
 	var orders []*model.Order
@@ -109,7 +145,8 @@ func (r *orderRepository) GetUserOrders(ctx context.Context, userID string, page
 	offset := (page - 1) * limit
 	err := query.Offset(offset).
 		Limit(limit).
-		Preload("Items.Product").
+		Preload("Items.Product", unscoped).
+		Preload("Items.Variant", unscoped).
 		Order("created_at DESC").
 		Find(&orders).
 		Error
@@ -121,6 +158,69 @@ func (r *orderRepository) GetUserOrders(ctx context.Context, userID string, page
 	return orders, total, nil
 }
 
+var orderKeyset = keyset{Sort: "newest", Column: "created_at", Desc: true}
+
+func (r *orderRepository) GetUserOrdersAfter(ctx context.Context, userID string, cursor *utils.Cursor, limit int) ([]*model.Order, *CursorPage, error) {
+	query, err := orderKeyset.apply(r.db.WithContext(ctx).Where("user_id = ?", userID), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var orders []*model.Order
+	err = query.
+		Preload("Items.Product", unscoped).
+		Preload("Items.Variant", unscoped).
+		Find(&orders).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, page := paginate(orderKeyset, orders, cursor, limit, func(order *model.Order) (utils.Cursor, string) {
+		return utils.Cursor{Int: order.CreatedAt}, order.ID
+	})
+	return orders, page, nil
+}
+
+func (r *orderRepository) CountUserOrders(ctx context.Context, userID string) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.Order{}).Where("user_id = ?", userID).Count(&total).Error
+	return total, err
+}
+
+func (r *orderRepository) GetAllUserOrders(ctx context.Context, userID string) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Preload("Items.Product", unscoped).
+		Preload("Items.Variant", unscoped).
+		Order("created_at DESC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// unscoped lets preloads include soft-deleted rows that orders still reference.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
 func NewOrderRepository() OrderRepository {
 	return &orderRepository{db: config.GetDB()}
 }
+
+// FindExpiredOrders returns pending orders whose payment deadline has passed.
+func (r *orderRepository) FindExpiredOrders(ctx context.Context, now int64, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", model.OrderStatusPending, now).
+		Preload("Items").
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -14,6 +14,7 @@ type PersonalTokenRepository interface {
 	FindByToken(ctx context.Context, token string) (*model.PersonalToken, error)
 	DeleteToken(ctx context.Context, id string) error
 	DeleteAllUserTokens(ctx context.Context, userID string) error
+	FindUserTokens(ctx context.Context, userID string) ([]*model.PersonalToken, error)
 }
 
 type personalTokenRepository struct {
@@ -55,3 +56,15 @@ func (r *personalTokenRepository) DeleteToken(ctx context.Context, id string) er
 func (r *personalTokenRepository) DeleteAllUserTokens(ctx context.Context, userID string) error {
 	return r.db.WithContext(ctx).Delete(&model.PersonalToken{}, "user_id = ?", userID).Error
 }
+
+func (r *personalTokenRepository) FindUserTokens(ctx context.Context, userID string) ([]*model.PersonalToken, error) {
+	var tokens []*model.PersonalToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PriceRepository interface {
+	GetPriceHistory(ctx context.Context, productID string, variantID string, page int, limit int) ([]*model.PriceChange, int64, error)
+	CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error
+	GetSchedule(ctx context.Context, productID string, id string) (*model.PriceSchedule, error)
+	GetSchedules(ctx context.Context, productID string, all bool) ([]*model.PriceSchedule, error)
+	ScheduleOverlaps(ctx context.Context, schedule *model.PriceSchedule) (bool, error)
+	CancelSchedule(ctx context.Context, id string) (bool, error)
+	FindSchedulesToStart(ctx context.Context, now int64, limit int) ([]*model.PriceSchedule, error)
+	FindSchedulesToEnd(ctx context.Context, now int64, limit int) ([]*model.PriceSchedule, error)
+	StartSchedule(ctx context.Context, id string, now int64) error
+	EndSchedule(ctx context.Context, id string, now int64) error
+}
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository() PriceRepository {
+	return &priceRepository{db: config.GetDB()}
+}
+
+// GetPriceHistory returns the price changes of a product and its variants,
+// newest first. A variantID narrows it to that variant.
+func (r *priceRepository) GetPriceHistory(ctx context.Context, productID string, variantID string, page int, limit int) ([]*model.PriceChange, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.PriceChange{}).Where("product_id = ?", productID)
+	if variantID != "" {
+		query = query.Where("variant_id = ?", variantID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var changes []*model.PriceChange
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&changes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return changes, total, nil
+}
+
+func (r *priceRepository) CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error {
+	return r.db.WithContext(ctx).Create(schedule).Error
+}
+
+func (r *priceRepository) GetSchedule(ctx context.Context, productID string, id string) (*model.PriceSchedule, error) {
+	var schedule model.PriceSchedule
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND product_id = ?", id, productID).
+		Take(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetSchedules returns the upcoming and running schedules of a product by
+// start time, or every schedule when all is set.
+func (r *priceRepository) GetSchedules(ctx context.Context, productID string, all bool) ([]*model.PriceSchedule, error) {
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID)
+	if !all {
+		query = query.Where("status IN ?", []string{model.PriceScheduleStatusScheduled, model.PriceScheduleStatusActive})
+	}
+
+	var schedules []*model.PriceSchedule
+	if err := query.Order("starts_at ASC").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ScheduleOverlaps reports whether another upcoming or running schedule sets
+// the same price while this one would. A schedule without an end only takes
+// its start instant, so it may follow a sale ending at that time.
+func (r *priceRepository) ScheduleOverlaps(ctx context.Context, schedule *model.PriceSchedule) (bool, error) {
+	end := schedule.StartsAt + 1
+	if schedule.EndsAt != nil {
+		end = *schedule.EndsAt
+	}
+
+	query := r.db.WithContext(ctx).Model(&model.PriceSchedule{}).
+		Where("product_id = ? AND status IN ?", schedule.ProductID,
+			[]string{model.PriceScheduleStatusScheduled, model.PriceScheduleStatusActive}).
+		Where("starts_at < ? AND COALESCE(ends_at, starts_at + 1) > ?", end, schedule.StartsAt)
+	if schedule.VariantID != nil {
+		query = query.Where("variant_id = ?", *schedule.VariantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	if schedule.ID != "" {
+		query = query.Where("id <> ?", schedule.ID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CancelSchedule cancels a schedule that has not started. It reports false
+// when the schedule already started.
+func (r *priceRepository) CancelSchedule(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.PriceSchedule{}).
+		Where("id = ? AND status = ?", id, model.PriceScheduleStatusScheduled).
+		Update("status", model.PriceScheduleStatusCanceled)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindSchedulesToStart returns the schedules whose start time has passed.
+func (r *priceRepository) FindSchedulesToStart(ctx context.Context, now int64, limit int) ([]*model.PriceSchedule, error) {
+	var schedules []*model.PriceSchedule
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND starts_at <= ?", model.PriceScheduleStatusScheduled, now).
+		Order("starts_at ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// FindSchedulesToEnd returns the running sales whose end time has passed.
+func (r *priceRepository) FindSchedulesToEnd(ctx context.Context, now int64, limit int) ([]*model.PriceSchedule, error) {
+	var schedules []*model.PriceSchedule
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND ends_at <= ?", model.PriceScheduleStatusActive, now).
+		Order("ends_at ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// StartSchedule applies the price of a schedule. A sale keeps the price it
+// replaced as the compare-at price when it is lower. A schedule whose product
+// or variant was deleted is canceled. gorm.ErrRecordNotFound means the
+// schedule is no longer waiting to start.
+func (r *priceRepository) StartSchedule(ctx context.Context, id string, now int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedule, err := lockSchedule(tx, id, model.PriceScheduleStatusScheduled)
+		if err != nil {
+			return err
+		}
+
+		regular, _, err := lockPriceTarget(tx, schedule)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Model(schedule).Update("status", model.PriceScheduleStatusCanceled).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		change := newScheduledPriceChange(schedule, now)
+		change.Reason = model.PriceReasonScheduled
+		change.PreviousPrice = &regular
+		change.Price = schedule.Price
+		updates := map[string]interface{}{
+			"status":        model.PriceScheduleStatusEnded,
+			"regular_price": regular,
+			"started_at":    now,
+			"ended_at":      now,
+		}
+		if schedule.EndsAt != nil {
+			change.Reason = model.PriceReasonSaleStart
+			if schedule.Price < regular {
+				change.CompareAtPrice = &regular
+			}
+			updates["status"] = model.PriceScheduleStatusActive
+			updates["ended_at"] = nil
+		}
+
+		if err := setTargetPrice(tx, schedule, change.Price, change.CompareAtPrice, now); err != nil {
+			return err
+		}
+		if err := logPriceChange(tx, change); err != nil {
+			return err
+		}
+		return tx.Model(schedule).Updates(updates).Error
+	})
+}
+
+// EndSchedule ends a running sale, restoring the regular price unless the
+// price was changed by hand meanwhile. gorm.ErrRecordNotFound means the sale
+// is not running.
+func (r *priceRepository) EndSchedule(ctx context.Context, id string, now int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedule, err := lockSchedule(tx, id, model.PriceScheduleStatusActive)
+		if err != nil {
+			return err
+		}
+
+		price, compareAt, err := lockPriceTarget(tx, schedule)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && price == schedule.Price && schedule.RegularPrice != nil {
+			change := newScheduledPriceChange(schedule, now)
+			change.Reason = model.PriceReasonSaleEnd
+			change.PreviousPrice = &price
+			change.Price = *schedule.RegularPrice
+			if err := setTargetPrice(tx, schedule, change.Price, nil, now); err != nil {
+				return err
+			}
+			if err := logPriceChange(tx, change); err != nil {
+				return err
+			}
+		} else if err == nil && compareAt != nil {
+			if err := setTargetPrice(tx, schedule, price, nil, now); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(schedule).Updates(map[string]interface{}{
+			"status":   model.PriceScheduleStatusEnded,
+			"ended_at": now,
+		}).Error
+	})
+}
+
+func lockSchedule(tx *gorm.DB, id string, status string) (*model.PriceSchedule, error) {
+	var schedule model.PriceSchedule
+	err := tx.Set("gorm:for_update", true).
+		Where("id = ? AND status = ?", id, status).
+		Take(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// lockPriceTarget locks the live product or variant a schedule prices and
+// returns its price and compare-at price.
+func lockPriceTarget(tx *gorm.DB, schedule *model.PriceSchedule) (int, *int, error) {
+	if schedule.VariantID != nil {
+		var variant model.ProductVariant
+		err := tx.Set("gorm:for_update", true).
+			Where("id = ? AND product_id = ?", *schedule.VariantID, schedule.ProductID).
+			Take(&variant).Error
+		return variant.Price, variant.CompareAtPrice, err
+	}
+
+	var product model.Product
+	err := tx.Set("gorm:for_update", true).
+		Where("id = ?", schedule.ProductID).
+		Take(&product).Error
+	return product.Price, product.CompareAtPrice, err
+}
+
+// setTargetPrice writes the price of the product or variant a schedule prices
+// and bumps the product version, so cached copies are refreshed.
+func setTargetPrice(tx *gorm.DB, schedule *model.PriceSchedule, price int, compareAt *int, now int64) error {
+	productUpdates := map[string]interface{}{
+		"version": gorm.Expr("version + 1"),
+	}
+	if schedule.VariantID != nil {
+		err := tx.Model(&model.ProductVariant{}).
+			Where("id = ?", *schedule.VariantID).
+			Updates(map[string]interface{}{
+				"price":            price,
+				"compare_at_price": compareAt,
+				"updated_at":       now,
+			}).Error
+		if err != nil {
+			return err
+		}
+	} else {
+		productUpdates["price"] = price
+		productUpdates["compare_at_price"] = compareAt
+	}
+	return tx.Model(&model.Product{}).Where("id = ?", schedule.ProductID).Updates(productUpdates).Error
+}
+
+func newScheduledPriceChange(schedule *model.PriceSchedule, now int64) *model.PriceChange {
+	return &model.PriceChange{
+		ProductID:     schedule.ProductID,
+		VariantID:     schedule.VariantID,
+		ReferenceType: "price_schedule",
+		ReferenceID:   schedule.ID,
+		ActorID:       schedule.CreatedBy,
+		CreatedAt:     now,
+	}
+}
+
+// logPriceChange adds an entry to the price history.
+func logPriceChange(tx *gorm.DB, change *model.PriceChange) error {
+	if change.CreatedAt == 0 {
+		change.CreatedAt = time.Now().UnixMilli()
+	}
+	return tx.Create(change).Error
+}
+
+// logVariantPriceChange records the price of a variant being written, when
+// price is given.
+func logVariantPriceChange(tx *gorm.DB, variant *model.ProductVariant, price *model.PriceChange) error {
+	if price == nil {
+		return nil
+	}
+	price.ProductID = variant.ProductID
+	price.VariantID = &variant.ID
+	price.Price = variant.Price
+	price.CompareAtPrice = variant.CompareAtPrice
+	return logPriceChange(tx, price)
+}
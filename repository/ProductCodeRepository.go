@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductCodeRepository interface {
+	AddCodes(ctx context.Context, codes []*model.ProductCode, movement *model.StockMovement) (int, error)
+	GetCodes(ctx context.Context, productID string, status string, page, limit int) ([]*model.ProductCode, int64, error)
+	GetCode(ctx context.Context, productID string, id string) (*model.ProductCode, error)
+	AssignCodes(ctx context.Context, tx *gorm.DB, item *model.OrderItem, quantity int) ([]model.ProductCode, error)
+	VoidCode(ctx context.Context, code *model.ProductCode, movement *model.StockMovement) error
+	VoidOrderItemCodes(ctx context.Context, tx *gorm.DB, orderItemID string) (int64, error)
+	ReissueCode(ctx context.Context, code *model.ProductCode, movement *model.StockMovement) (*model.ProductCode, error)
+}
+
+type productCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewProductCodeRepository() ProductCodeRepository {
+	return &productCodeRepository{db: config.GetDB()}
+}
+
+// AddCodes stores the codes of a product, skipping those it already has, and
+// records movement with the number of codes added.
+func (r *productCodeRepository) AddCodes(ctx context.Context, codes []*model.ProductCode, movement *model.StockMovement) (int, error) {
+	added := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(codes, 1000)
+		if result.Error != nil {
+			return result.Error
+		}
+		added = int(result.RowsAffected)
+		if added == 0 {
+			return nil
+		}
+		movement.Quantity = added
+		return applyStockMovement(tx, movement)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+func (r *productCodeRepository) GetCodes(ctx context.Context, productID string, status string, page, limit int) ([]*model.ProductCode, int64, error) {
+	var codes []*model.ProductCode
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.ProductCode{}).Where("product_id = ?", productID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order("created_at DESC, id DESC").
+		Find(&codes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return codes, total, nil
+}
+
+func (r *productCodeRepository) GetCode(ctx context.Context, productID string, id string) (*model.ProductCode, error) {
+	var code model.ProductCode
+	err := r.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).Take(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// AssignCodes hands quantity available codes of the item's product to the
+// item, oldest first. Codes locked by a concurrent assignment are skipped, so
+// ErrInsufficientStock means the pool ran out.
+func (r *productCodeRepository) AssignCodes(ctx context.Context, tx *gorm.DB, item *model.OrderItem, quantity int) ([]model.ProductCode, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	var codes []model.ProductCode
+	err := db.WithContext(ctx).Raw(`
+		UPDATE product_codes SET status = ?, order_id = ?, order_item_id = ?, assigned_at = ?
+		WHERE id IN (
+			SELECT id FROM product_codes
+			WHERE product_id = ? AND status = ?
+			ORDER BY created_at ASC, id ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.ProductCodeAssigned, item.OrderID, item.ID, time.Now().UnixMilli(),
+		item.ProductID, model.ProductCodeAvailable, quantity,
+	).Scan(&codes).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) < quantity {
+		return nil, ErrInsufficientStock
+	}
+	return codes, nil
+}
+
+// VoidCode voids an available code and records movement for the unit taken
+// out of stock.
+func (r *productCodeRepository) VoidCode(ctx context.Context, code *model.ProductCode, movement *model.StockMovement) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := voidCode(tx, code, model.ProductCodeAvailable); err != nil {
+			return err
+		}
+		return applyStockMovement(tx, movement)
+	})
+}
+
+// VoidOrderItemCodes voids the codes assigned to an order item. They do not go
+// back to the pool, a revealed code cannot be sold again.
+func (r *productCodeRepository) VoidOrderItemCodes(ctx context.Context, tx *gorm.DB, orderItemID string) (int64, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	result := db.WithContext(ctx).Model(&model.ProductCode{}).
+		Where("order_item_id = ? AND status = ?", orderItemID, model.ProductCodeAssigned).
+		Updates(map[string]interface{}{
+			"status":    model.ProductCodeVoided,
+			"voided_at": time.Now().UnixMilli(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ReissueCode voids an assigned code and assigns its order item a replacement
+// from the pool, recording movement for the unit taken out of stock. The order
+// version is bumped so its ETag no longer matches copies with the old code.
+func (r *productCodeRepository) ReissueCode(ctx context.Context, code *model.ProductCode, movement *model.StockMovement) (*model.ProductCode, error) {
+	var replacement *model.ProductCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := voidCode(tx, code, model.ProductCodeAssigned); err != nil {
+			return err
+		}
+		// The movement locks the product before the pool is read.
+		if err := applyStockMovement(tx, movement); err != nil {
+			return err
+		}
+
+		item := &model.OrderItem{ID: *code.OrderItemID, OrderID: *code.OrderID, ProductID: code.ProductID}
+		codes, err := r.AssignCodes(ctx, tx, item, 1)
+		if err != nil {
+			return err
+		}
+		replacement = &codes[0]
+		replacement.ReplacesID = &code.ID
+		if err := tx.Model(replacement).Update("replaces_id", code.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Order{}).
+			Where("id = ?", *code.OrderID).
+			Updates(map[string]interface{}{
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now().UnixMilli(),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// voidCode voids code if it still has the given status.
+func voidCode(tx *gorm.DB, code *model.ProductCode, status string) error {
+	now := time.Now().UnixMilli()
+	result := tx.Model(code).
+		Where("status = ?", status).
+		Updates(map[string]interface{}{
+			"status":    model.ProductCodeVoided,
+			"voided_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
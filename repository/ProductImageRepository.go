@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductImageRepository interface {
+	GetProductImages(ctx context.Context, productID string) ([]*model.ProductImage, error)
+	GetImage(ctx context.Context, productID string, id string) (*model.ProductImage, error)
+	CountProductImages(ctx context.Context, productID string) (int64, error)
+	CreateImages(ctx context.Context, images []*model.ProductImage) error
+	DeleteImage(ctx context.Context, productID string, id string) error
+	ReorderImages(ctx context.Context, productID string, ids []string) error
+	FindImagesOfDeletedProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]*model.ProductImage, error)
+	DeleteImagesByIDs(ctx context.Context, ids []string) error
+}
+
+type productImageRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImageRepository() ProductImageRepository {
+	return &productImageRepository{db: config.GetDB()}
+}
+
+func (r *productImageRepository) GetProductImages(ctx context.Context, productID string) ([]*model.ProductImage, error) {
+	var images []*model.ProductImage
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("position ASC, created_at ASC").
+		Find(&images).Error
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *productImageRepository) GetImage(ctx context.Context, productID string, id string) (*model.ProductImage, error) {
+	var image model.ProductImage
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND product_id = ?", id, productID).
+		First(&image).Error
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *productImageRepository) CountProductImages(ctx context.Context, productID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProductImage{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateImages appends the images to the end of their product's gallery.
+// All images must belong to the same product.
+func (r *productImageRepository) CreateImages(ctx context.Context, images []*model.ProductImage) error {
+	if len(images) == 0 {
+		return nil
+	}
+	productID := images[0].ProductID
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		err := tx.Model(&model.ProductImage{}).
+			Where("product_id = ?", productID).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		for i, image := range images {
+			image.Position = last + 1 + i
+		}
+		if err := tx.Create(&images).Error; err != nil {
+			return err
+		}
+		return syncProductImages(tx, productID)
+	})
+}
+
+func (r *productImageRepository) DeleteImage(ctx context.Context, productID string, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND product_id = ?", id, productID).Delete(&model.ProductImage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return syncProductImages(tx, productID)
+	})
+}
+
+// ReorderImages sets the gallery order to ids, which must list every image
+// of the product exactly once.
+func (r *productImageRepository) ReorderImages(ctx context.Context, productID string, ids []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			result := tx.Model(&model.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return syncProductImages(tx, productID)
+	})
+}
+
+// FindImagesOfDeletedProducts returns images whose product was soft deleted
+// before the given time.
+func (r *productImageRepository) FindImagesOfDeletedProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]*model.ProductImage, error) {
+	var images []*model.ProductImage
+	err := r.db.WithContext(ctx).
+		Joins("JOIN products ON products.id = product_images.product_id").
+		Where("products.deleted_at IS NOT NULL AND products.deleted_at < ?", deletedBefore).
+		Order("product_images.created_at ASC").
+		Limit(limit).
+		Find(&images).Error
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *productImageRepository) DeleteImagesByIDs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.ProductImage{}).Error
+}
+
+// syncProductImages mirrors the gallery URLs, in order, into products.image
+// so clients reading the image list keep working.
+func syncProductImages(tx *gorm.DB, productID string) error {
+	urls := tx.Model(&model.ProductImage{}).
+		Select("COALESCE(jsonb_agg(url ORDER BY position, created_at), '[]'::jsonb)").
+		Where("product_id = ?", productID)
+
+	return tx.Model(&model.Product{}).Where("id = ?", productID).
+		Updates(map[string]interface{}{
+			"image":   gorm.Expr("(?)", urls),
+			"version": gorm.Expr("version + 1"),
+		}).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type ProductImportRepository interface {
+	CreateImport(ctx context.Context, productImport *model.ProductImport) error
+	GetImport(ctx context.Context, id string) (*model.ProductImport, error)
+	UpdateImport(ctx context.Context, productImport *model.ProductImport) error
+	FindByStatus(ctx context.Context, statuses []string) ([]*model.ProductImport, error)
+	ClaimImport(ctx context.Context, id string) (bool, error)
+	ResetProcessing(ctx context.Context) error
+}
+
+type productImportRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImportRepository() ProductImportRepository {
+	return &productImportRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *productImportRepository) CreateImport(ctx context.Context, productImport *model.ProductImport) error {
+	return r.db.WithContext(ctx).Create(productImport).Error
+}
+
+func (r *productImportRepository) GetImport(ctx context.Context, id string) (*model.ProductImport, error) {
+	var productImport model.ProductImport
+	if err := r.db.WithContext(ctx).First(&productImport, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &productImport, nil
+}
+
+func (r *productImportRepository) UpdateImport(ctx context.Context, productImport *model.ProductImport) error {
+	return r.db.WithContext(ctx).Save(productImport).Error
+}
+
+func (r *productImportRepository) FindByStatus(ctx context.Context, statuses []string) ([]*model.ProductImport, error) {
+	var imports []*model.ProductImport
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", statuses).
+		Order("created_at ASC").
+		Find(&imports).Error
+	if err != nil {
+		return nil, err
+	}
+	return imports, nil
+}
+
+// ClaimImport moves a pending import to processing, reporting false when
+// another worker got there first.
+func (r *productImportRepository) ClaimImport(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.ProductImport{}).
+		Where("id = ? AND status = ?", id, model.ProductImportStatusPending).
+		Update("status", model.ProductImportStatusProcessing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ResetProcessing requeues imports that were interrupted by a restart.
+// Rows are upserted by SKU, so running an import again is safe.
+func (r *productImportRepository) ResetProcessing(ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.ProductImport{}).
+		Where("status = ?", model.ProductImportStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":         model.ProductImportStatusPending,
+			"processed_rows": 0,
+			"created_count":  0,
+			"updated_count":  0,
+			"failed_count":   0,
+			"row_errors":     "[]",
+		}).Error
+}
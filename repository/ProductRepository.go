@@ -2,41 +2,225 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"nuxatech-nextmedis/config"
 	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/utils"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type productRepository struct {
 	db *gorm.DB
 }
 
+// ProductFilter narrows GetAllProducts. CategoryIDs matches products in any
+// of the given categories, Attributes products with a live variant having all
+// of the given option values.
+type ProductFilter struct {
+	Search       string
+	CategoryIDs  []string
+	MinPrice     *int
+	MaxPrice     *int
+	InStock      bool
+	Attributes   map[string]string
+	CreatedAfter int64
+	Sort         string
+}
+
+// ProductFacets counts the products matching a filter per category, price
+// bucket and variant attribute.
+type ProductFacets struct {
+	Categories   []CategoryFacet
+	PriceBuckets []PriceBucketCount
+	Attributes   []AttributeFacet
+}
+
+type CategoryFacet struct {
+	ID    string
+	Name  string
+	Slug  string
+	Count int64
+}
+
+// PriceBucketCount.Bucket is the index returned by width_bucket, 0 being
+// below the first bound.
+type PriceBucketCount struct {
+	Bucket int
+	Count  int64
+}
+
+type AttributeFacet struct {
+	Name  string
+	Value string
+	Count int64
+}
+
+// productKeysets are the sorts usable with cursor pagination.
+var productKeysets = map[string]keyset{
+	"newest":     {Sort: "newest", Column: "created_at", Desc: true},
+	"price_asc":  {Sort: "price_asc", Column: "price"},
+	"price_desc": {Sort: "price_desc", Column: "price", Desc: true},
+	"name_asc":   {Sort: "name_asc", Column: "name", Text: true},
+	"name_desc":  {Sort: "name_desc", Column: "name", Desc: true, Text: true},
+}
+
+// productSortOrders maps the supported sort keys to ORDER BY clauses.
+var productSortOrders = map[string]string{
+	"price_asc":  "price ASC, created_at DESC",
+	"price_desc": "price DESC, created_at DESC",
+	"newest":     "created_at DESC",
+	"name_asc":   "name ASC",
+	"name_desc":  "name DESC",
+	"best_selling": `(SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.product_id = products.id AND o.status IN ('paid', 'shipped', 'complete')) DESC, created_at DESC`,
+}
+
 // GetAllProducts implements ProductRepository.
-func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit int, search string) ([]*model.Product, int64, error) {
+func (p *productRepository) GetAllProducts(ctx context.Context, page int, limit int, filter ProductFilter) ([]*model.Product, int64, error) {
 	var products []*model.Product
 	var total int64
 
-	query := p.db.Model(&model.Product{})
-
-	if search != "" {
-		searchQuery := "%" + strings.ToLower(search) + "%"
-		query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(sku) LIKE ?",
-			searchQuery, searchQuery, searchQuery)
-	}
+	query := applyProductFilter(p.db.WithContext(ctx).Model(&model.Product{}), filter)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	query = selectSearchColumns(query, filter.Search)
+	if order, ok := productSortOrders[filter.Sort]; ok {
+		query = query.Order(order)
+	} else if filter.Search != "" {
+		query = query.Order("search_rank DESC, created_at DESC")
+	} else {
+		query = query.Order(productSortOrders["newest"])
+	}
+
 	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+	if err := preloadProductDetails(query).Offset(offset).Limit(limit).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 	return products, total, nil
 }
 
+// GetProductsAfter implements ProductRepository.
+// It pages by keyset instead of offset, only for sorts on a plain column. A
+// search is ranked by relevance unless sorted otherwise, which it cannot page.
+func (p *productRepository) GetProductsAfter(ctx context.Context, filter ProductFilter, cursor *utils.Cursor, limit int) ([]*model.Product, *CursorPage, error) {
+	sort := filter.Sort
+	if sort == "" && filter.Search != "" {
+		sort = "relevance"
+	} else if sort == "" {
+		sort = "newest"
+	}
+	k, ok := productKeysets[sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: sort %s does not support cursor pagination", utils.ErrInvalidCursor, sort)
+	}
+
+	query := applyProductFilter(p.db.WithContext(ctx).Model(&model.Product{}), filter)
+	query, err := k.apply(selectSearchColumns(query, filter.Search), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var products []*model.Product
+	if err := preloadProductDetails(query).Find(&products).Error; err != nil {
+		return nil, nil, err
+	}
+
+	products, page := paginate(k, products, cursor, limit, func(product *model.Product) (utils.Cursor, string) {
+		switch k.Column {
+		case "price":
+			return utils.Cursor{Int: int64(product.Price)}, product.ID
+		case "name":
+			return utils.Cursor{Text: product.Name}, product.ID
+		default:
+			return utils.Cursor{Int: product.CreatedAt}, product.ID
+		}
+	})
+	return products, page, nil
+}
+
+// CountProducts implements ProductRepository.
+func (p *productRepository) CountProducts(ctx context.Context, filter ProductFilter) (int64, error) {
+	var total int64
+	err := applyProductFilter(p.db.WithContext(ctx).Model(&model.Product{}), filter).Count(&total).Error
+	return total, err
+}
+
+// GetProductFacets implements ProductRepository.
+// priceBounds are the ascending lower bounds of the price buckets after the first.
+func (p *productRepository) GetProductFacets(ctx context.Context, filter ProductFilter, priceBounds []int) (*ProductFacets, error) {
+	db := p.db.WithContext(ctx)
+	matching := applyProductFilter(db.Model(&model.Product{}).Select("products.id"), filter)
+
+	facets := &ProductFacets{}
+	err := db.Table("product_categories pc").
+		Select("c.id, c.name, c.slug, COUNT(DISTINCT pc.product_id) AS count").
+		Joins("JOIN categories c ON c.id = pc.category_id").
+		Where("pc.product_id IN (?)", matching).
+		Group("c.id, c.name, c.slug").
+		Order("count DESC, c.name ASC").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(priceBounds) > 0 {
+		bounds := make([]string, len(priceBounds))
+		for i, bound := range priceBounds {
+			bounds[i] = strconv.Itoa(bound)
+		}
+		err = applyProductFilter(db.Model(&model.Product{}), filter).
+			Select("width_bucket(COALESCE(price, 0), ?::int[]) AS bucket, COUNT(*) AS count", "{"+strings.Join(bounds, ",")+"}").
+			Group("bucket").
+			Order("bucket ASC").
+			Scan(&facets.PriceBuckets).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = db.Table("product_variants v, jsonb_each_text(v.options) o").
+		Select("o.key AS name, o.value, COUNT(DISTINCT v.product_id) AS count").
+		Where("v.deleted_at IS NULL AND v.product_id IN (?)", matching).
+		Group("o.key, o.value").
+		Order("o.key ASC, o.value ASC").
+		Scan(&facets.Attributes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// SuggestProducts returns names for autocomplete, names starting with the
+// input first, then the closest fuzzy matches.
+func (p *productRepository) SuggestProducts(ctx context.Context, search string, limit int) ([]*model.Product, error) {
+	var products []*model.Product
+	err := p.db.WithContext(ctx).Model(&model.Product{}).
+		Select("id", "name", "slug").
+		Where("(name ILIKE ? OR search_vector @@ to_tsquery('simple', ?) OR ? <% name)",
+			search+"%", prefixTSQuery(search), search).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "name ILIKE ? DESC, word_similarity(?, name) DESC, name ASC",
+			Vars: []interface{}{search + "%", search},
+		}}).
+		Limit(limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (r *productRepository) GetProductForUpdate(ctx context.Context, tx *gorm.DB, id string) (*model.Product, error) {
 	var product model.Product
 	err := tx.WithContext(ctx).
@@ -49,57 +233,261 @@ func (r *productRepository) GetProductForUpdate(ctx context.Context, tx *gorm.DB
 	return &product, nil
 }
 
-func (r *productRepository) UpdateStock(ctx context.Context, tx *gorm.DB, productID string, newStock int) error {
-	return tx.WithContext(ctx).
-		Model(&model.Product{}).
-		Where("id = ?", productID).
-		Update("stock", newStock).
-		Error
+// SlugOrSKUExists implements ProductRepository.
+// Soft-deleted products keep their slug and SKU so they can be restored.
+func (p *productRepository) SlugOrSKUExists(ctx context.Context, slug string, sku string, excludeID string) (bool, error) {
+	var count int64
+	query := p.db.WithContext(ctx).Unscoped().Model(&model.Product{})
+	if sku != "" {
+		query = query.Where("LOWER(slug) = ? OR LOWER(sku) = ?", strings.ToLower(slug), strings.ToLower(sku))
+	} else {
+		query = query.Where("LOWER(slug) = ?", strings.ToLower(slug))
+	}
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
-// CheckSlug implements ProductRepository.
-func (p *productRepository) CheckSlug(ctx context.Context, slug string, sku string) error {
-	var product *model.Product
-	err := p.db.Where("LOWER(slug) = ? OR LOWER(sku) = ?", slug).Find(&product)
+// GetProductBySKU implements ProductRepository.
+// SKUs are matched case-insensitively.
+func (p *productRepository) GetProductBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	var product model.Product
+	err := preloadProductDetails(p.db.WithContext(ctx)).
+		Where("LOWER(sku) = ?", strings.ToLower(sku)).
+		First(&product).Error
 	if err != nil {
-		return err.Error
+		return nil, err
 	}
-	return nil
+	return &product, nil
+}
+
+// EachProductBatch implements ProductRepository.
+// It walks the whole live catalog in primary key order, one batch at a time.
+func (p *productRepository) EachProductBatch(ctx context.Context, batchSize int, fn func(products []*model.Product) error) error {
+	var products []*model.Product
+	return p.db.WithContext(ctx).
+		Preload("Categories").
+		FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(products)
+		}).Error
 }
 
 // CreateProduct implements ProductRepository.
-func (p *productRepository) CreateProduct(ctx context.Context, product *model.Product) error {
-	return p.db.Create(product).Error
+// price, when given, starts the price history, and movement is logged as the
+// source of the starting stock.
+func (p *productRepository) CreateProduct(ctx context.Context, product *model.Product, movement *model.StockMovement, price *model.PriceChange) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		if price != nil {
+			price.ProductID = product.ID
+			price.Price = product.Price
+			price.CompareAtPrice = product.CompareAtPrice
+			if err := logPriceChange(tx, price); err != nil {
+				return err
+			}
+		}
+		if movement == nil {
+			return nil
+		}
+		movement.ProductID = product.ID
+		movement.Quantity = product.Stock
+		return logStockMovement(tx, movement)
+	})
 }
 
 // DeleteProduct implements ProductRepository.
-func (p *productRepository) DeleteProduct(ctx context.Context, id string) error {
-	return p.db.Delete(&model.Product{}, id).Error
+// The soft delete only applies at the given version.
+func (p *productRepository) DeleteProduct(ctx context.Context, id string, version int64) error {
+	result := p.db.WithContext(ctx).Model(&model.Product{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RestoreProduct implements ProductRepository.
+func (p *productRepository) RestoreProduct(ctx context.Context, id string) error {
+	result := p.db.WithContext(ctx).Unscoped().Model(&model.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 // GetProduct implements ProductRepository.
 func (p *productRepository) GetProduct(ctx context.Context, id string) (*model.Product, error) {
 	var product model.Product
-	if err := p.db.Where("id = ?", id).First(&product).Error; err != nil {
+	if err := preloadProductDetails(p.db).Where("id = ?", id).First(&product).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
 // UpdateProduct implements ProductRepository.
-func (p *productRepository) UpdateProduct(ctx context.Context, product *model.Product) error {
-	return p.db.Save(product).Error
+// The write only applies if the stored version still matches product.Version,
+// which is then bumped. gorm.ErrRecordNotFound signals a version conflict.
+// Stock is only changed through movement, which is applied in the same
+// transaction, and reservations are left to the reservation repository.
+// price, when given, records the new price in the price history.
+func (p *productRepository) UpdateProduct(ctx context.Context, product *model.Product, movement *model.StockMovement, price *model.PriceChange) error {
+	expected := product.Version
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		product.Version = expected + 1
+		result := tx.Model(product).
+			Where("version = ?", expected).
+			Select("*").
+			Omit("stock", "reserved", "rating_average", "rating_count", "created_at", "deleted_at", clause.Associations).
+			Updates(product)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if price != nil {
+			price.ProductID = product.ID
+			price.Price = product.Price
+			price.CompareAtPrice = product.CompareAtPrice
+			if err := logPriceChange(tx, price); err != nil {
+				return err
+			}
+		}
+		if movement == nil {
+			return nil
+		}
+		movement.ProductID = product.ID
+		if err := applyStockMovement(tx, movement); err != nil {
+			return err
+		}
+		product.Stock = movement.StockAfter
+		product.Version++
+		return nil
+	})
+	if err != nil {
+		product.Version = expected
+	}
+	return err
+}
+
+const (
+	nameHighlightOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	snippetOptions       = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+// prefixTSQuery turns free text into a tsquery matching every word as a
+// prefix, e.g. "red sho" becomes "red:* & sho:*".
+func prefixTSQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// selectSearchColumns adds the relevance rank and highlights of a search.
+func selectSearchColumns(query *gorm.DB, search string) *gorm.DB {
+	if search == "" {
+		return query
+	}
+	tsQuery := prefixTSQuery(search)
+	return query.Select(`products.*,
+		ts_rank_cd(search_vector, to_tsquery('simple', ?)) + similarity(name, ?) AS search_rank,
+		ts_headline('simple', name, to_tsquery('simple', ?), ?) AS name_highlight,
+		ts_headline('simple', COALESCE(description, ''), to_tsquery('simple', ?), ?) AS snippet`,
+		tsQuery, search,
+		tsQuery, nameHighlightOptions,
+		tsQuery, snippetOptions)
+}
+
+// applyProductFilter adds the WHERE conditions of a ProductFilter.
+func applyProductFilter(query *gorm.DB, filter ProductFilter) *gorm.DB {
+	// Words match as prefixes of the weighted search vector, names also match
+	// with typos through trigrams, and SKUs by prefix.
+	if filter.Search != "" {
+		query = query.Where("(search_vector @@ to_tsquery('simple', ?) OR ? <% name OR sku ILIKE ?)",
+			prefixTSQuery(filter.Search), filter.Search, filter.Search+"%")
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("products.id IN (SELECT product_id FROM product_categories WHERE category_id IN ?)", filter.CategoryIDs)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	// In stock means something can still be ordered: stock left after
+	// reservations, on at least one live variant when sold in variants.
+	if filter.InStock {
+		query = query.Where(`((products.variant_count = 0 AND products.available > 0) OR
+			(products.variant_count > 0 AND EXISTS (SELECT 1 FROM product_variants pv
+				WHERE pv.product_id = products.id AND pv.deleted_at IS NULL AND pv.available > 0)))`)
+	}
+	for name, value := range filter.Attributes {
+		query = query.Where(`EXISTS (SELECT 1 FROM product_variants pv
+			WHERE pv.product_id = products.id AND pv.deleted_at IS NULL AND pv.options ->> ? = ?)`, name, value)
+	}
+	if filter.CreatedAfter > 0 {
+		query = query.Where("created_at > ?", filter.CreatedAfter)
+	}
+	return query
+}
+
+// preloadProductDetails loads categories, option types and live variants.
+func preloadProductDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Categories").
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, created_at ASC")
+		}).
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, created_at ASC")
+		})
 }
 
 type ProductRepository interface {
-	CreateProduct(ctx context.Context, product *model.Product) error
+	CreateProduct(ctx context.Context, product *model.Product, movement *model.StockMovement, price *model.PriceChange) error
 	GetProduct(ctx context.Context, id string) (*model.Product, error)
-	UpdateProduct(ctx context.Context, product *model.Product) error
-	DeleteProduct(ctx context.Context, id string) error
-	GetAllProducts(ctx context.Context, page int, limit int, search string) ([]*model.Product, int64, error)
-	CheckSlug(ctx context.Context, slug string, sku string) error
+	UpdateProduct(ctx context.Context, product *model.Product, movement *model.StockMovement, price *model.PriceChange) error
+	DeleteProduct(ctx context.Context, id string, version int64) error
+	RestoreProduct(ctx context.Context, id string) error
+	GetAllProducts(ctx context.Context, page int, limit int, filter ProductFilter) ([]*model.Product, int64, error)
+	GetProductsAfter(ctx context.Context, filter ProductFilter, cursor *utils.Cursor, limit int) ([]*model.Product, *CursorPage, error)
+	CountProducts(ctx context.Context, filter ProductFilter) (int64, error)
+	GetProductFacets(ctx context.Context, filter ProductFilter, priceBounds []int) (*ProductFacets, error)
+	SuggestProducts(ctx context.Context, search string, limit int) ([]*model.Product, error)
+	SlugOrSKUExists(ctx context.Context, slug string, sku string, excludeID string) (bool, error)
 	GetProductForUpdate(ctx context.Context, tx *gorm.DB, id string) (*model.Product, error)
-	UpdateStock(ctx context.Context, tx *gorm.DB, productID string, newStock int) error
+	GetProductBySKU(ctx context.Context, sku string) (*model.Product, error)
+	EachProductBatch(ctx context.Context, batchSize int, fn func(products []*model.Product) error) error
 }
 
 func NewProductRepository() ProductRepository {
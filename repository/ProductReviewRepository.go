@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewFilter struct {
+	ProductID  string
+	Status     string
+	Rating     int
+	WithImages bool
+	// Sort is newest, oldest, rating_desc, rating_asc or helpful.
+	Sort string
+}
+
+type ProductReviewRepository interface {
+	FindCompletedOrder(ctx context.Context, userID string, productID string) (string, error)
+	CreateReview(ctx context.Context, review *model.ProductReview) error
+	GetReview(ctx context.Context, id string) (*model.ProductReview, error)
+	ReviewExists(ctx context.Context, userID string, productID string) (bool, error)
+	GetReviews(ctx context.Context, filter ReviewFilter, page, limit int) ([]*model.ProductReview, int64, error)
+	ModerateReview(ctx context.Context, review *model.ProductReview) error
+	AddVote(ctx context.Context, vote *model.ReviewVote) error
+	RemoveVote(ctx context.Context, reviewID string, userID string) error
+}
+
+type productReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewProductReviewRepository() ProductReviewRepository {
+	return &productReviewRepository{db: config.GetDB()}
+}
+
+var reviewSorts = map[string]string{
+	"newest":      "created_at DESC, id DESC",
+	"oldest":      "created_at ASC, id ASC",
+	"rating_desc": "rating DESC, created_at DESC",
+	"rating_asc":  "rating ASC, created_at DESC",
+	"helpful":     "helpful_count DESC, created_at DESC",
+}
+
+// FindCompletedOrder returns the latest paid and complete order of the user
+// that contains the product, gorm.ErrRecordNotFound when there is none. Only
+// admins mark orders paid or complete, so customers cannot make themselves
+// verified buyers.
+func (r *productReviewRepository) FindCompletedOrder(ctx context.Context, userID string, productID string) (string, error) {
+	var order model.Order
+	err := r.db.WithContext(ctx).
+		Select("orders.id").
+		Joins("JOIN order_items ON order_items.order_id = orders.id").
+		Where("orders.user_id = ? AND orders.status = ? AND orders.paid_at IS NOT NULL AND order_items.product_id = ?", userID, model.OrderStatusComplete, productID).
+		Order("orders.created_at DESC").
+		Take(&order).Error
+	if err != nil {
+		return "", err
+	}
+	return order.ID, nil
+}
+
+func (r *productReviewRepository) CreateReview(ctx context.Context, review *model.ProductReview) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(review).Error
+}
+
+func (r *productReviewRepository) GetReview(ctx context.Context, id string) (*model.ProductReview, error) {
+	var review model.ProductReview
+	err := r.db.WithContext(ctx).Preload("User", unscoped).Where("id = ?", id).Take(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *productReviewRepository) ReviewExists(ctx context.Context, userID string, productID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *productReviewRepository) GetReviews(ctx context.Context, filter ReviewFilter, page, limit int) ([]*model.ProductReview, int64, error) {
+	var reviews []*model.ProductReview
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.ProductReview{})
+	if filter.ProductID != "" {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Rating > 0 {
+		query = query.Where("rating = ?", filter.Rating)
+	}
+	if filter.WithImages {
+		query = query.Where("jsonb_array_length(images) > 0")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := reviewSorts[filter.Sort]
+	if !ok {
+		order = reviewSorts["newest"]
+	}
+	offset := (page - 1) * limit
+	err := query.Preload("User", unscoped).
+		Offset(offset).
+		Limit(limit).
+		Order(order).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// ModerateReview saves the status of the review and refreshes the rating of
+// its product.
+func (r *productReviewRepository) ModerateReview(ctx context.Context, review *model.ProductReview) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(review).Updates(map[string]interface{}{
+			"status":       review.Status,
+			"moderated_by": review.ModeratedBy,
+			"moderated_at": review.ModeratedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return refreshProductRating(tx, review.ProductID)
+	})
+}
+
+// AddVote records a helpful vote, voting twice is a no-op.
+func (r *productReviewRepository) AddVote(ctx context.Context, vote *model.ReviewVote) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ?", vote.ReviewID).
+			Update("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+}
+
+func (r *productReviewRepository) RemoveVote(ctx context.Context, reviewID string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&model.ReviewVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ?", reviewID).
+			Update("helpful_count", gorm.Expr("GREATEST(helpful_count - 1, 0)")).Error
+	})
+}
+
+// refreshProductRating recomputes the cached rating of a product from its
+// approved reviews.
+func refreshProductRating(tx *gorm.DB, productID string) error {
+	approved := "FROM product_reviews WHERE product_id = ? AND status = ?"
+	return tx.Unscoped().Model(&model.Product{}).
+		Where("id = ?", productID).
+		Updates(map[string]interface{}{
+			"rating_count":   gorm.Expr("(SELECT COUNT(*) "+approved+")", productID, model.ReviewStatusApproved),
+			"rating_average": gorm.Expr("(SELECT COALESCE(ROUND(AVG(rating), 2), 0) "+approved+")", productID, model.ReviewStatusApproved),
+			"version":        gorm.Expr("version + 1"),
+		}).Error
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ProductVariantRepository interface {
+	GetOptions(ctx context.Context, productID string) ([]*model.ProductOption, error)
+	SetOptions(ctx context.Context, productID string, options []*model.ProductOption) error
+	GetVariants(ctx context.Context, productID string) ([]*model.ProductVariant, error)
+	GetVariant(ctx context.Context, productID string, id string) (*model.ProductVariant, error)
+	CreateVariant(ctx context.Context, variant *model.ProductVariant, movement *model.StockMovement, price *model.PriceChange) error
+	UpdateVariant(ctx context.Context, variant *model.ProductVariant, movement *model.StockMovement, price *model.PriceChange) error
+	DeleteVariant(ctx context.Context, productID string, id string) error
+	SKUExists(ctx context.Context, sku string, excludeID string) (bool, error)
+	GetVariantForUpdate(ctx context.Context, tx *gorm.DB, id string) (*model.ProductVariant, error)
+}
+
+type productVariantRepository struct {
+	db *gorm.DB
+}
+
+func NewProductVariantRepository() ProductVariantRepository {
+	return &productVariantRepository{db: config.GetDB()}
+}
+
+func (r *productVariantRepository) GetOptions(ctx context.Context, productID string) ([]*model.ProductOption, error) {
+	var options []*model.ProductOption
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("position ASC").
+		Find(&options).Error
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// SetOptions replaces all option types of a product.
+func (r *productVariantRepository) SetOptions(ctx context.Context, productID string, options []*model.ProductOption) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) > 0 {
+			if err := tx.Create(&options).Error; err != nil {
+				return err
+			}
+		}
+		return syncProductVariants(tx, productID)
+	})
+}
+
+func (r *productVariantRepository) GetVariants(ctx context.Context, productID string) ([]*model.ProductVariant, error) {
+	var variants []*model.ProductVariant
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("position ASC, created_at ASC").
+		Find(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *productVariantRepository) GetVariant(ctx context.Context, productID string, id string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND product_id = ?", id, productID).
+		First(&variant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// CreateVariant creates the variant. price, when given, starts its price
+// history, and movement is logged as the source of its starting stock.
+func (r *productVariantRepository) CreateVariant(ctx context.Context, variant *model.ProductVariant, movement *model.StockMovement, price *model.PriceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		if err := logVariantPriceChange(tx, variant, price); err != nil {
+			return err
+		}
+		if movement != nil {
+			movement.ProductID = variant.ProductID
+			movement.VariantID = &variant.ID
+			movement.Quantity = variant.Stock
+			if err := logStockMovement(tx, movement); err != nil {
+				return err
+			}
+		}
+		return syncProductVariants(tx, variant.ProductID)
+	})
+}
+
+// UpdateVariant saves the variant. Stock is only changed through movement,
+// which is applied in the same transaction, and price, when given, records the
+// new price in the price history.
+func (r *productVariantRepository) UpdateVariant(ctx context.Context, variant *model.ProductVariant, movement *model.StockMovement, price *model.PriceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("stock", "reserved", "created_at").Save(variant).Error; err != nil {
+			return err
+		}
+		if err := logVariantPriceChange(tx, variant, price); err != nil {
+			return err
+		}
+		if movement != nil {
+			movement.ProductID = variant.ProductID
+			movement.VariantID = &variant.ID
+			if err := applyStockMovement(tx, movement); err != nil {
+				return err
+			}
+			variant.Stock = movement.StockAfter
+		}
+		return syncProductVariants(tx, variant.ProductID)
+	})
+}
+
+// DeleteVariant soft-deletes the variant so past orders keep referencing it.
+func (r *productVariantRepository) DeleteVariant(ctx context.Context, productID string, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND product_id = ?", id, productID).Delete(&model.ProductVariant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return syncProductVariants(tx, productID)
+	})
+}
+
+// SKUExists also checks deleted variants, their SKUs stay on past orders.
+func (r *productVariantRepository) SKUExists(ctx context.Context, sku string, excludeID string) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Unscoped().Model(&model.ProductVariant{}).
+		Where("LOWER(sku) = ?", strings.ToLower(sku))
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *productVariantRepository) GetVariantForUpdate(ctx context.Context, tx *gorm.DB, id string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := tx.WithContext(ctx).
+		Set("gorm:for_update", true).
+		First(&variant, "id = ?", id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// syncProductVariants recomputes the product stock, reserved stock and variant
+// count from its live variants and bumps the product version, so product ETags
+// change along with the variants.
+func syncProductVariants(tx *gorm.DB, productID string) error {
+	live := tx.Model(&model.ProductVariant{}).Where("product_id = ?", productID)
+
+	var summary struct {
+		Count    int
+		Stock    int
+		Reserved int
+	}
+	err := live.Select("COUNT(*) AS count, COALESCE(SUM(stock), 0) AS stock, COALESCE(SUM(reserved), 0) AS reserved").
+		Scan(&summary).Error
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"variant_count": summary.Count,
+		"version":       gorm.Expr("version + 1"),
+	}
+	if summary.Count > 0 {
+		updates["stock"] = summary.Stock
+		updates["reserved"] = summary.Reserved
+	}
+
+	return tx.Model(&model.Product{}).Where("id = ?", productID).Updates(updates).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	GetProductSales(ctx context.Context, from int64, to int64) ([]ProductSales, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository() ReportRepository {
+	return &reportRepository{db: config.GetDB()}
+}
+
+// ProductSales sums the items of a product sold in paid orders. Revenue uses
+// the price charged on each order, RegularRevenue the compare-at price that
+// was in effect when the order was placed, if a sale was running.
+type ProductSales struct {
+	ProductID      string
+	Name           string
+	SKU            string
+	CurrentPrice   int
+	Units          int64
+	Revenue        int64
+	RegularRevenue int64
+}
+
+// GetProductSales reports the sales of orders placed between from and to,
+// both inclusive, best selling by revenue first. Deleted products are kept.
+func (r *reportRepository) GetProductSales(ctx context.Context, from int64, to int64) ([]ProductSales, error) {
+	var sales []ProductSales
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT oi.product_id, p.name, p.sku, p.price AS current_price,
+			SUM(oi.quantity) AS units,
+			SUM(oi.quantity * oi.price) AS revenue,
+			SUM(oi.quantity * GREATEST(oi.price, COALESCE((
+				SELECT pc.compare_at_price FROM price_changes pc
+				WHERE pc.product_id = oi.product_id
+					AND pc.variant_id IS NOT DISTINCT FROM oi.variant_id
+					AND pc.created_at <= o.created_at
+				ORDER BY pc.created_at DESC
+				LIMIT 1
+			), oi.price))) AS regular_revenue
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		WHERE o.deleted_at IS NULL
+			AND o.status IN ?
+			AND o.created_at BETWEEN ? AND ?
+		GROUP BY oi.product_id, p.name, p.sku, p.price
+		ORDER BY revenue DESC, p.name ASC`,
+		[]model.OrderStatus{model.OrderStatusPaid, model.OrderStatusShipped, model.OrderStatusComplete},
+		from, to).
+		Scan(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a movement would take stock below zero.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrNoDefaultWarehouse is returned when a movement names no warehouse and
+// none is marked as the default.
+var ErrNoDefaultWarehouse = errors.New("no default warehouse")
+
+type StockMovementFilter struct {
+	ProductID   string
+	VariantID   string
+	WarehouseID string
+	Reason      string
+}
+
+type StockMovementRepository interface {
+	RecordMovement(ctx context.Context, tx *gorm.DB, movement *model.StockMovement) error
+	TransferStock(ctx context.Context, out *model.StockMovement, in *model.StockMovement) error
+	GetMovements(ctx context.Context, filter StockMovementFilter, page, limit int) ([]*model.StockMovement, int64, error)
+}
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository() StockMovementRepository {
+	return &stockMovementRepository{db: config.GetDB()}
+}
+
+// RecordMovement applies the movement to the cached stock and logs it, within
+// the caller's transaction or a new one.
+func (r *stockMovementRepository) RecordMovement(ctx context.Context, tx *gorm.DB, movement *model.StockMovement) error {
+	if tx == nil {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return applyStockMovement(tx, movement)
+		})
+	}
+	return applyStockMovement(tx.WithContext(ctx), movement)
+}
+
+// TransferStock moves stock between the warehouses of out and in, which carry
+// opposite quantities. The stock of the product or variant is unchanged and
+// reserved stock cannot be moved.
+func (r *stockMovementRepository) TransferStock(ctx context.Context, out *model.StockMovement, in *model.StockMovement) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the product or variant, like every other stock change does.
+		var stock struct{ Stock int }
+		query := tx.Model(&model.Product{}).Where("id = ?", out.ProductID)
+		if out.VariantID != nil {
+			query = tx.Model(&model.ProductVariant{}).
+				Where("id = ? AND product_id = ?", *out.VariantID, out.ProductID)
+		}
+		result := query.Clauses(clause.Locking{Strength: "UPDATE"}).Select("stock").Scan(&stock)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		now := time.Now().UnixMilli()
+		for _, movement := range []*model.StockMovement{out, in} {
+			movement.CreatedAt = now
+			movement.StockAfter = stock.Stock
+			if err := applyWarehouseMovement(tx, movement, true); err != nil {
+				return err
+			}
+			if err := tx.Create(movement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *stockMovementRepository) GetMovements(ctx context.Context, filter StockMovementFilter, page, limit int) ([]*model.StockMovement, int64, error) {
+	var movements []*model.StockMovement
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.StockMovement{}).
+		Where("product_id = ?", filter.ProductID)
+	if filter.VariantID != "" {
+		query = query.Where("variant_id = ?", filter.VariantID)
+	}
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order("created_at DESC, id DESC").
+		Find(&movements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// applyStockMovement adds movement.Quantity to the stock of the variant, or of
+// the product when no variant is set, and to its warehouse, then logs the
+// movement. Deleted products and variants are included so canceled orders can
+// still restock them.
+func applyStockMovement(tx *gorm.DB, movement *model.StockMovement) error {
+	if movement.CreatedAt == 0 {
+		movement.CreatedAt = time.Now().UnixMilli()
+	}
+
+	// RETURNING fills the model with the new stock.
+	var variant model.ProductVariant
+	var product model.Product
+	var result *gorm.DB
+	if movement.VariantID != nil {
+		result = tx.Unscoped().Model(&variant).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock"}}}).
+			Where("id = ? AND product_id = ? AND stock + ? >= 0", *movement.VariantID, movement.ProductID, movement.Quantity).
+			Updates(map[string]interface{}{
+				"stock":      gorm.Expr("stock + ?", movement.Quantity),
+				"updated_at": movement.CreatedAt,
+			})
+	} else {
+		result = tx.Unscoped().Model(&product).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock"}}}).
+			Where("id = ? AND stock + ? >= 0", movement.ProductID, movement.Quantity).
+			Updates(map[string]interface{}{
+				"stock":   gorm.Expr("stock + ?", movement.Quantity),
+				"version": gorm.Expr("version + 1"),
+			})
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return stockTargetError(tx, movement)
+	}
+
+	movement.StockAfter = product.Stock
+	if movement.VariantID != nil {
+		movement.StockAfter = variant.Stock
+	}
+	if err := applyWarehouseMovement(tx, movement, false); err != nil {
+		return err
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+
+	if movement.VariantID != nil {
+		return syncProductVariants(tx, movement.ProductID)
+	}
+	return nil
+}
+
+// logStockMovement records the movement that set the stock of a product or
+// variant being created, whose stock column is already written.
+func logStockMovement(tx *gorm.DB, movement *model.StockMovement) error {
+	if movement.CreatedAt == 0 {
+		movement.CreatedAt = time.Now().UnixMilli()
+	}
+	movement.StockAfter = movement.Quantity
+	if err := applyWarehouseMovement(tx, movement, false); err != nil {
+		return err
+	}
+	return tx.Create(movement).Error
+}
+
+// applyWarehouseMovement adds movement.Quantity to the stock held at its
+// warehouse, the default one when none is set. With keepReserved the stock
+// reserved there cannot be taken.
+func applyWarehouseMovement(tx *gorm.DB, movement *model.StockMovement, keepReserved bool) error {
+	if movement.WarehouseID == nil {
+		warehouseID, err := defaultWarehouseID(tx)
+		if err != nil {
+			return err
+		}
+		movement.WarehouseID = &warehouseID
+	}
+
+	guard := "quantity + ? >= 0"
+	if keepReserved {
+		guard = "quantity - reserved + ? >= 0"
+	}
+	result := warehouseStockScope(tx, *movement.WarehouseID, movement.ProductID, movement.VariantID).
+		Where(guard, movement.Quantity).
+		Updates(map[string]interface{}{
+			"quantity":   gorm.Expr("quantity + ?", movement.Quantity),
+			"updated_at": movement.CreatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	err := warehouseStockScope(tx, *movement.WarehouseID, movement.ProductID, movement.VariantID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 || movement.Quantity < 0 {
+		return ErrInsufficientStock
+	}
+
+	// First stock of the product at this warehouse. The product or variant
+	// row is locked by now, so no concurrent insert can race this one.
+	return tx.Create(&model.WarehouseStock{
+		WarehouseID: *movement.WarehouseID,
+		ProductID:   movement.ProductID,
+		VariantID:   movement.VariantID,
+		Quantity:    movement.Quantity,
+		UpdatedAt:   movement.CreatedAt,
+	}).Error
+}
+
+// warehouseStockScope selects the stock of a product or variant, at one
+// warehouse or, when warehouseID is empty, at all of them.
+func warehouseStockScope(tx *gorm.DB, warehouseID string, productID string, variantID *string) *gorm.DB {
+	query := tx.Model(&model.WarehouseStock{}).Where("warehouse_stocks.product_id = ?", productID)
+	if warehouseID != "" {
+		query = query.Where("warehouse_stocks.warehouse_id = ?", warehouseID)
+	}
+	if variantID != nil {
+		return query.Where("warehouse_stocks.variant_id = ?", *variantID)
+	}
+	return query.Where("warehouse_stocks.variant_id IS NULL")
+}
+
+func defaultWarehouseID(tx *gorm.DB) (string, error) {
+	var warehouse model.Warehouse
+	err := tx.Where("is_default = ?", true).Select("id").Take(&warehouse).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrNoDefaultWarehouse
+	}
+	if err != nil {
+		return "", err
+	}
+	return warehouse.ID, nil
+}
+
+// stockTargetError explains why a movement matched no row.
+func stockTargetError(tx *gorm.DB, movement *model.StockMovement) error {
+	var count int64
+	query := tx.Unscoped().Model(&model.Product{}).Where("id = ?", movement.ProductID)
+	if movement.VariantID != nil {
+		query = tx.Unscoped().Model(&model.ProductVariant{}).
+			Where("id = ? AND product_id = ?", *movement.VariantID, movement.ProductID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrInsufficientStock
+}
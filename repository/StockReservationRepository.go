@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StockReservationRepository interface {
+	CreateReservations(ctx context.Context, tx *gorm.DB, reservations []*model.StockReservation) error
+	GetOrderReservations(ctx context.Context, tx *gorm.DB, orderID string) ([]*model.StockReservation, error)
+	CloseReservation(ctx context.Context, tx *gorm.DB, reservation *model.StockReservation, status string) error
+}
+
+type stockReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository() StockReservationRepository {
+	return &stockReservationRepository{db: config.GetDB()}
+}
+
+// CreateReservations stores the reservations and adds them to the reserved
+// stock of their products and variants.
+func (r *stockReservationRepository) CreateReservations(ctx context.Context, tx *gorm.DB, reservations []*model.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reservations).Error; err != nil {
+			return err
+		}
+		for _, reservation := range reservations {
+			if err := adjustReserved(tx, reservation, reservation.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *stockReservationRepository) GetOrderReservations(ctx context.Context, tx *gorm.DB, orderID string) ([]*model.StockReservation, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	var reservations []*model.StockReservation
+	err := db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// CloseReservation marks an active reservation committed or released and
+// removes it from the reserved stock.
+func (r *stockReservationRepository) CloseReservation(ctx context.Context, tx *gorm.DB, reservation *model.StockReservation, status string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		result := tx.Model(reservation).
+			Where("status = ?", model.StockReservationActive).
+			Updates(map[string]interface{}{
+				"status":    status,
+				"closed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return adjustReserved(tx, reservation, -reservation.Quantity)
+	})
+}
+
+// adjustReserved changes the reserved stock of the reservation's variant, or
+// of its product when it has no variant, and of its warehouse. The product
+// version is bumped, as the available stock is part of its ETag.
+func adjustReserved(tx *gorm.DB, reservation *model.StockReservation, delta int) error {
+	var err error
+	if reservation.VariantID != nil {
+		err = tx.Unscoped().Model(&model.ProductVariant{}).
+			Where("id = ?", *reservation.VariantID).
+			Update("reserved", gorm.Expr("GREATEST(reserved + ?, 0)", delta)).Error
+		if err == nil {
+			err = syncProductVariants(tx, reservation.ProductID)
+		}
+	} else {
+		err = tx.Unscoped().Model(&model.Product{}).
+			Where("id = ?", reservation.ProductID).
+			Updates(map[string]interface{}{
+				"reserved": gorm.Expr("GREATEST(reserved + ?, 0)", delta),
+				"version":  gorm.Expr("version + 1"),
+			}).Error
+	}
+	if err != nil || reservation.WarehouseID == nil {
+		return err
+	}
+
+	// Updated after the product or variant, which serializes stock changes.
+	return warehouseStockScope(tx, *reservation.WarehouseID, reservation.ProductID, reservation.VariantID).
+		Update("reserved", gorm.Expr("GREATEST(reserved + ?, 0)", delta)).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type StockSubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, subscription *model.StockSubscription) error
+	GetOpenSubscription(ctx context.Context, userID string, productID string, variantID *string) (*model.StockSubscription, error)
+	GetUserSubscriptions(ctx context.Context, userID string) ([]*model.StockSubscription, error)
+	DeleteSubscription(ctx context.Context, userID string, id string) error
+	FindRestocked(ctx context.Context, limit int) ([]*model.StockSubscription, error)
+	MarkNotified(ctx context.Context, id string, notifiedAt int64) (bool, error)
+}
+
+type stockSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewStockSubscriptionRepository() StockSubscriptionRepository {
+	return &stockSubscriptionRepository{db: config.GetDB()}
+}
+
+func (r *stockSubscriptionRepository) CreateSubscription(ctx context.Context, subscription *model.StockSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+// GetOpenSubscription returns the subscription of the user to the product or
+// variant that has not been notified yet.
+func (r *stockSubscriptionRepository) GetOpenSubscription(ctx context.Context, userID string, productID string, variantID *string) (*model.StockSubscription, error) {
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND product_id = ? AND notified_at IS NULL", userID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	var subscription model.StockSubscription
+	if err := query.First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetUserSubscriptions returns the open subscriptions of the user, newest
+// first.
+func (r *stockSubscriptionRepository) GetUserSubscriptions(ctx context.Context, userID string) ([]*model.StockSubscription, error) {
+	var subscriptions []*model.StockSubscription
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Where("user_id = ? AND notified_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *stockSubscriptionRepository) DeleteSubscription(ctx context.Context, userID string, id string) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.StockSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindRestocked returns open subscriptions whose product or variant has stock
+// again. Deleted products and variants are skipped.
+func (r *stockSubscriptionRepository) FindRestocked(ctx context.Context, limit int) ([]*model.StockSubscription, error) {
+	var subscriptions []*model.StockSubscription
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Joins("JOIN products p ON p.id = stock_subscriptions.product_id AND p.deleted_at IS NULL").
+		Joins("LEFT JOIN product_variants v ON v.id = stock_subscriptions.variant_id AND v.deleted_at IS NULL").
+		Where("stock_subscriptions.notified_at IS NULL").
+		Where(`((stock_subscriptions.variant_id IS NULL AND p.stock > 0) OR
+			(stock_subscriptions.variant_id IS NOT NULL AND v.stock > 0))`).
+		Order("stock_subscriptions.created_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// MarkNotified closes the subscription. It reports false when another worker
+// already did.
+func (r *stockSubscriptionRepository) MarkNotified(ctx context.Context, id string, notifiedAt int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.StockSubscription{}).
+		Where("id = ? AND notified_at IS NULL", id).
+		Update("notified_at", notifiedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"nuxatech-nextmedis/config"
 	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/utils"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +12,10 @@ import (
 type TransactionRepository interface {
 	Create(ctx context.Context, tx *gorm.DB, transaction *model.Transaction) error
 	GetByID(ctx context.Context, id string) (*model.Transaction, error)
+	FindByAccountIDs(ctx context.Context, accountIDs []string) ([]*model.Transaction, error)
+	GetAccountTransactions(ctx context.Context, accountID string, page, limit int) ([]*model.Transaction, error)
+	GetAccountTransactionsAfter(ctx context.Context, accountID string, cursor *utils.Cursor, limit int) ([]*model.Transaction, *CursorPage, error)
+	CountAccountTransactions(ctx context.Context, accountID string) (int64, error)
 }
 
 type transactionRepository struct {
@@ -33,6 +38,60 @@ func (r *transactionRepository) GetByID(ctx context.Context, id string) (*model.
 	return &transaction, nil
 }
 
+func (r *transactionRepository) FindByAccountIDs(ctx context.Context, accountIDs []string) ([]*model.Transaction, error) {
+	var transactions []*model.Transaction
+	if len(accountIDs) == 0 {
+		return transactions, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("account_id IN ?", accountIDs).
+		Order("created_at DESC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+var transactionKeyset = keyset{Sort: "newest", Column: "created_at", Desc: true}
+
+func (r *transactionRepository) GetAccountTransactions(ctx context.Context, accountID string, page, limit int) ([]*model.Transaction, error) {
+	var transactions []*model.Transaction
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepository) GetAccountTransactionsAfter(ctx context.Context, accountID string, cursor *utils.Cursor, limit int) ([]*model.Transaction, *CursorPage, error) {
+	query, err := transactionKeyset.apply(r.db.WithContext(ctx).Where("account_id = ?", accountID), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transactions []*model.Transaction
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	transactions, page := paginate(transactionKeyset, transactions, cursor, limit, func(transaction *model.Transaction) (utils.Cursor, string) {
+		return utils.Cursor{Int: transaction.CreatedAt}, transaction.ID
+	})
+	return transactions, page, nil
+}
+
+func (r *transactionRepository) CountAccountTransactions(ctx context.Context, accountID string) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).Where("account_id = ?", accountID).Count(&total).Error
+	return total, err
+}
+
 func NewTransactionRepository() TransactionRepository {
 	return &transactionRepository{db: config.GetDB()}
 }
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	FindByProviderSubject(ctx context.Context, provider string, subject string) (*model.UserIdentity, error)
+	FindByUserID(ctx context.Context, userID string) ([]*model.UserIdentity, error)
+	TouchIdentity(ctx context.Context, id string, loginAt int64) error
+	DeleteUserIdentities(ctx context.Context, tx *gorm.DB, userID string) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository() UserIdentityRepository {
+	return &userIdentityRepository{
+		db: config.GetDB(),
+	}
+}
+
+func (r *userIdentityRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *userIdentityRepository) TouchIdentity(ctx context.Context, id string, loginAt int64) error {
+	return r.db.WithContext(ctx).Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Update("last_login_at", loginAt).Error
+}
+
+func (r *userIdentityRepository) DeleteUserIdentities(ctx context.Context, tx *gorm.DB, userID string) error {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserIdentity{}).Error
+}
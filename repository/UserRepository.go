@@ -14,7 +14,27 @@ type userRepository struct {
 	db *gorm.DB
 }
 
+type UserFilter struct {
+	Query       string
+	Username    string
+	Email       string
+	Role        string
+	Status      string
+	CreatedFrom int64
+	CreatedTo   int64
+	SortBy      string
+	SortOrder   string
+}
+
+// userSortColumns whitelists the columns SearchUsers may order by.
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"username":   "username",
+	"email":      "email",
+}
+
 type UserRepository interface {
+	BeginTx(ctx context.Context) *gorm.DB
 	CreateUser(ctx context.Context, user *model.User) error
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
@@ -23,6 +43,13 @@ type UserRepository interface {
 	UpdateUser(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, id string) error
 	CheckUserExists(ctx context.Context, email string, username string) bool
+	CheckUserExistsExcept(ctx context.Context, email string, username string, excludeID string) bool
+	UpdatePassword(ctx context.Context, id string, hashedPassword string) error
+	AnonymizeUser(ctx context.Context, tx *gorm.DB, id string, username string, email string, hashedPassword string) error
+	SearchUsers(ctx context.Context, filter UserFilter, page, limit int) ([]*model.User, int64, error)
+	UpdateStatus(ctx context.Context, id string, status string, suspendedAt *int64) error
+	RevokeSessions(ctx context.Context, id string, revokedAt int64) error
+	FindActiveByRole(ctx context.Context, role string) ([]*model.User, error)
 }
 
 func NewUserRepository() UserRepository {
@@ -30,13 +57,17 @@ func NewUserRepository() UserRepository {
 		db: config.GetDB(),
 	}
 }
+func (u *userRepository) BeginTx(ctx context.Context) *gorm.DB {
+	return u.db.WithContext(ctx).Begin()
+}
+
 func (u *userRepository) CreateUser(ctx context.Context, user *model.User) error {
 	return u.db.WithContext(ctx).Create(user).Error
 }
 
 // DeleteUser implements UserRepository.
 func (u *userRepository) DeleteUser(ctx context.Context, id string) error {
-	return u.db.WithContext(ctx).Delete(&model.User{}, id).Error
+	return u.db.WithContext(ctx).Delete(&model.User{}, "id = ?", id).Error
 }
 
 // FindByEmail implements UserRepository.
@@ -89,3 +120,129 @@ func (u *userRepository) CheckUserExists(ctx context.Context, email string, user
 	}
 	return false
 }
+
+func (u *userRepository) CheckUserExistsExcept(ctx context.Context, email string, username string, excludeID string) bool {
+	var user model.User
+	err := u.db.WithContext(ctx).
+		Where("(email = ? OR username = ?) AND id <> ?", email, username, excludeID).
+		First(&user).Error
+	return err == nil
+}
+
+func (u *userRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	return u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Update("password", hashedPassword).Error
+}
+
+// AnonymizeUser replaces the user's PII and soft-deletes the row in one go.
+func (u *userRepository) AnonymizeUser(ctx context.Context, tx *gorm.DB, id string, username string, email string, hashedPassword string) error {
+	db := tx
+	if tx == nil {
+		db = u.db
+	}
+
+	result := db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"username":   username,
+			"email":      email,
+			"password":   hashedPassword,
+			"deleted_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SearchUsers implements UserRepository.
+func (u *userRepository) SearchUsers(ctx context.Context, filter UserFilter, page, limit int) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	query := u.db.WithContext(ctx).Model(&model.User{})
+
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ?", like, like)
+	}
+	if filter.Username != "" {
+		query = query.Where("username ILIKE ?", "%"+filter.Username+"%")
+	}
+	if filter.Email != "" {
+		query = query.Where("email ILIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CreatedFrom > 0 {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if filter.CreatedTo > 0 {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sortBy, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		sortBy = "created_at"
+	}
+	sortOrder := "DESC"
+	if filter.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order(sortBy + " " + sortOrder).
+		Order("id ASC").
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// UpdateStatus implements UserRepository.
+func (u *userRepository) UpdateStatus(ctx context.Context, id string, status string, suspendedAt *int64) error {
+	return u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"suspended_at": suspendedAt,
+		}).Error
+}
+
+// RevokeSessions implements UserRepository.
+func (u *userRepository) RevokeSessions(ctx context.Context, id string, revokedAt int64) error {
+	return u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Update("sessions_revoked_at", revokedAt).Error
+}
+
+// FindActiveByRole returns the active users with the given role.
+func (u *userRepository) FindActiveByRole(ctx context.Context, role string) ([]*model.User, error) {
+	var users []*model.User
+	err := u.db.WithContext(ctx).
+		Where("role = ? AND status = ?", role, model.UserStatusActive).
+		Order("created_at ASC").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository interface {
+	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	GetWarehouse(ctx context.Context, id string) (*model.Warehouse, error)
+	GetWarehouses(ctx context.Context) ([]*model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	CodeExists(ctx context.Context, code string, excludeID string) (bool, error)
+	GetStockLevels(ctx context.Context, tx *gorm.DB, productID string, variantID *string) ([]*model.WarehouseStock, error)
+	GetProductStockLevels(ctx context.Context, productID string) ([]*model.WarehouseStock, error)
+}
+
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository() WarehouseRepository {
+	return &warehouseRepository{db: config.GetDB()}
+}
+
+// CreateWarehouse stores the warehouse. A new default replaces the old one.
+func (r *warehouseRepository) CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if warehouse.IsDefault {
+			if err := clearDefaultWarehouse(tx, ""); err != nil {
+				return err
+			}
+		}
+		return tx.Create(warehouse).Error
+	})
+}
+
+func (r *warehouseRepository) GetWarehouse(ctx context.Context, id string) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+	if err := r.db.WithContext(ctx).First(&warehouse, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) GetWarehouses(ctx context.Context) ([]*model.Warehouse, error) {
+	var warehouses []*model.Warehouse
+	err := r.db.WithContext(ctx).
+		Order("priority ASC, code ASC").
+		Find(&warehouses).Error
+	if err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+// UpdateWarehouse saves the warehouse. A new default replaces the old one.
+func (r *warehouseRepository) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if warehouse.IsDefault {
+			if err := clearDefaultWarehouse(tx, warehouse.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("created_at").Save(warehouse).Error
+	})
+}
+
+func (r *warehouseRepository) CodeExists(ctx context.Context, code string, excludeID string) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&model.Warehouse{}).Where("code = ?", code)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetStockLevels returns the stock of a product, or of one of its variants,
+// at every active warehouse that holds some.
+func (r *warehouseRepository) GetStockLevels(ctx context.Context, tx *gorm.DB, productID string, variantID *string) ([]*model.WarehouseStock, error) {
+	db := tx
+	if tx == nil {
+		db = r.db
+	}
+
+	var levels []*model.WarehouseStock
+	err := warehouseStockScope(db.WithContext(ctx), "", productID, variantID).
+		Joins("Warehouse").
+		Where(`"Warehouse".active = ?`, true).
+		Find(&levels).Error
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// GetProductStockLevels returns the stock of a product and its variants at
+// every warehouse.
+func (r *warehouseRepository) GetProductStockLevels(ctx context.Context, productID string) ([]*model.WarehouseStock, error) {
+	var levels []*model.WarehouseStock
+	err := r.db.WithContext(ctx).
+		Joins("Warehouse").
+		Where("warehouse_stocks.product_id = ?", productID).
+		Order(`"Warehouse".priority ASC, "Warehouse".code ASC, warehouse_stocks.variant_id ASC`).
+		Find(&levels).Error
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+func clearDefaultWarehouse(tx *gorm.DB, exceptID string) error {
+	query := tx.Model(&model.Warehouse{}).Where("is_default = ?", true)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+	return query.Update("is_default", false).Error
+}
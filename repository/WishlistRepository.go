@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	CreateWishlist(ctx context.Context, wishlist *model.Wishlist) error
+	UpdateWishlist(ctx context.Context, wishlist *model.Wishlist) error
+	DeleteWishlist(ctx context.Context, id string) error
+	GetWishlist(ctx context.Context, userID string, id string) (*model.Wishlist, error)
+	GetWishlistByToken(ctx context.Context, token string) (*model.Wishlist, error)
+	GetUserWishlists(ctx context.Context, userID string) ([]*model.Wishlist, error)
+	CountWishlists(ctx context.Context, userID string) (int64, error)
+	NameExists(ctx context.Context, userID string, name string, excludeID string) (bool, error)
+	AddItem(ctx context.Context, item *model.WishlistItem) error
+	GetItem(ctx context.Context, wishlistID string, id string) (*model.WishlistItem, error)
+	FindItem(ctx context.Context, wishlistID string, productID string, variantID *string) (*model.WishlistItem, error)
+	CountItems(ctx context.Context, wishlistID string) (int64, error)
+	DeleteItem(ctx context.Context, wishlistID string, id string) error
+	RaiseLastPrices(ctx context.Context) error
+	FindPriceDrops(ctx context.Context, limit int) ([]*model.WishlistItem, error)
+	UpdateLastPrice(ctx context.Context, id string, from int, to int) (bool, error)
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository() WishlistRepository {
+	return &wishlistRepository{db: config.GetDB()}
+}
+
+// wishlistPrices selects the current price of every wishlist item whose
+// product, and variant if any, is live.
+const wishlistPrices = `
+	SELECT wishlist_items.id, COALESCE(v.price, p.price) AS current_price
+	FROM wishlist_items
+	JOIN products p ON p.id = wishlist_items.product_id AND p.deleted_at IS NULL
+	LEFT JOIN product_variants v ON v.id = wishlist_items.variant_id AND v.deleted_at IS NULL
+	WHERE wishlist_items.variant_id IS NULL OR v.id IS NOT NULL`
+
+func (r *wishlistRepository) CreateWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(wishlist).Error
+}
+
+func (r *wishlistRepository) UpdateWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	return r.db.WithContext(ctx).Omit("created_at", clause.Associations).Save(wishlist).Error
+}
+
+func (r *wishlistRepository) DeleteWishlist(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&model.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Wishlist{}).Error
+	})
+}
+
+func (r *wishlistRepository) GetWishlist(ctx context.Context, userID string, id string) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	err := withWishlistItems(r.db.WithContext(ctx)).
+		Where("id = ? AND user_id = ?", id, userID).
+		Take(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) GetWishlistByToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	err := withWishlistItems(r.db.WithContext(ctx)).
+		Where("share_token = ?", token).
+		Take(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) GetUserWishlists(ctx context.Context, userID string) ([]*model.Wishlist, error) {
+	var wishlists []*model.Wishlist
+	err := withWishlistItems(r.db.WithContext(ctx)).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&wishlists).Error
+	if err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepository) CountWishlists(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Wishlist{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *wishlistRepository) NameExists(ctx context.Context, userID string, name string, excludeID string) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&model.Wishlist{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *wishlistRepository) AddItem(ctx context.Context, item *model.WishlistItem) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(item).Error
+}
+
+func (r *wishlistRepository) GetItem(ctx context.Context, wishlistID string, id string) (*model.WishlistItem, error) {
+	var item model.WishlistItem
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND wishlist_id = ?", id, wishlistID).
+		Take(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) FindItem(ctx context.Context, wishlistID string, productID string, variantID *string) (*model.WishlistItem, error) {
+	query := r.db.WithContext(ctx).Where("wishlist_id = ? AND product_id = ?", wishlistID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	var item model.WishlistItem
+	if err := query.Take(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) CountItems(ctx context.Context, wishlistID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.WishlistItem{}).Where("wishlist_id = ?", wishlistID).Count(&count).Error
+	return count, err
+}
+
+func (r *wishlistRepository) DeleteItem(ctx context.Context, wishlistID string, id string) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND wishlist_id = ?", id, wishlistID).
+		Delete(&model.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RaiseLastPrices catches up with price increases, so the next drop is
+// measured from the price the product actually had.
+func (r *wishlistRepository) RaiseLastPrices(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec(`
+		UPDATE wishlist_items SET last_price = prices.current_price
+		FROM (` + wishlistPrices + `) prices
+		WHERE prices.id = wishlist_items.id AND prices.current_price > wishlist_items.last_price`).Error
+}
+
+// FindPriceDrops returns wishlist items whose product or variant got cheaper
+// than their last price, with CurrentPrice set.
+func (r *wishlistRepository) FindPriceDrops(ctx context.Context, limit int) ([]*model.WishlistItem, error) {
+	var items []*model.WishlistItem
+	err := r.db.WithContext(ctx).
+		Select("wishlist_items.*, prices.current_price").
+		Joins("JOIN (" + wishlistPrices + ") prices ON prices.id = wishlist_items.id").
+		Where("prices.current_price < wishlist_items.last_price").
+		Preload("Wishlist").
+		Preload("Product").
+		Preload("Variant").
+		Order("wishlist_items.created_at ASC").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdateLastPrice moves the last price of an item from one value to another.
+// It reports false when another worker already did.
+func (r *wishlistRepository) UpdateLastPrice(ctx context.Context, id string, from int, to int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.WishlistItem{}).
+		Where("id = ? AND last_price = ?", id, from).
+		Update("last_price", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// withWishlistItems preloads the items of live products, newest first.
+// Items of deleted products come back if the product is restored.
+func withWishlistItems(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Select("wishlist_items.*").
+				Joins("JOIN products ON products.id = wishlist_items.product_id AND products.deleted_at IS NULL").
+				Order("wishlist_items.created_at DESC")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Variant")
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	"nuxatech-nextmedis/utils"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// ErrCursorMismatch is returned for a cursor issued under another sort order.
+var ErrCursorMismatch = fmt.Errorf("%w: it does not match the sort order", utils.ErrInvalidCursor)
+
+// keyset is a stable sort for cursor pagination, a column with the primary
+// key as tie breaker. Text marks columns compared as strings.
+type keyset struct {
+	Sort   string
+	Column string
+	Desc   bool
+	Text   bool
+}
+
+// CursorPage holds the encoded cursors around a page, empty at either end.
+type CursorPage struct {
+	Next string
+	Prev string
+}
+
+// apply restricts the query to rows after the cursor and fetches one row more
+// than limit, so paginate can tell whether another page follows.
+func (k keyset) apply(query *gorm.DB, cursor *utils.Cursor, limit int) (*gorm.DB, error) {
+	desc := k.Desc
+	if cursor != nil {
+		if cursor.Sort != k.Sort {
+			return nil, ErrCursorMismatch
+		}
+		if cursor.Backward {
+			desc = !desc
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		var value interface{} = cursor.Int
+		if k.Text {
+			value = cursor.Text
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", k.Column, op), value, cursor.ID)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	return query.Order(fmt.Sprintf("%s %s, id %s", k.Column, direction, direction)).Limit(limit + 1), nil
+}
+
+// paginate trims the extra row fetched by apply, restores the display order of
+// backward pages and builds the cursors. key returns a row's sort value and id.
+func paginate[T any](k keyset, rows []T, cursor *utils.Cursor, limit int, key func(T) (utils.Cursor, string)) ([]T, *CursorPage) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page := &CursorPage{}
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	at := func(row T, backward bool) string {
+		c, id := key(row)
+		c.Sort = k.Sort
+		c.ID = id
+		c.Backward = backward
+		return utils.EncodeCursor(c)
+	}
+	if hasMore || backward {
+		page.Next = at(rows[len(rows)-1], false)
+	}
+	if (hasMore && backward) || (!backward && cursor != nil) {
+		page.Prev = at(rows[0], true)
+	}
+	return rows, page
+}
@@ -3,6 +3,7 @@ package route
 import (
 	"nuxatech-nextmedis/handler"
 	"nuxatech-nextmedis/middleware"
+	"nuxatech-nextmedis/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,44 +15,168 @@ func SetupRoutes(
 	cartHandler handler.CartHandler,
 	accountHandler handler.AccountHandler,
 	orderHandler handler.OrderHandler,
+	apiTokenHandler handler.ApiTokenHandler,
+	oidcHandler handler.OIDCHandler,
+	adminHandler handler.AdminHandler,
+	auditHandler handler.AuditHandler,
+	dataExportHandler handler.DataExportHandler,
+	notificationHandler handler.NotificationHandler,
+	addressHandler handler.AddressHandler,
+	categoryHandler handler.CategoryHandler,
+	productVariantHandler handler.ProductVariantHandler,
+	productImageHandler handler.ProductImageHandler,
+	productImportHandler handler.ProductImportHandler,
+	stockHandler handler.StockHandler,
+	warehouseHandler handler.WarehouseHandler,
+	stockAlertHandler handler.StockAlertHandler,
+	productCodeHandler handler.ProductCodeHandler,
+	productReviewHandler handler.ProductReviewHandler,
+	wishlistHandler handler.WishlistHandler,
+	priceHandler handler.PriceHandler,
+	reportHandler handler.ReportHandler,
 ) *gin.Engine {
 	router := gin.Default()
+	// Services receive the gin context, let it fall back to the request context.
+	router.ContextWithFallback = true
+	router.Use(middleware.RequestMeta())
 	v1 := router.Group("/api/v1")
 
 	// Submission TASK 1
 	user := v1.Group("/user")
 	user.POST("/create", userHandler.CreateUser)
-	user.GET("/me", middleware.AuthMiddleware(), userHandler.GetUser)
-	user.GET("/find", middleware.AuthMiddleware(), userHandler.FindUser)
+	user.GET("/me", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), userHandler.GetUser)
+	user.PATCH("/me", middleware.AuthMiddleware(), middleware.SessionOnly(), userHandler.UpdateUser)
+	user.POST("/me/password", middleware.AuthMiddleware(), middleware.SessionOnly(), userHandler.ChangePassword)
+	user.DELETE("/me", middleware.AuthMiddleware(), middleware.SessionOnly(), userHandler.DeleteUser)
+	user.GET("/me/security-activity", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), auditHandler.GetSecurityActivity)
+	user.POST("/me/export", middleware.AuthMiddleware(), middleware.SessionOnly(), middleware.BlockImpersonation(), dataExportHandler.RequestExport)
+	user.GET("/me/export/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), dataExportHandler.GetExport)
+	user.GET("/addresses", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), addressHandler.GetAddresses)
+	user.POST("/addresses", middleware.AuthMiddleware(), middleware.SessionOnly(), addressHandler.CreateAddress)
+	user.GET("/addresses/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), addressHandler.GetAddress)
+	user.PATCH("/addresses/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), addressHandler.UpdateAddress)
+	user.DELETE("/addresses/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), addressHandler.DeleteAddress)
+	user.POST("/addresses/:id/default", middleware.AuthMiddleware(), middleware.SessionOnly(), addressHandler.SetDefaultAddress)
+	user.GET("/notifications", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), notificationHandler.GetNotifications)
+	user.POST("/notifications/:id/read", middleware.AuthMiddleware(), middleware.SessionOnly(), notificationHandler.MarkRead)
+	user.GET("/stock-subscriptions", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), stockAlertHandler.GetSubscriptions)
+	user.POST("/stock-subscriptions", middleware.AuthMiddleware(), middleware.SessionOnly(), stockAlertHandler.Subscribe)
+	user.DELETE("/stock-subscriptions/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), stockAlertHandler.Unsubscribe)
+
+	user.GET("/wishlists", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), wishlistHandler.GetWishlists)
+	user.POST("/wishlists", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.CreateWishlist)
+	user.GET("/wishlists/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), wishlistHandler.GetWishlist)
+	user.PATCH("/wishlists/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.RenameWishlist)
+	user.DELETE("/wishlists/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.DeleteWishlist)
+	user.POST("/wishlists/:id/share", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.ShareWishlist)
+	user.DELETE("/wishlists/:id/share", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.UnshareWishlist)
+	user.POST("/wishlists/:id/items", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.AddItem)
+	user.DELETE("/wishlists/:id/items/:itemId", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.RemoveItem)
+	user.POST("/wishlists/:id/items/:itemId/cart", middleware.AuthMiddleware(), middleware.SessionOnly(), wishlistHandler.MoveToCart)
+
+	user.POST("/tokens", middleware.AuthMiddleware(), middleware.SessionOnly(), apiTokenHandler.CreateToken)
+	user.GET("/tokens", middleware.AuthMiddleware(), middleware.SessionOnly(), apiTokenHandler.ListTokens)
+	user.DELETE("/tokens/:id", middleware.AuthMiddleware(), middleware.SessionOnly(), apiTokenHandler.RevokeToken)
+	user.GET("/identities", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeUserRead), oidcHandler.ListIdentities)
+
+	// Signed links, authorised by the signature rather than a bearer token.
+	v1.GET("/exports/:id/download", dataExportHandler.Download)
 
 	auth := v1.Group("/auth")
 	auth.POST("/login", authHandler.Login)
 	auth.POST("/register", authHandler.Register)
 	auth.POST("/refresh", authHandler.RefreshToken)
 	auth.DELETE("/logout", authHandler.Logout)
+	auth.GET("/oidc/login", oidcHandler.Login)
+	auth.GET("/oidc/callback", oidcHandler.Callback)
 
 	product := v1.Group("/product")
 	product.GET("/", productHandler.GetAllProducts)
+	product.GET("/suggestions", productHandler.SuggestProducts)
 	product.GET("/:id", productHandler.GetProduct)
-	product.POST("/", productHandler.CreateProduct)
+	product.POST("/", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productHandler.CreateProduct)
+	product.PUT("/:id", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productHandler.ReplaceProduct)
+	product.PATCH("/:id", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productHandler.UpdateProduct)
+	product.DELETE("/:id", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productHandler.DeleteProduct)
+	product.POST("/:id/restore", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productHandler.RestoreProduct)
+	product.GET("/:id/variants", productVariantHandler.GetVariants)
+	product.PUT("/:id/options", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productVariantHandler.SetOptions)
+	product.POST("/:id/variants", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productVariantHandler.CreateVariant)
+	product.PATCH("/:id/variants/:variantId", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productVariantHandler.UpdateVariant)
+	product.DELETE("/:id/variants/:variantId", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productVariantHandler.DeleteVariant)
+	product.GET("/:id/images", productImageHandler.GetImages)
+	product.POST("/:id/images", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productImageHandler.UploadImages)
+	product.PUT("/:id/images/order", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productImageHandler.ReorderImages)
+	product.DELETE("/:id/images/:imageId", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productImageHandler.DeleteImage)
+	product.POST("/:id/stock/adjustments", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), stockHandler.AdjustStock)
+	product.GET("/:id/stock/movements", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), stockHandler.GetStockHistory)
+	product.GET("/:id/stock/levels", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), stockHandler.GetStockLevels)
+	product.GET("/:id/prices", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), priceHandler.GetPriceHistory)
+	product.GET("/:id/price-schedules", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), priceHandler.GetSchedules)
+	product.POST("/:id/price-schedules", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), priceHandler.CreateSchedule)
+	product.DELETE("/:id/price-schedules/:scheduleId", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), priceHandler.CancelSchedule)
+	product.GET("/:id/codes", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productCodeHandler.GetCodes)
+	product.POST("/:id/codes", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productCodeHandler.UploadCodes)
+	product.POST("/:id/codes/:codeId/void", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productCodeHandler.VoidCode)
+	product.POST("/:id/codes/:codeId/reissue", middleware.AuthMiddleware(), middleware.AdminOnly(), middleware.RequireScope(model.ScopeProductsWrite), productCodeHandler.ReissueCode)
+	product.GET("/:id/reviews", productReviewHandler.GetProductReviews)
+	product.POST("/:id/reviews", middleware.AuthMiddleware(), middleware.SessionOnly(), middleware.BlockImpersonation(), productReviewHandler.CreateReview)
+
+	reviews := v1.Group("/reviews")
+	reviews.POST("/:id/helpful", middleware.AuthMiddleware(), middleware.SessionOnly(), middleware.BlockImpersonation(), productReviewHandler.VoteHelpful)
+	reviews.DELETE("/:id/helpful", middleware.AuthMiddleware(), middleware.SessionOnly(), middleware.BlockImpersonation(), productReviewHandler.RemoveHelpfulVote)
+
+	v1.GET("/wishlists/shared/:token", wishlistHandler.GetSharedWishlist)
+
+	category := v1.Group("/category")
+	category.GET("/", categoryHandler.GetCategoryTree)
+	category.GET("/:slug/products", categoryHandler.GetCategoryProducts)
 
 	cart := v1.Group("cart")
-	cart.POST("/add", middleware.AuthMiddleware(), cartHandler.AddToCart)
-	cart.GET("/", middleware.AuthMiddleware(), cartHandler.GetCart)
-	cart.PUT("/item/:id", middleware.AuthMiddleware(), cartHandler.UpdateCartItem)
-	cart.DELETE("/item/:id", middleware.AuthMiddleware(), cartHandler.RemoveFromCart)
+	cart.POST("/add", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeCartWrite), cartHandler.AddToCart)
+	cart.GET("/", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeCartRead), cartHandler.GetCart)
+	cart.PUT("/item/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeCartWrite), cartHandler.UpdateCartItem)
+	cart.DELETE("/item/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeCartWrite), cartHandler.RemoveFromCart)
 
 	order := v1.Group("order")
-	order.POST("/", middleware.AuthMiddleware(), orderHandler.CreateOrder)
-	order.GET("/:id", middleware.AuthMiddleware(), orderHandler.GetOrder)
-	order.PUT("/:id/status", middleware.AuthMiddleware(), orderHandler.UpdateOrderStatus)
-	order.GET("/", middleware.AuthMiddleware(), orderHandler.GetUserOrders)
+	order.POST("/", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeOrdersWrite), middleware.BlockImpersonation(), orderHandler.CreateOrder)
+	order.GET("/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeOrdersRead), orderHandler.GetOrder)
+	order.PUT("/:id/status", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeOrdersWrite), middleware.BlockImpersonation(), orderHandler.UpdateOrderStatus)
+	order.GET("/", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeOrdersRead), orderHandler.GetUserOrders)
 
 	// Submission TASK 3
-	user.POST("/wallet", middleware.AuthMiddleware(), accountHandler.CreateAccount)
-	user.POST("/wallet/:id/deposit", middleware.AuthMiddleware(), accountHandler.Deposit)
-	user.POST("/wallet/:id/withdraw", middleware.AuthMiddleware(), accountHandler.Withdraw)
-	user.GET("/wallet/:id", middleware.AuthMiddleware(), accountHandler.GetAccount)
+	user.POST("/wallet", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeWalletWrite), middleware.BlockImpersonation(), accountHandler.CreateAccount)
+	user.POST("/wallet/:id/deposit", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeWalletWrite), middleware.BlockImpersonation(), accountHandler.Deposit)
+	user.POST("/wallet/:id/withdraw", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeWalletWrite), middleware.BlockImpersonation(), accountHandler.Withdraw)
+	user.GET("/wallet/:id", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeWalletRead), accountHandler.GetAccount)
+	user.GET("/wallet/:id/transactions", middleware.AuthMiddleware(), middleware.RequireScope(model.ScopeWalletRead), accountHandler.GetTransactions)
+
+	admin := v1.Group("/admin", middleware.AuthMiddleware(), middleware.AdminOnly())
+	// Account administration and the audit log are never open to API tokens,
+	// whatever their scopes.
+	admin.GET("/users", middleware.SessionOnly(), adminHandler.ListUsers)
+	admin.GET("/users/find", middleware.SessionOnly(), userHandler.FindUser)
+	admin.POST("/users/:id/suspend", middleware.SessionOnly(), adminHandler.SuspendUser)
+	admin.POST("/users/:id/reactivate", middleware.SessionOnly(), adminHandler.ReactivateUser)
+	admin.POST("/users/:id/logout", middleware.SessionOnly(), adminHandler.ForceLogout)
+	admin.POST("/users/:id/impersonate", middleware.SessionOnly(), adminHandler.ImpersonateUser)
+	admin.GET("/audit-events", middleware.SessionOnly(), auditHandler.QueryEvents)
+	admin.POST("/categories", middleware.RequireScope(model.ScopeProductsWrite), categoryHandler.CreateCategory)
+	admin.PATCH("/categories/:id", middleware.RequireScope(model.ScopeProductsWrite), categoryHandler.UpdateCategory)
+	admin.POST("/categories/:id/move", middleware.RequireScope(model.ScopeProductsWrite), categoryHandler.MoveCategory)
+	admin.DELETE("/categories/:id", middleware.RequireScope(model.ScopeProductsWrite), categoryHandler.DeleteCategory)
+	admin.POST("/products/imports", middleware.RequireScope(model.ScopeProductsWrite), productImportHandler.StartImport)
+	admin.GET("/products/imports/:id", middleware.RequireScope(model.ScopeProductsWrite), productImportHandler.GetImport)
+	admin.GET("/products/export", middleware.RequireScope(model.ScopeProductsWrite), productImportHandler.ExportProducts)
+	admin.GET("/warehouses", middleware.RequireScope(model.ScopeProductsWrite), warehouseHandler.GetWarehouses)
+	admin.POST("/warehouses", middleware.RequireScope(model.ScopeProductsWrite), warehouseHandler.CreateWarehouse)
+	admin.PATCH("/warehouses/:id", middleware.RequireScope(model.ScopeProductsWrite), warehouseHandler.UpdateWarehouse)
+	admin.POST("/warehouses/transfers", middleware.RequireScope(model.ScopeProductsWrite), warehouseHandler.TransferStock)
+	admin.GET("/reviews", middleware.RequireScope(model.ScopeProductsWrite), productReviewHandler.GetReviewQueue)
+	admin.POST("/reviews/:id/moderate", middleware.RequireScope(model.ScopeProductsWrite), productReviewHandler.ModerateReview)
+	admin.GET("/reports/sales", middleware.SessionOnly(), reportHandler.GetSalesReport)
+	admin.GET("/orders/:id", middleware.RequireScope(model.ScopeOrdersRead), orderHandler.AdminGetOrder)
+	admin.PUT("/orders/:id/status", middleware.RequireScope(model.ScopeOrdersWrite), orderHandler.AdminUpdateOrderStatus)
 
 	return router
 }
@@ -18,11 +18,22 @@ type AccountService interface {
 	GetAccount(ctx context.Context, id string) (*response.AccountResponse, error)
 	Deposit(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error)
 	Withdraw(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error)
+	GetTransactions(ctx context.Context, userID string, accountID string, params TransactionQueryParams) (*response.TransactionPagingResponse, error)
+}
+
+// ErrAccountNotFound is also returned for accounts of other users.
+var ErrAccountNotFound = errors.New("account not found")
+
+type TransactionQueryParams struct {
+	CursorParams
+	Page  int
+	Limit int
 }
 
 type accountService struct {
 	accountRepo     repository.AccountRepository
 	transactionRepo repository.TransactionRepository
+	auditService    AuditService
 	validate        *validator.Validate
 	mutexes         sync.Map
 }
@@ -33,6 +44,29 @@ func (s *accountService) getLock(accountID string) *sync.Mutex {
 }
 
 func (s *accountService) CreateAccount(ctx context.Context, req *request.CreateAccountRequest) (*response.AccountResponse, error) {
+	account, err := s.createAccount(ctx, req)
+
+	event := &model.AuditEvent{
+		Action:     model.AuditActionWalletCreate,
+		TargetType: "user",
+		TargetID:   req.UserID,
+		Outcome:    auditOutcome(err),
+		Metadata:   model.AuditMetadata{},
+	}
+	if account != nil {
+		event.TargetType = "account"
+		event.TargetID = account.ID
+		event.Metadata["user_id"] = account.UserID
+	}
+	if err != nil {
+		event.Metadata["error"] = err.Error()
+	}
+	s.auditService.Record(ctx, event)
+
+	return account, err
+}
+
+func (s *accountService) createAccount(ctx context.Context, req *request.CreateAccountRequest) (*response.AccountResponse, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return nil, err
 	}
@@ -80,6 +114,12 @@ func (s *accountService) GetAccount(ctx context.Context, id string) (*response.A
 }
 
 func (s *accountService) Deposit(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error) {
+	transaction, err := s.deposit(ctx, accountID, req)
+	s.recordTransaction(ctx, model.AuditActionWalletDeposit, accountID, req, transaction, err)
+	return transaction, err
+}
+
+func (s *accountService) deposit(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return nil, err
 	}
@@ -141,18 +181,16 @@ func (s *accountService) Deposit(ctx context.Context, accountID string, req *req
 
 	transaction.Status = "success"
 
-	return &response.TransactionResponse{
-		ID:          transaction.ID,
-		AccountID:   transaction.AccountID,
-		Amount:      transaction.Amount,
-		Type:        transaction.Type,
-		Status:      transaction.Status,
-		Description: transaction.Description,
-		CreatedAt:   transaction.CreatedAt,
-	}, nil
+	return toTransactionResponse(transaction), nil
 }
 
 func (s *accountService) Withdraw(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error) {
+	transaction, err := s.withdraw(ctx, accountID, req)
+	s.recordTransaction(ctx, model.AuditActionWalletWithdraw, accountID, req, transaction, err)
+	return transaction, err
+}
+
+func (s *accountService) withdraw(ctx context.Context, accountID string, req *request.TransactionRequest) (*response.TransactionResponse, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return nil, err
 	}
@@ -217,6 +255,75 @@ func (s *accountService) Withdraw(ctx context.Context, accountID string, req *re
 
 	transaction.Status = "success"
 
+	return toTransactionResponse(transaction), nil
+}
+
+// GetTransactions lists the transactions of one of the user's accounts, newest
+// first.
+func (s *accountService) GetTransactions(ctx context.Context, userID string, accountID string, params TransactionQueryParams) (*response.TransactionPagingResponse, error) {
+	account, err := s.accountRepo.GetAccount(ctx, accountID)
+	if err != nil || account.UserID != userID {
+		return nil, ErrAccountNotFound
+	}
+
+	if params.cursorMode() {
+		return s.getTransactionsByCursor(ctx, accountID, params)
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	}
+
+	transactions, err := s.transactionRepo.GetAccountTransactions(ctx, accountID, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.transactionRepo.CountAccountTransactions(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.TransactionPagingResponse{
+		Metadata: &response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: toTransactionResponses(transactions),
+	}, nil
+}
+
+func (s *accountService) getTransactionsByCursor(ctx context.Context, accountID string, params TransactionQueryParams) (*response.TransactionPagingResponse, error) {
+	cursor, err := params.decode()
+	if err != nil {
+		return nil, err
+	}
+	limit := cursorLimit(params.Limit)
+
+	transactions, page, err := s.transactionRepo.GetAccountTransactionsAfter(ctx, accountID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IncludeTotal {
+		count, err := s.transactionRepo.CountAccountTransactions(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return &response.TransactionPagingResponse{
+		Cursor: toCursorMetadata(page, limit, total),
+		Result: toTransactionResponses(transactions),
+	}, nil
+}
+
+func toTransactionResponse(transaction *model.Transaction) *response.TransactionResponse {
 	return &response.TransactionResponse{
 		ID:          transaction.ID,
 		AccountID:   transaction.AccountID,
@@ -225,7 +332,32 @@ func (s *accountService) Withdraw(ctx context.Context, accountID string, req *re
 		Status:      transaction.Status,
 		Description: transaction.Description,
 		CreatedAt:   transaction.CreatedAt,
-	}, nil
+	}
+}
+
+func toTransactionResponses(transactions []*model.Transaction) []*response.TransactionResponse {
+	result := make([]*response.TransactionResponse, len(transactions))
+	for i, transaction := range transactions {
+		result[i] = toTransactionResponse(transaction)
+	}
+	return result
+}
+
+func (s *accountService) recordTransaction(ctx context.Context, action string, accountID string, req *request.TransactionRequest, transaction *response.TransactionResponse, err error) {
+	event := &model.AuditEvent{
+		Action:     action,
+		TargetType: "account",
+		TargetID:   accountID,
+		Outcome:    auditOutcome(err),
+		Metadata:   model.AuditMetadata{"amount": req.Amount},
+	}
+	if transaction != nil {
+		event.Metadata["transaction_id"] = transaction.ID
+	}
+	if err != nil {
+		event.Metadata["error"] = err.Error()
+	}
+	s.auditService.Record(ctx, event)
 }
 
 func (s *accountService) validateAmount(amount int64) error {
@@ -241,10 +373,15 @@ func (s *accountService) validateAmount(amount int64) error {
 	return nil
 }
 
-func NewAccountService(accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository) AccountService {
+func NewAccountService(
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	auditService AuditService,
+) AccountService {
 	return &accountService{
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
+		auditService:    auditService,
 		validate:        validator.New(),
 	}
 }
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type AddressService interface {
+	CreateAddress(ctx context.Context, userID string, req request.CreateAddressRequest) (*model.UserAddress, error)
+	GetAddresses(ctx context.Context, userID string) ([]*model.UserAddress, error)
+	GetAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error)
+	UpdateAddress(ctx context.Context, userID string, id string, req request.UpdateAddressRequest) (*model.UserAddress, error)
+	DeleteAddress(ctx context.Context, userID string, id string) error
+	SetDefaultAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error)
+}
+
+const maxUserAddresses = 20
+
+type addressService struct {
+	addressRepo      repository.AddressRepository
+	addressValidator AddressValidator
+	validate         *validator.Validate
+}
+
+func NewAddressService(addressRepo repository.AddressRepository, addressValidator AddressValidator) AddressService {
+	return &addressService{
+		addressRepo:      addressRepo,
+		addressValidator: addressValidator,
+		validate:         validator.New(),
+	}
+}
+
+// CreateAddress stores a new address. The first address always becomes the default.
+func (s *addressService) CreateAddress(ctx context.Context, userID string, req request.CreateAddressRequest) (*model.UserAddress, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	count, err := s.addressRepo.CountUserAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxUserAddresses {
+		return nil, errors.New("address book is full")
+	}
+
+	now := time.Now().UnixMilli()
+	address := &model.UserAddress{
+		UserID:        userID,
+		Label:         req.Label,
+		RecipientName: req.RecipientName,
+		Phone:         req.Phone,
+		Line1:         req.Line1,
+		Line2:         req.Line2,
+		City:          req.City,
+		Region:        req.Region,
+		PostalCode:    req.PostalCode,
+		CountryCode:   strings.ToUpper(req.CountryCode),
+		IsDefault:     req.IsDefault || count == 0,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.addressValidator.Validate(address); err != nil {
+		return nil, err
+	}
+
+	tx := s.addressRepo.BeginTx(ctx)
+	if tx == nil {
+		return nil, errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	if address.IsDefault {
+		if err := s.addressRepo.ClearDefault(ctx, tx, userID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.addressRepo.CreateAddress(ctx, tx, address); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+func (s *addressService) GetAddresses(ctx context.Context, userID string) ([]*model.UserAddress, error) {
+	return s.addressRepo.GetUserAddresses(ctx, userID)
+}
+
+func (s *addressService) GetAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error) {
+	address, err := s.addressRepo.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, errors.New("address not found")
+	}
+	return address, nil
+}
+
+func (s *addressService) UpdateAddress(ctx context.Context, userID string, id string, req request.UpdateAddressRequest) (*model.UserAddress, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	address, err := s.addressRepo.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, errors.New("address not found")
+	}
+
+	if req.Label != nil {
+		address.Label = *req.Label
+	}
+	if req.RecipientName != nil {
+		address.RecipientName = *req.RecipientName
+	}
+	if req.Phone != nil {
+		address.Phone = *req.Phone
+	}
+	if req.Line1 != nil {
+		address.Line1 = *req.Line1
+	}
+	if req.Line2 != nil {
+		address.Line2 = *req.Line2
+	}
+	if req.City != nil {
+		address.City = *req.City
+	}
+	if req.Region != nil {
+		address.Region = *req.Region
+	}
+	if req.PostalCode != nil {
+		address.PostalCode = *req.PostalCode
+	}
+	if req.CountryCode != nil {
+		address.CountryCode = strings.ToUpper(*req.CountryCode)
+	}
+
+	if err := s.addressValidator.Validate(address); err != nil {
+		return nil, err
+	}
+
+	address.UpdatedAt = time.Now().UnixMilli()
+	if err := s.addressRepo.UpdateAddress(ctx, nil, address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// DeleteAddress removes an address. When it was the default, the oldest
+// remaining address takes over.
+func (s *addressService) DeleteAddress(ctx context.Context, userID string, id string) error {
+	address, err := s.addressRepo.GetAddress(ctx, userID, id)
+	if err != nil {
+		return errors.New("address not found")
+	}
+
+	tx := s.addressRepo.BeginTx(ctx)
+	if tx == nil {
+		return errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	if err := s.addressRepo.DeleteAddress(ctx, tx, userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("address not found")
+		}
+		return err
+	}
+
+	if address.IsDefault {
+		next, err := s.addressRepo.FindOldestAddress(ctx, tx, userID)
+		if err == nil {
+			next.IsDefault = true
+			next.UpdatedAt = time.Now().UnixMilli()
+			if err := s.addressRepo.UpdateAddress(ctx, tx, next); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (s *addressService) SetDefaultAddress(ctx context.Context, userID string, id string) (*model.UserAddress, error) {
+	address, err := s.addressRepo.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, errors.New("address not found")
+	}
+	if address.IsDefault {
+		return address, nil
+	}
+
+	tx := s.addressRepo.BeginTx(ctx)
+	if tx == nil {
+		return nil, errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	if err := s.addressRepo.ClearDefault(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	address.IsDefault = true
+	address.UpdatedAt = time.Now().UnixMilli()
+	if err := s.addressRepo.UpdateAddress(ctx, tx, address); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
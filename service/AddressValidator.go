@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"nuxatech-nextmedis/model"
+	"regexp"
+	"strings"
+)
+
+// AddressValidator checks an address against country specific rules. It is
+// passed to AddressService so deployments can swap in their own rules.
+type AddressValidator interface {
+	Validate(address *model.UserAddress) error
+}
+
+// AddressRule describes what a country requires of an address.
+type AddressRule struct {
+	// RequiredFields uses the address JSON names, e.g. "region".
+	RequiredFields []string
+	// PostalCode is matched against the whole postal code when set.
+	PostalCode *regexp.Regexp
+}
+
+type CountryAddressValidator struct {
+	rules    map[string]AddressRule
+	fallback AddressRule
+}
+
+// NewAddressValidator returns a validator with rules for the countries we
+// ship to most. Unknown countries only need a street, city and recipient.
+func NewAddressValidator() *CountryAddressValidator {
+	v := &CountryAddressValidator{
+		rules: map[string]AddressRule{},
+		fallback: AddressRule{
+			RequiredFields: []string{"recipient_name", "line1", "city"},
+		},
+	}
+
+	v.Register("ID", AddressRule{
+		RequiredFields: []string{"recipient_name", "phone", "line1", "city", "region", "postal_code"},
+		PostalCode:     regexp.MustCompile(`^\d{5}$`),
+	})
+	v.Register("SG", AddressRule{
+		RequiredFields: []string{"recipient_name", "line1", "postal_code"},
+		PostalCode:     regexp.MustCompile(`^\d{6}$`),
+	})
+	v.Register("MY", AddressRule{
+		RequiredFields: []string{"recipient_name", "line1", "city", "region", "postal_code"},
+		PostalCode:     regexp.MustCompile(`^\d{5}$`),
+	})
+	v.Register("US", AddressRule{
+		RequiredFields: []string{"recipient_name", "line1", "city", "region", "postal_code"},
+		PostalCode:     regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	})
+	v.Register("GB", AddressRule{
+		RequiredFields: []string{"recipient_name", "line1", "city", "postal_code"},
+		PostalCode:     regexp.MustCompile(`(?i)^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	})
+
+	return v
+}
+
+// Register adds or replaces the rule for an ISO 3166-1 alpha-2 country code.
+func (v *CountryAddressValidator) Register(countryCode string, rule AddressRule) {
+	v.rules[strings.ToUpper(countryCode)] = rule
+}
+
+func (v *CountryAddressValidator) Validate(address *model.UserAddress) error {
+	rule, ok := v.rules[strings.ToUpper(address.CountryCode)]
+	if !ok {
+		rule = v.fallback
+	}
+
+	for _, field := range rule.RequiredFields {
+		if strings.TrimSpace(addressField(address, field)) == "" {
+			return fmt.Errorf("%s is required for addresses in %s", field, address.CountryCode)
+		}
+	}
+
+	if rule.PostalCode != nil && address.PostalCode != "" && !rule.PostalCode.MatchString(address.PostalCode) {
+		return fmt.Errorf("invalid postal code for %s: %s", address.CountryCode, address.PostalCode)
+	}
+
+	return nil
+}
+
+func addressField(address *model.UserAddress, field string) string {
+	switch field {
+	case "recipient_name":
+		return address.RecipientName
+	case "phone":
+		return address.Phone
+	case "line1":
+		return address.Line1
+	case "line2":
+		return address.Line2
+	case "city":
+		return address.City
+	case "region":
+		return address.Region
+	case "postal_code":
+		return address.PostalCode
+	}
+	return ""
+}
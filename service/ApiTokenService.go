@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const defaultApiTokenDays = 90
+
+type ApiTokenService interface {
+	CreateToken(ctx context.Context, userID string, req *request.CreateApiTokenRequest) (*response.CreatedApiTokenResponse, error)
+	ListTokens(ctx context.Context, userID string) ([]response.ApiTokenResponse, error)
+	RevokeToken(ctx context.Context, userID string, tokenID string) error
+	ValidateToken(ctx context.Context, tokenString string) (*model.ApiToken, *model.User, error)
+}
+
+type apiTokenService struct {
+	tokenRepo repository.ApiTokenRepository
+	userRepo  repository.UserRepository
+	validate  *validator.Validate
+}
+
+func NewApiTokenService(tokenRepo repository.ApiTokenRepository, userRepo repository.UserRepository) ApiTokenService {
+	return &apiTokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		validate:  validator.New(),
+	}
+}
+
+func (s *apiTokenService) CreateToken(ctx context.Context, userID string, req *request.CreateApiTokenRequest) (*response.CreatedApiTokenResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	scopes := make(model.TokenScopes, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.ApiTokenScopes, scope) {
+			return nil, fmt.Errorf("unknown scope: %s", scope)
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultApiTokenDays
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	plainToken := model.ApiTokenPrefix + secret
+
+	now := time.Now()
+	token := &model.ApiToken{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: utils.HashToken(plainToken),
+		Prefix:    plainToken[:len(model.ApiTokenPrefix)+8],
+		Scopes:    scopes,
+		ExpiresAt: now.AddDate(0, 0, days).UnixMilli(),
+		CreatedAt: now.UnixMilli(),
+	}
+
+	if err := s.tokenRepo.CreateToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &response.CreatedApiTokenResponse{
+		ApiTokenResponse: toApiTokenResponse(token),
+		Token:            plainToken,
+	}, nil
+}
+
+func (s *apiTokenService) ListTokens(ctx context.Context, userID string) ([]response.ApiTokenResponse, error) {
+	tokens, err := s.tokenRepo.FindUserTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.ApiTokenResponse, len(tokens))
+	for i, token := range tokens {
+		result[i] = toApiTokenResponse(token)
+	}
+	return result, nil
+}
+
+func (s *apiTokenService) RevokeToken(ctx context.Context, userID string, tokenID string) error {
+	err := s.tokenRepo.RevokeToken(ctx, userID, tokenID, time.Now().UnixMilli())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("token not found")
+	}
+	return err
+}
+
+func (s *apiTokenService) ValidateToken(ctx context.Context, tokenString string) (*model.ApiToken, *model.User, error) {
+	if !strings.HasPrefix(tokenString, model.ApiTokenPrefix) {
+		return nil, nil, errors.New("invalid token")
+	}
+
+	token, err := s.tokenRepo.FindByHash(ctx, utils.HashToken(tokenString))
+	if err != nil {
+		return nil, nil, errors.New("invalid token")
+	}
+
+	now := time.Now().UnixMilli()
+	if token.RevokedAt != nil {
+		return nil, nil, errors.New("token has been revoked")
+	}
+	if now > token.ExpiresAt {
+		return nil, nil, errors.New("token has expired")
+	}
+
+	user, err := s.userRepo.FindById(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, errors.New("invalid token")
+	}
+	if user.IsSuspended() {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	if err := s.tokenRepo.TouchToken(ctx, token.ID, now); err != nil {
+		return nil, nil, err
+	}
+
+	return token, user, nil
+}
+
+func toApiTokenResponse(token *model.ApiToken) response.ApiTokenResponse {
+	return response.ApiTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		RevokedAt:  token.RevokedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"time"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, event *model.AuditEvent)
+	QueryEvents(ctx context.Context, params AuditQueryParams) (*response.AuditPagingResponse, error)
+	RecentSecurityActivity(ctx context.Context, userID string, limit int) ([]*model.AuditEvent, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+type AuditQueryParams struct {
+	Page       int
+	Limit      int
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	From       int64
+	To         int64
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record never fails the calling operation, a lost audit row is logged instead.
+// IP, user agent and actor default to what RequestMeta put on the context.
+func (s *auditService) Record(ctx context.Context, event *model.AuditEvent) {
+	if meta := utils.RequestMetaFromContext(ctx); meta != nil {
+		if event.IP == "" {
+			event.IP = meta.IP
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = meta.UserAgent
+		}
+		if event.ActorID == nil && meta.ActorID != "" {
+			actorID := meta.ActorID
+			event.ActorID = &actorID
+		}
+	}
+	if event.Outcome == "" {
+		event.Outcome = model.AuditOutcomeSuccess
+	}
+	if event.CreatedAt == 0 {
+		event.CreatedAt = time.Now().UnixMilli()
+	}
+
+	if err := s.auditRepo.CreateEvent(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("Failed to record audit event %s: %v", event.Action, err)
+	}
+}
+
+func (s *auditService) QueryEvents(ctx context.Context, params AuditQueryParams) (*response.AuditPagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	filter := repository.AuditFilter{
+		ActorID:    params.ActorID,
+		Action:     params.Action,
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+		Outcome:    params.Outcome,
+		From:       params.From,
+		To:         params.To,
+	}
+
+	events, total, err := s.auditRepo.FindEvents(ctx, filter, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.AuditPagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: events,
+	}, nil
+}
+
+func (s *auditService) RecentSecurityActivity(ctx context.Context, userID string, limit int) ([]*model.AuditEvent, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.auditRepo.FindUserSecurityEvents(ctx, userID, model.SecurityAuditActions, limit)
+}
+
+// auditOutcome maps an operation error onto an audit outcome.
+func auditOutcome(err error) string {
+	if err != nil {
+		return model.AuditOutcomeFailure
+	}
+	return model.AuditOutcomeSuccess
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"nuxatech-nextmedis/config"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
@@ -25,22 +26,29 @@ type AuthService interface {
 	ValidateAccessToken(tokenString string) (*request.AccessTokenPayload, error)
 	ValidateRefreshToken(tokenString string) (*request.RefreshTokenPayload, error)
 	Logout(ctx context.Context, tokenString string) error
+	Impersonate(ctx context.Context, actorID string, targetUserID string, req *request.ImpersonateRequest) (*response.ImpersonationResponse, error)
+	VerifySession(ctx context.Context, payload *request.AccessTokenPayload) (*model.User, error)
 }
 
+var ErrAccountSuspended = errors.New("account is suspended")
+
 type authService struct {
-	userRepo  repository.UserRepository
-	tokenRepo repository.PersonalTokenRepository
-	validate  *validator.Validate
+	userRepo     repository.UserRepository
+	tokenRepo    repository.PersonalTokenRepository
+	auditService AuditService
+	validate     *validator.Validate
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	tokenRepo repository.PersonalTokenRepository,
+	auditService AuditService,
 ) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
-		validate:  validator.New(),
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		auditService: auditService,
+		validate:     validator.New(),
 	}
 }
 
@@ -59,6 +67,8 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 		Email:     req.Email,
 		Username:  req.Username,
 		Password:  hashedPassword,
+		Role:      model.RoleCustomer,
+		Status:    model.UserStatusActive,
 		CreatedAt: time.Now().UnixMilli(),
 	}
 
@@ -66,6 +76,13 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 		return nil, err
 	}
 
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &user.ID,
+		Action:     model.AuditActionRegister,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+
 	return &response.RegisterResponse{
 		User: response.UserResponse{
 			ID:       user.ID,
@@ -82,18 +99,49 @@ func (s *authService) Login(ctx context.Context, req request.LoginRequest) (*res
 
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			Action:   model.AuditActionLoginFailed,
+			Outcome:  model.AuditOutcomeFailure,
+			Metadata: model.AuditMetadata{"email": req.Email, "reason": "unknown email"},
+		})
 		return nil, errors.New("invalid credentials")
 	}
 
 	if !utils.VerifyPassword(req.Password, user.Password) {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			Action:     model.AuditActionLoginFailed,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Outcome:    model.AuditOutcomeFailure,
+			Metadata:   model.AuditMetadata{"email": req.Email, "reason": "wrong password"},
+		})
 		return nil, errors.New("invalid credentials")
 	}
 
+	if user.IsSuspended() {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			Action:     model.AuditActionLoginFailed,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Outcome:    model.AuditOutcomeFailure,
+			Metadata:   model.AuditMetadata{"email": req.Email, "reason": "account suspended"},
+		})
+		return nil, ErrAccountSuspended
+	}
+
 	tokens, err := s.GenerateTokenPair(user)
 	if err != nil {
 		return nil, err
 	}
 
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &user.ID,
+		Action:     model.AuditActionLogin,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   model.AuditMetadata{"method": "password"},
+	})
+
 	return &response.LoginResponse{
 		User: response.UserResponse{
 			ID:        user.ID,
@@ -111,6 +159,25 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*r
 		return nil, err
 	}
 
+	tokens, err := s.rotateRefreshToken(ctx, payload, refreshToken)
+
+	event := &model.AuditEvent{
+		ActorID:    &payload.UserID,
+		Action:     model.AuditActionTokenRefresh,
+		TargetType: "user",
+		TargetID:   payload.UserID,
+		Outcome:    auditOutcome(err),
+		Metadata:   model.AuditMetadata{"token_id": payload.TokenID},
+	}
+	if err != nil {
+		event.Metadata["error"] = err.Error()
+	}
+	s.auditService.Record(ctx, event)
+
+	return tokens, err
+}
+
+func (s *authService) rotateRefreshToken(ctx context.Context, payload *request.RefreshTokenPayload, refreshToken string) (*response.TokenResponse, error) {
 	token, err := s.tokenRepo.FindByID(ctx, payload.TokenID)
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
@@ -124,6 +191,9 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*r
 	if err != nil {
 		return nil, err
 	}
+	if user.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
 
 	if err := s.tokenRepo.DeleteToken(ctx, payload.TokenID); err != nil {
 		return nil, err
@@ -133,23 +203,17 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*r
 }
 
 func (s *authService) GenerateTokenPair(user *model.User) (*response.TokenResponse, error) {
+	issuedAt := time.Now()
 	accessPayload := request.AccessTokenPayload{
 		UserID:    user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(time.Duration(config.Envs.AccessTokenTTL) * time.Second),
+		Role:      user.Role,
+		IssuedAt:  issuedAt,
+		ExpiredAt: issuedAt.Add(time.Duration(config.Envs.AccessTokenTTL) * time.Second),
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":    accessPayload.UserID,
-		"username":   accessPayload.Username,
-		"email":      accessPayload.Email,
-		"issued_at":  accessPayload.IssuedAt.Unix(),
-		"expired_at": accessPayload.ExpiredAt.Unix(),
-	})
-
-	accessTokenString, err := accessToken.SignedString([]byte(config.Envs.JwtAccessSecret))
+	accessTokenString, err := s.signAccessToken(accessPayload)
 	if err != nil {
 		return nil, err
 	}
@@ -158,8 +222,8 @@ func (s *authService) GenerateTokenPair(user *model.User) (*response.TokenRespon
 	refreshPayload := request.RefreshTokenPayload{
 		TokenID:   refreshTokenID,
 		UserID:    user.ID,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(time.Duration(config.Envs.RefreshTokenTTL) * time.Second),
+		IssuedAt:  issuedAt,
+		ExpiredAt: issuedAt.Add(time.Duration(config.Envs.RefreshTokenTTL) * time.Second),
 	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
@@ -213,13 +277,66 @@ func (s *authService) ValidateAccessToken(tokenString string) (*request.AccessTo
 		return nil, errors.New("token has expired")
 	}
 
-	return &request.AccessTokenPayload{
+	// Tokens issued before roles existed carry no role claim.
+	role, _ := claims["role"].(string)
+	if role == "" {
+		role = model.RoleCustomer
+	}
+
+	payload := &request.AccessTokenPayload{
 		UserID:    claims["user_id"].(string),
 		Username:  claims["username"].(string),
 		Email:     claims["email"].(string),
-		IssuedAt:  time.Unix(int64(claims["issued_at"].(float64)), 0),
+		Role:      role,
+		IssuedAt:  time.UnixMilli(int64(math.Round(claims["issued_at"].(float64) * 1000))),
 		ExpiredAt: expiredAt,
-	}, nil
+	}
+
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorID, _ := act["sub"].(string)
+		actorUsername, _ := act["username"].(string)
+		if actorID == "" {
+			return nil, errors.New("invalid token")
+		}
+		payload.Actor = &request.TokenActor{
+			UserID:   actorID,
+			Username: actorUsername,
+		}
+	}
+
+	return payload, nil
+}
+
+// VerifySession checks a stateless access token against the user record, so
+// suspension and force-logout take effect before the token expires.
+func (s *authService) VerifySession(ctx context.Context, payload *request.AccessTokenPayload) (*model.User, error) {
+	user, err := s.userRepo.FindById(ctx, payload.UserID)
+	if err != nil {
+		return nil, errors.New("user no longer exists")
+	}
+	if user.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
+	if sessionRevoked(user, payload.IssuedAt) {
+		return nil, errors.New("session has been revoked")
+	}
+
+	// An impersonation token only lasts as long as its admin could still
+	// start it.
+	if payload.Actor != nil {
+		actor, err := s.userRepo.FindById(ctx, payload.Actor.UserID)
+		if err != nil || actor.IsSuspended() || actor.Role != model.RoleAdmin || sessionRevoked(actor, payload.IssuedAt) {
+			return nil, errors.New("impersonation session is no longer valid")
+		}
+	}
+	return user, nil
+}
+
+// sessionRevoked reports whether a token issued at issuedAt predates the
+// revocation of the user's sessions, to the millisecond. Tokens that only
+// carry whole seconds count from the start of their second.
+func sessionRevoked(user *model.User, issuedAt time.Time) bool {
+	return user.SessionsRevokedAt != nil && issuedAt.UnixMilli() < *user.SessionsRevokedAt
 }
 
 func (s *authService) ValidateRefreshToken(tokenString string) (*request.RefreshTokenPayload, error) {
@@ -267,5 +384,102 @@ func (s *authService) Logout(ctx context.Context, tokenString string) error {
 		return err
 	}
 
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &payload.UserID,
+		Action:     model.AuditActionLogout,
+		TargetType: "user",
+		TargetID:   payload.UserID,
+		Metadata:   model.AuditMetadata{"token_id": payload.TokenID},
+	})
+
 	return nil
 }
+
+// Impersonate issues a short-lived access token for the target user that
+// carries the admin in its act claim. No refresh token is issued.
+func (s *authService) Impersonate(ctx context.Context, actorID string, targetUserID string, req *request.ImpersonateRequest) (*response.ImpersonationResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	actor, err := s.userRepo.FindById(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != model.RoleAdmin {
+		return nil, errors.New("only admins can impersonate users")
+	}
+
+	target, err := s.userRepo.FindById(ctx, targetUserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if target.ID == actor.ID {
+		return nil, errors.New("cannot impersonate yourself")
+	}
+	if target.Role == model.RoleAdmin {
+		return nil, errors.New("cannot impersonate another admin")
+	}
+
+	now := time.Now()
+	payload := request.AccessTokenPayload{
+		UserID:    target.ID,
+		Username:  target.Username,
+		Email:     target.Email,
+		Role:      target.Role,
+		IssuedAt:  now,
+		ExpiredAt: now.Add(time.Duration(config.Envs.ImpersonationTTL) * time.Second),
+		Actor: &request.TokenActor{
+			UserID:   actor.ID,
+			Username: actor.Username,
+		},
+	}
+
+	accessToken, err := s.signAccessToken(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &actor.ID,
+		Action:     model.AuditActionImpersonationStart,
+		TargetType: "user",
+		TargetID:   target.ID,
+		Metadata: model.AuditMetadata{
+			"reason":     req.Reason,
+			"expires_at": payload.ExpiredAt.UnixMilli(),
+		},
+	})
+
+	return &response.ImpersonationResponse{
+		AccessToken: accessToken,
+		ExpiresAt:   payload.ExpiredAt.UnixMilli(),
+		User: response.UserResponse{
+			ID:        target.ID,
+			Username:  target.Username,
+			Email:     target.Email,
+			CreatedAt: time.UnixMilli(target.CreatedAt).Format("02-01-2006 15:04:05"),
+		},
+	}, nil
+}
+
+// signAccessToken writes issued_at in fractional seconds, so revocations can be
+// compared to the millisecond.
+func (s *authService) signAccessToken(payload request.AccessTokenPayload) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":    payload.UserID,
+		"username":   payload.Username,
+		"email":      payload.Email,
+		"role":       payload.Role,
+		"issued_at":  float64(payload.IssuedAt.UnixMilli()) / 1000,
+		"expired_at": payload.ExpiredAt.Unix(),
+	}
+	if payload.Actor != nil {
+		claims["act"] = map[string]interface{}{
+			"sub":      payload.Actor.UserID,
+			"username": payload.Actor.Username,
+		}
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.Envs.JwtAccessSecret))
+}
@@ -22,6 +22,7 @@ type CartService interface {
 type cartService struct {
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
+	variantRepo repository.ProductVariantRepository
 	validate    *validator.Validate
 }
 
@@ -36,11 +37,21 @@ func (s *cartService) AddToCart(ctx context.Context, userID string, req *request
 		return nil, err
 	}
 
+	variant, err := s.resolveVariant(ctx, product, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check stock
-	if product.Stock < req.Quantity {
+	if availableStock(product, variant) < req.Quantity {
 		return nil, errors.New("insufficient stock")
 	}
 
+	var variantID *string
+	if variant != nil {
+		variantID = &variant.ID
+	}
+
 	// Get or create cart
 	cart, err := s.cartRepo.GetCart(ctx, userID)
 	if err != nil {
@@ -48,7 +59,7 @@ func (s *cartService) AddToCart(ctx context.Context, userID string, req *request
 	}
 
 	// Check if product already in cart
-	existingItem, err := s.cartRepo.GetCartItem(ctx, cart.ID, req.ProductID)
+	existingItem, err := s.cartRepo.GetCartItem(ctx, cart.ID, req.ProductID, variantID)
 	if err == nil {
 		existingItem.Quantity += req.Quantity
 		existingItem.UpdatedAt = time.Now().UnixMilli()
@@ -59,6 +70,7 @@ func (s *cartService) AddToCart(ctx context.Context, userID string, req *request
 		cartItem := &model.CartItem{
 			CartID:    cart.ID,
 			ProductID: req.ProductID,
+			VariantID: variantID,
 			Quantity:  req.Quantity,
 			CreatedAt: time.Now().UnixMilli(),
 			UpdatedAt: time.Now().UnixMilli(),
@@ -85,9 +97,10 @@ func (s *cartService) GetCart(ctx context.Context, userID string) (*response.Car
 		cartItems[i] = response.CartItemResponse{
 			ID:       item.ID,
 			Product:  toProductResponse(item.Product),
+			Variant:  item.Variant,
 			Quantity: item.Quantity,
 		}
-		total += item.Product.Price * item.Quantity
+		total += unitPrice(&item.Product, item.Variant) * item.Quantity
 	}
 
 	return &response.CartResponse{
@@ -126,7 +139,14 @@ func (s *cartService) UpdateCartItem(ctx context.Context, userID string, cartIte
 	if err != nil {
 		return nil, err
 	}
-	if product.Stock < req.Quantity {
+	var variant *model.ProductVariant
+	if cartItem.VariantID != nil {
+		variant, err = s.variantRepo.GetVariant(ctx, product.ID, *cartItem.VariantID)
+		if err != nil {
+			return nil, errors.New("variant not found")
+		}
+	}
+	if availableStock(product, variant) < req.Quantity {
 		return nil, errors.New("insufficient stock")
 	}
 
@@ -161,6 +181,41 @@ func (s *cartService) RemoveFromCart(ctx context.Context, userID string, cartIte
 	return s.cartRepo.RemoveItem(ctx, cartItemID)
 }
 
+// resolveVariant returns the chosen variant, which is required exactly when the
+// product is sold in variants.
+func (s *cartService) resolveVariant(ctx context.Context, product *model.Product, variantID string) (*model.ProductVariant, error) {
+	if product.VariantCount == 0 {
+		if variantID != "" {
+			return nil, errors.New("product has no variants")
+		}
+		return nil, nil
+	}
+	if variantID == "" {
+		return nil, errors.New("variant_id is required for this product")
+	}
+
+	variant, err := s.variantRepo.GetVariant(ctx, product.ID, variantID)
+	if err != nil {
+		return nil, errors.New("variant not found")
+	}
+	return variant, nil
+}
+
+// availableStock is the stock not held by unpaid orders.
+func availableStock(product *model.Product, variant *model.ProductVariant) int {
+	if variant != nil {
+		return variant.Stock - variant.Reserved
+	}
+	return product.Stock - product.Reserved
+}
+
+func unitPrice(product *model.Product, variant *model.ProductVariant) int {
+	if variant != nil {
+		return variant.Price
+	}
+	return product.Price
+}
+
 func toProductResponse(product model.Product) response.ProductResponse {
 	return response.ProductResponse{
 		ID:             product.ID,
@@ -168,21 +223,31 @@ func toProductResponse(product model.Product) response.ProductResponse {
 		Description:    product.Description,
 		Image:          product.Image,
 		Stock:          product.Stock,
+		Available:      product.Stock - product.Reserved,
 		Price:          product.Price,
+		CompareAtPrice: product.CompareAtPrice,
 		Weight:         product.Weight,
 		BasePrice:      product.BasePrice,
 		SKU:            product.SKU,
 		Slug:           product.Slug,
 		UniqueCodeType: product.UniqueCodeType,
 		Sold:           product.Sold,
+		RatingAverage:  product.RatingAverage,
+		RatingCount:    product.RatingCount,
 		CreatedAt:      product.CreatedAt,
+		Version:        product.Version,
 	}
 }
 
-func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository) CartService {
+func NewCartService(
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+) CartService {
 	return &cartService{
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
+		variantRepo: variantRepo,
 		validate:    validator.New(),
 	}
 }
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) ([]*response.CategoryResponse, error)
+	CreateCategory(ctx context.Context, req request.CreateCategoryRequest) (*model.Category, error)
+	UpdateCategory(ctx context.Context, id string, req request.UpdateCategoryRequest) (*model.Category, error)
+	MoveCategory(ctx context.Context, id string, req request.MoveCategoryRequest) (*model.Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+	validate     *validator.Validate
+}
+
+func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+		validate:     validator.New(),
+	}
+}
+
+// GetCategoryTree returns the top-level categories with their children nested.
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]*response.CategoryResponse, error) {
+	categories, err := s.categoryRepo.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*response.CategoryResponse, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &response.CategoryResponse{
+			ID:          category.ID,
+			ParentID:    category.ParentID,
+			Name:        category.Name,
+			Slug:        category.Slug,
+			Description: category.Description,
+			Position:    category.Position,
+			Children:    []*response.CategoryResponse{},
+		}
+	}
+
+	// categories is already ordered, so appending keeps siblings in order.
+	roots := []*response.CategoryResponse{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	return roots, nil
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, req request.CreateCategoryRequest) (*model.Category, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.categoryRepo.GetCategory(ctx, *req.ParentID); err != nil {
+			return nil, errors.New("parent category not found")
+		}
+	}
+
+	categorySlug := req.Slug
+	if categorySlug == "" {
+		categorySlug = req.Name
+	}
+	categorySlug = slug.Make(categorySlug)
+	if err := s.checkSlug(ctx, categorySlug, ""); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	category := &model.Category{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Slug:        categorySlug,
+		Description: req.Description,
+		Position:    req.Position,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.categoryRepo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, id string, req request.UpdateCategoryRequest) (*model.Category, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryRepo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Slug != nil {
+		categorySlug := slug.Make(*req.Slug)
+		if categorySlug != category.Slug {
+			if err := s.checkSlug(ctx, categorySlug, category.ID); err != nil {
+				return nil, err
+			}
+			category.Slug = categorySlug
+		}
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.Position != nil {
+		category.Position = *req.Position
+	}
+
+	category.UpdatedAt = time.Now().UnixMilli()
+	if err := s.categoryRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// MoveCategory reparents a category. Its descendants move along with it, and
+// it can never end up below one of its own descendants.
+func (s *categoryService) MoveCategory(ctx context.Context, id string, req request.MoveCategoryRequest) (*model.Category, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryRepo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.categoryRepo.GetCategory(ctx, *req.ParentID); err != nil {
+			return nil, errors.New("parent category not found")
+		}
+
+		subtree, err := s.categoryRepo.GetDescendantIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(subtree, *req.ParentID) {
+			return nil, errors.New("cannot move a category under itself or one of its descendants")
+		}
+	}
+
+	category.ParentID = req.ParentID
+	if req.Position != nil {
+		category.Position = *req.Position
+	}
+	category.UpdatedAt = time.Now().UnixMilli()
+
+	if err := s.categoryRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// DeleteCategory only removes leaf categories, products lose the membership.
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) error {
+	children, err := s.categoryRepo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return errors.New("category has subcategories, move or delete them first")
+	}
+
+	if err := s.categoryRepo.DeleteCategory(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *categoryService) checkSlug(ctx context.Context, categorySlug string, excludeID string) error {
+	if categorySlug == "" {
+		return errors.New("slug must contain letters or digits")
+	}
+	exists, err := s.categoryRepo.SlugExists(ctx, categorySlug, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("slug already exist")
+	}
+	return nil
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DataExportService interface {
+	RequestExport(ctx context.Context, userID string) (*response.DataExportResponse, error)
+	GetExport(ctx context.Context, userID string, exportID string) (*response.DataExportResponse, error)
+	OpenDownload(ctx context.Context, exportID string, expires string, signature string) (*model.DataExport, error)
+	Run(ctx context.Context)
+}
+
+type dataExportService struct {
+	exportRepo          repository.DataExportRepository
+	userRepo            repository.UserRepository
+	tokenRepo           repository.PersonalTokenRepository
+	apiTokenRepo        repository.ApiTokenRepository
+	identityRepo        repository.UserIdentityRepository
+	cartRepo            repository.CartRepository
+	orderRepo           repository.OrderRepository
+	accountRepo         repository.AccountRepository
+	transactionRepo     repository.TransactionRepository
+	addressRepo         repository.AddressRepository
+	notificationService NotificationService
+	auditService        AuditService
+	queue               chan string
+}
+
+// exportSession is a refresh token row without the token itself.
+type exportSession struct {
+	ID        string `json:"id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+const exportSweepInterval = time.Minute
+
+func NewDataExportService(
+	exportRepo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	tokenRepo repository.PersonalTokenRepository,
+	apiTokenRepo repository.ApiTokenRepository,
+	identityRepo repository.UserIdentityRepository,
+	cartRepo repository.CartRepository,
+	orderRepo repository.OrderRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	addressRepo repository.AddressRepository,
+	notificationService NotificationService,
+	auditService AuditService,
+) DataExportService {
+	return &dataExportService{
+		exportRepo:          exportRepo,
+		userRepo:            userRepo,
+		tokenRepo:           tokenRepo,
+		apiTokenRepo:        apiTokenRepo,
+		identityRepo:        identityRepo,
+		cartRepo:            cartRepo,
+		orderRepo:           orderRepo,
+		accountRepo:         accountRepo,
+		transactionRepo:     transactionRepo,
+		addressRepo:         addressRepo,
+		notificationService: notificationService,
+		auditService:        auditService,
+		queue:               make(chan string, 100),
+	}
+}
+
+// RequestExport enqueues a new export, or returns the one already in flight.
+func (s *dataExportService) RequestExport(ctx context.Context, userID string) (*response.DataExportResponse, error) {
+	active, err := s.exportRepo.FindActiveUserExport(ctx, userID)
+	if err == nil {
+		return s.toDataExportResponse(active), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	export := &model.DataExport{
+		UserID:    userID,
+		Status:    model.DataExportStatusPending,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if err := s.exportRepo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	// A full queue is fine, the periodic sweep picks up pending rows.
+	select {
+	case s.queue <- export.ID:
+	default:
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionDataExportRequest,
+		TargetType: "user",
+		TargetID:   userID,
+		Metadata:   model.AuditMetadata{"export_id": export.ID},
+	})
+
+	return s.toDataExportResponse(export), nil
+}
+
+func (s *dataExportService) GetExport(ctx context.Context, userID string, exportID string) (*response.DataExportResponse, error) {
+	export, err := s.exportRepo.GetExport(ctx, exportID)
+	if err != nil || export.UserID != userID {
+		return nil, errors.New("export not found")
+	}
+	return s.toDataExportResponse(export), nil
+}
+
+// OpenDownload checks a signed link and returns the export whose file may be served.
+func (s *dataExportService) OpenDownload(ctx context.Context, exportID string, expires string, signature string) (*model.DataExport, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !utils.VerifySignature(config.Envs.SigningSecret, signature, "export", exportID, expires) {
+		return nil, errors.New("invalid download link")
+	}
+	if time.Now().UnixMilli() > expiresAt {
+		return nil, errors.New("download link has expired")
+	}
+
+	export, err := s.exportRepo.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, errors.New("export not found")
+	}
+	if export.Status != model.DataExportStatusReady {
+		return nil, errors.New("export is no longer available")
+	}
+	if _, err := s.userRepo.FindById(ctx, export.UserID); err != nil {
+		return nil, errors.New("export is no longer available")
+	}
+
+	return export, nil
+}
+
+// Run processes queued exports and removes expired archives until ctx is done.
+func (s *dataExportService) Run(ctx context.Context) {
+	if err := s.exportRepo.ResetProcessing(ctx); err != nil {
+		log.Printf("Failed to reset interrupted exports: %v", err)
+	}
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(exportSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *dataExportService) sweep(ctx context.Context) {
+	pending, err := s.exportRepo.FindByStatus(ctx, []string{model.DataExportStatusPending})
+	if err != nil {
+		log.Printf("Failed to load pending exports: %v", err)
+	}
+	for _, export := range pending {
+		s.process(ctx, export.ID)
+	}
+
+	expired, err := s.exportRepo.FindExpired(ctx, time.Now().UnixMilli())
+	if err != nil {
+		log.Printf("Failed to load expired exports: %v", err)
+		return
+	}
+	for _, export := range expired {
+		if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export file %s: %v", export.FilePath, err)
+			continue
+		}
+		export.Status = model.DataExportStatusExpired
+		export.FilePath = ""
+		if err := s.exportRepo.UpdateExport(ctx, export); err != nil {
+			log.Printf("Failed to expire export %s: %v", export.ID, err)
+		}
+	}
+}
+
+func (s *dataExportService) process(ctx context.Context, exportID string) {
+	claimed, err := s.exportRepo.ClaimExport(ctx, exportID)
+	if err != nil {
+		log.Printf("Failed to claim export %s: %v", exportID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	export, err := s.exportRepo.GetExport(ctx, exportID)
+	if err != nil {
+		log.Printf("Failed to load export %s: %v", exportID, err)
+		return
+	}
+
+	// Exports of deleted users are never built.
+	if _, err := s.userRepo.FindById(ctx, export.UserID); err != nil {
+		export.Status = model.DataExportStatusExpired
+		if err := s.exportRepo.UpdateExport(ctx, export); err != nil {
+			log.Printf("Failed to update export %s: %v", export.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	completedAt := now.UnixMilli()
+	export.CompletedAt = &completedAt
+
+	path, size, err := s.buildArchive(ctx, export)
+	if err != nil {
+		log.Printf("Failed to build export %s: %v", export.ID, err)
+		export.Status = model.DataExportStatusFailed
+		export.Error = err.Error()
+		if err := s.exportRepo.UpdateExport(ctx, export); err != nil {
+			log.Printf("Failed to update export %s: %v", export.ID, err)
+		}
+		return
+	}
+
+	expiresAt := now.Add(time.Duration(config.Envs.ExportRetention) * time.Second).UnixMilli()
+	export.Status = model.DataExportStatusReady
+	export.FilePath = path
+	export.FileSize = size
+	export.ExpiresAt = &expiresAt
+	finished, err := s.exportRepo.FinishExport(ctx, export)
+	if err != nil || !finished {
+		if err != nil {
+			log.Printf("Failed to update export %s: %v", export.ID, err)
+		}
+		os.Remove(path)
+		return
+	}
+
+	link, linkExpiresAt := s.downloadLink(export)
+	s.notificationService.Notify(ctx, export.UserID, model.NotificationTypeDataExportReady,
+		"Your data export is ready",
+		"Your personal data export can be downloaded until the link expires.",
+		model.NotificationData{
+			"export_id":           export.ID,
+			"download_url":        link,
+			"download_expires_at": linkExpiresAt,
+		},
+	)
+}
+
+func (s *dataExportService) buildArchive(ctx context.Context, export *model.DataExport) (string, int64, error) {
+	user, err := s.userRepo.FindById(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load user: %w", err)
+	}
+
+	tokens, err := s.tokenRepo.FindUserTokens(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load sessions: %w", err)
+	}
+	sessions := make([]exportSession, len(tokens))
+	for i, token := range tokens {
+		sessions[i] = exportSession{ID: token.ID, CreatedAt: token.CreatedAt}
+	}
+
+	apiTokens, err := s.apiTokenRepo.FindUserTokens(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load api tokens: %w", err)
+	}
+	apiTokenResponses := make([]response.ApiTokenResponse, len(apiTokens))
+	for i, token := range apiTokens {
+		apiTokenResponses[i] = toApiTokenResponse(token)
+	}
+
+	identities, err := s.identityRepo.FindByUserID(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load identities: %w", err)
+	}
+
+	addresses, err := s.addressRepo.GetUserAddresses(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load addresses: %w", err)
+	}
+
+	cart, err := s.cartRepo.FindUserCart(ctx, export.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", 0, fmt.Errorf("load cart: %w", err)
+	}
+
+	orders, err := s.orderRepo.GetAllUserOrders(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load orders: %w", err)
+	}
+
+	accounts, err := s.accountRepo.GetAccountsByUserID(ctx, export.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("load accounts: %w", err)
+	}
+	accountIDs := make([]string, len(accounts))
+	for i, account := range accounts {
+		accountIDs[i] = account.ID
+	}
+	transactions, err := s.transactionRepo.FindByAccountIDs(ctx, accountIDs)
+	if err != nil {
+		return "", 0, fmt.Errorf("load transactions: %w", err)
+	}
+
+	if err := os.MkdirAll(config.Envs.ExportDir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(config.Envs.ExportDir, export.ID+".zip")
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmpPath)
+
+	zw := zip.NewWriter(file)
+	writeErr := func() error {
+		if err := writeZipJSON(zw, "user.json", user); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "user.csv", []string{"id", "username", "email", "role", "created_at"}, [][]string{
+			{user.ID, user.Username, user.Email, user.Role, formatInt(user.CreatedAt)},
+		}); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "sessions.json", sessions); err != nil {
+			return err
+		}
+		sessionRows := make([][]string, len(sessions))
+		for i, session := range sessions {
+			sessionRows[i] = []string{session.ID, formatInt(session.CreatedAt)}
+		}
+		if err := writeZipCSV(zw, "sessions.csv", []string{"id", "created_at"}, sessionRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "api_tokens.json", apiTokenResponses); err != nil {
+			return err
+		}
+		if err := writeZipJSON(zw, "identities.json", identities); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "addresses.json", addresses); err != nil {
+			return err
+		}
+		addressRows := make([][]string, len(addresses))
+		for i, address := range addresses {
+			addressRows[i] = []string{address.ID, address.Label, address.RecipientName, address.Phone, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.CountryCode, strconv.FormatBool(address.IsDefault)}
+		}
+		if err := writeZipCSV(zw, "addresses.csv", []string{"id", "label", "recipient_name", "phone", "line1", "line2", "city", "region", "postal_code", "country_code", "is_default"}, addressRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "cart.json", cart); err != nil {
+			return err
+		}
+		var cartRows [][]string
+		if cart != nil {
+			for _, item := range cart.Items {
+				cartRows = append(cartRows, []string{item.ID, item.ProductID, item.Product.Name, variantSKU(item.Variant), strconv.Itoa(item.Quantity), formatInt(item.CreatedAt)})
+			}
+		}
+		if err := writeZipCSV(zw, "cart_items.csv", []string{"id", "product_id", "product_name", "variant_sku", "quantity", "created_at"}, cartRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "orders.json", orders); err != nil {
+			return err
+		}
+		orderRows := make([][]string, len(orders))
+		var orderItemRows [][]string
+		for i, order := range orders {
+			orderRows[i] = []string{order.ID, string(order.Status), formatInt(order.TotalAmount), formatInt(order.CreatedAt), formatOptionalInt(order.PaidAt)}
+			for _, item := range order.Items {
+				orderItemRows = append(orderItemRows, []string{order.ID, item.ProductID, item.Product.Name, variantSKU(item.Variant), strconv.Itoa(item.Quantity), formatInt(item.Price)})
+			}
+		}
+		if err := writeZipCSV(zw, "orders.csv", []string{"id", "status", "total_amount", "created_at", "paid_at"}, orderRows); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "order_items.csv", []string{"order_id", "product_id", "product_name", "variant_sku", "quantity", "price"}, orderItemRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "accounts.json", accounts); err != nil {
+			return err
+		}
+		accountRows := make([][]string, len(accounts))
+		for i, account := range accounts {
+			accountRows[i] = []string{account.ID, formatInt(account.Balance), formatInt(account.CreatedAt), formatInt(account.UpdatedAt)}
+		}
+		if err := writeZipCSV(zw, "accounts.csv", []string{"id", "balance", "created_at", "updated_at"}, accountRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "transactions.json", transactions); err != nil {
+			return err
+		}
+		transactionRows := make([][]string, len(transactions))
+		for i, transaction := range transactions {
+			transactionRows[i] = []string{transaction.ID, transaction.AccountID, transaction.Type, transaction.Status, formatInt(transaction.Amount), transaction.Description, formatInt(transaction.CreatedAt)}
+		}
+		return writeZipCSV(zw, "transactions.csv", []string{"id", "account_id", "type", "status", "amount", "description", "created_at"}, transactionRows)
+	}()
+
+	if err := zw.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if err := file.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		return "", 0, writeErr
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return path, info.Size(), nil
+}
+
+// downloadLink signs a link that is valid for ExportLinkTTL, capped at the
+// export's own expiry.
+func (s *dataExportService) downloadLink(export *model.DataExport) (string, int64) {
+	expiresAt := time.Now().Add(time.Duration(config.Envs.ExportLinkTTL) * time.Second).UnixMilli()
+	if export.ExpiresAt != nil && *export.ExpiresAt < expiresAt {
+		expiresAt = *export.ExpiresAt
+	}
+	expires := formatInt(expiresAt)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", utils.Sign(config.Envs.SigningSecret, "export", export.ID, expires))
+
+	return fmt.Sprintf("%s/api/v1/exports/%s/download?%s", config.Envs.AppURL, export.ID, query.Encode()), expiresAt
+}
+
+func (s *dataExportService) toDataExportResponse(export *model.DataExport) *response.DataExportResponse {
+	res := &response.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		FileSize:    export.FileSize,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+	if export.Status == model.DataExportStatusReady {
+		res.DownloadURL, res.DownloadExpiresAt = s.downloadLink(export)
+	}
+	return res
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeZipCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return formatInt(*v)
+}
+
+func variantSKU(v *model.ProductVariant) string {
+	if v == nil {
+		return ""
+	}
+	return v.SKU
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/mailer"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, userID string, notificationType string, title string, body string, data model.NotificationData)
+	GetNotifications(ctx context.Context, userID string, params NotificationQueryParams) (*response.NotificationPagingResponse, error)
+	MarkRead(ctx context.Context, userID string, notificationID string) error
+	Run(ctx context.Context)
+}
+
+type NotificationQueryParams struct {
+	Page       int
+	Limit      int
+	UnreadOnly bool
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	mailer           mailer.Mailer
+	queue            chan string
+}
+
+const (
+	emailSweepInterval = time.Minute
+	emailSweepBatch    = 100
+	maxEmailAttempts   = 5
+)
+
+// emailNotificationTypes are also sent by email, on top of the in-app copy.
+var emailNotificationTypes = map[string]bool{
+	model.NotificationTypeLowStock:    true,
+	model.NotificationTypeBackInStock: true,
+	model.NotificationTypePriceDrop:   true,
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	mailer mailer.Mailer,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		mailer:           mailer,
+		queue:            make(chan string, 100),
+	}
+}
+
+// Notify stores an in-app notification and queues its email copy when the
+// type has one. Delivery problems are logged and never fail the operation
+// that triggered the notification.
+func (s *notificationService) Notify(ctx context.Context, userID string, notificationType string, title string, body string, data model.NotificationData) {
+	notification := &model.Notification{
+		UserID:    userID,
+		Type:      notificationType,
+		Title:     title,
+		Body:      body,
+		Data:      data,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if emailNotificationTypes[notificationType] {
+		notification.EmailStatus = model.NotificationEmailPending
+	}
+
+	if err := s.notificationRepo.CreateNotification(context.WithoutCancel(ctx), notification); err != nil {
+		log.Printf("Failed to create notification %s for user %s: %v", notificationType, userID, err)
+		return
+	}
+
+	if notification.EmailStatus == model.NotificationEmailPending {
+		// A full queue is fine, the periodic sweep picks up pending emails.
+		select {
+		case s.queue <- notification.ID:
+		default:
+		}
+	}
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, userID string, params NotificationQueryParams) (*response.NotificationPagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	notifications, total, err := s.notificationRepo.GetUserNotifications(ctx, userID, params.UnreadOnly, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.NotificationPagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: notifications,
+	}, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID string, notificationID string) error {
+	err := s.notificationRepo.MarkRead(ctx, userID, notificationID, time.Now().UnixMilli())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("notification not found")
+	}
+	return err
+}
+
+// Run emails queued notifications until ctx is done.
+func (s *notificationService) Run(ctx context.Context) {
+	if err := s.notificationRepo.ResetSendingEmails(ctx); err != nil {
+		log.Printf("Failed to reset interrupted emails: %v", err)
+	}
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(emailSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.sendEmail(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *notificationService) sweep(ctx context.Context) {
+	ids, err := s.notificationRepo.FindPendingEmails(ctx, emailSweepBatch)
+	if err != nil {
+		log.Printf("Failed to load pending emails: %v", err)
+		return
+	}
+	for _, id := range ids {
+		s.sendEmail(ctx, id)
+	}
+}
+
+func (s *notificationService) sendEmail(ctx context.Context, id string) {
+	notification, err := s.notificationRepo.ClaimEmail(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to claim email of notification %s: %v", id, err)
+		return
+	}
+
+	notification.EmailAttempts++
+	err = s.deliver(ctx, notification)
+	switch {
+	case err == nil:
+		now := time.Now().UnixMilli()
+		notification.EmailStatus = model.NotificationEmailSent
+		notification.EmailedAt = &now
+	case notification.EmailAttempts >= maxEmailAttempts:
+		log.Printf("Giving up on email of notification %s: %v", id, err)
+		notification.EmailStatus = model.NotificationEmailFailed
+	default:
+		// Retried by the next sweep.
+		log.Printf("Failed to email notification %s: %v", id, err)
+		notification.EmailStatus = model.NotificationEmailPending
+	}
+
+	if err := s.notificationRepo.UpdateEmailStatus(ctx, notification); err != nil {
+		log.Printf("Failed to update email status of notification %s: %v", id, err)
+	}
+}
+
+func (s *notificationService) deliver(ctx context.Context, notification *model.Notification) error {
+	user, err := s.userRepo.FindById(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return errors.New("user has no email address")
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: notification.Title,
+		Body:    notification.Body,
+	})
+}
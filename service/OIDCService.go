@@ -0,0 +1,644 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// OIDCLoginTTL is how long a started login can be completed. The login
+// state is kept in a signed cookie for that long.
+const OIDCLoginTTL = 10 * time.Minute
+
+type OIDCService interface {
+	// AuthorizationURL also returns the login state to store in a cookie on
+	// the browser starting the login. HandleCallback only accepts a callback
+	// coming back with that cookie.
+	AuthorizationURL(ctx context.Context) (*response.OIDCLoginResponse, string, error)
+	HandleCallback(ctx context.Context, code string, state string, loginState string) (*response.LoginResponse, error)
+	ListIdentities(ctx context.Context, userID string) ([]response.UserIdentityResponse, error)
+}
+
+type oidcEndpoints struct {
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+	JWKSURL     string `json:"jwks_uri"`
+}
+
+// oidcLoginState is what the login cookie carries between AuthorizationURL
+// and HandleCallback.
+type oidcLoginState struct {
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+type oidcIDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce"`
+}
+
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+type oidcService struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	authService  AuthService
+	auditService AuditService
+	httpClient   *http.Client
+	endpoints    *oidcEndpoints
+	endpointsMu  sync.Mutex
+	keys         map[string]interface{}
+	keysMu       sync.Mutex
+}
+
+func NewOIDCService(
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	authService AuthService,
+	auditService AuditService,
+) OIDCService {
+	return &oidcService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		authService:  authService,
+		auditService: auditService,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *oidcService) AuthorizationURL(ctx context.Context) (*response.OIDCLoginResponse, string, error) {
+	endpoints, err := s.getEndpoints(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	login := oidcLoginState{ExpiresAt: time.Now().Add(OIDCLoginTTL).UnixMilli()}
+	for _, value := range []*string{&login.State, &login.CodeVerifier, &login.Nonce} {
+		if *value, err = utils.GenerateRandomToken(32); err != nil {
+			return nil, "", err
+		}
+	}
+	loginState, err := encodeLoginState(&login)
+	if err != nil {
+		return nil, "", err
+	}
+
+	challenge := sha256.Sum256([]byte(login.CodeVerifier))
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", config.Envs.OIDCClientID)
+	query.Set("redirect_uri", config.Envs.OIDCRedirectURL)
+	query.Set("scope", config.Envs.OIDCScopes)
+	query.Set("state", login.State)
+	query.Set("nonce", login.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(endpoints.AuthURL, "?") {
+		separator = "&"
+	}
+
+	return &response.OIDCLoginResponse{
+		AuthorizationURL: endpoints.AuthURL + separator + query.Encode(),
+	}, loginState, nil
+}
+
+func (s *oidcService) HandleCallback(ctx context.Context, code string, state string, loginState string) (*response.LoginResponse, error) {
+	if code == "" || state == "" {
+		return nil, errors.New("missing code or state")
+	}
+
+	login, err := decodeLoginState(loginState)
+	if err != nil || subtle.ConstantTimeCompare([]byte(login.State), []byte(state)) != 1 {
+		return nil, errors.New("invalid or expired state")
+	}
+
+	endpoints, err := s.getEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, idToken, err := s.exchangeCode(ctx, endpoints, code, login.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.verifyIDToken(ctx, endpoints, idToken, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.fetchUserInfo(ctx, endpoints, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if info.Subject != claims.Subject {
+		return nil, errors.New("userinfo subject does not match the id token")
+	}
+
+	user, err := s.resolveUser(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			Action:     model.AuditActionLoginFailed,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Outcome:    model.AuditOutcomeFailure,
+			Metadata:   model.AuditMetadata{"method": "oidc", "reason": "account suspended"},
+		})
+		return nil, ErrAccountSuspended
+	}
+
+	tokens, err := s.authService.GenerateTokenPair(user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &user.ID,
+		Action:     model.AuditActionLogin,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   model.AuditMetadata{"method": "oidc", "provider": config.Envs.OIDCProvider},
+	})
+
+	return &response.LoginResponse{
+		User: response.UserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			CreatedAt: time.UnixMilli(user.CreatedAt).Format("02-01-2006 15:04:05"),
+		},
+		Token: *tokens,
+	}, nil
+}
+
+func (s *oidcService) ListIdentities(ctx context.Context, userID string) ([]response.UserIdentityResponse, error) {
+	identities, err := s.identityRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		result[i] = response.UserIdentityResponse{
+			ID:          identity.ID,
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			CreatedAt:   identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		}
+	}
+	return result, nil
+}
+
+// resolveUser finds the user linked to the provider identity. Unknown
+// identities are linked to an existing account with the same email, or get a
+// fresh account otherwise, once the provider has verified the email.
+func (s *oidcService) resolveUser(ctx context.Context, info *oidcUserInfo) (*model.User, error) {
+	provider := config.Envs.OIDCProvider
+	now := time.Now().UnixMilli()
+
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, provider, info.Subject)
+	if err == nil {
+		if err := s.identityRepo.TouchIdentity(ctx, identity.ID, now); err != nil {
+			return nil, err
+		}
+		return s.userRepo.FindById(ctx, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if info.Email == "" {
+		return nil, errors.New("identity provider did not return an email")
+	}
+	if !info.EmailVerified {
+		return nil, errors.New("identity provider has not verified the email")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, info.Email)
+	created := false
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.createUser(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+		created = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	identity = &model.UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider,
+		Subject:     info.Subject,
+		Email:       info.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+	if err := s.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	if created {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			ActorID:    &user.ID,
+			Action:     model.AuditActionRegister,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Metadata:   model.AuditMetadata{"method": "oidc", "provider": provider},
+		})
+	}
+	s.auditService.Record(ctx, &model.AuditEvent{
+		ActorID:    &user.ID,
+		Action:     model.AuditActionIdentityLink,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata: model.AuditMetadata{
+			"provider":    provider,
+			"identity_id": identity.ID,
+			"email":       info.Email,
+		},
+	})
+
+	return user, nil
+}
+
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+func (s *oidcService) createUser(ctx context.Context, info *oidcUserInfo) (*model.User, error) {
+	base := info.PreferredUsername
+	if base == "" {
+		base = strings.Split(info.Email, "@")[0]
+	}
+	base = usernameSanitizer.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 20 {
+		base = base[:20]
+	}
+
+	username := base
+	if _, err := s.userRepo.FindByUsername(ctx, username); err == nil {
+		suffix, err := utils.GenerateRandomToken(3)
+		if err != nil {
+			return nil, err
+		}
+		username = base + "-" + suffix
+	}
+
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:  username,
+		Email:     info.Email,
+		Password:  utils.HashPassword(password),
+		Role:      model.RoleCustomer,
+		Status:    model.UserStatusActive,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// exchangeCode returns the access token and ID token issued for the code.
+func (s *oidcService) exchangeCode(ctx context.Context, endpoints *oidcEndpoints, code string, verifier string) (string, string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", config.Envs.OIDCRedirectURL)
+	form.Set("client_id", config.Envs.OIDCClientID)
+	form.Set("code_verifier", verifier)
+	if config.Envs.OIDCClientSecret != "" {
+		form.Set("client_secret", config.Envs.OIDCClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+		Error       string `json:"error"`
+	}
+	if err := s.doJSON(req, &tokenResponse); err != nil {
+		return "", "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	if tokenResponse.AccessToken == "" {
+		return "", "", fmt.Errorf("token exchange failed: %s", tokenResponse.Error)
+	}
+	if tokenResponse.IDToken == "" {
+		return "", "", errors.New("token exchange failed: no id token returned")
+	}
+
+	return tokenResponse.AccessToken, tokenResponse.IDToken, nil
+}
+
+// verifyIDToken checks the signature of the ID token against the provider
+// keys, that it was issued to this client for this login, and returns its
+// claims.
+func (s *oidcService) verifyIDToken(ctx context.Context, endpoints *oidcEndpoints, idToken string, nonce string) (*oidcIDTokenClaims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(config.Envs.OIDCClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	}
+	if config.Envs.OIDCIssuer != "" {
+		options = append(options, jwt.WithIssuer(config.Envs.OIDCIssuer))
+	}
+
+	var claims oidcIDTokenClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.getSigningKey(ctx, endpoints, kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id token: missing subject")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	return &claims, nil
+}
+
+// getSigningKey returns the provider key with the given id, fetching the key
+// set again when the key is unknown, as providers rotate their keys.
+func (s *oidcService) getSigningKey(ctx context.Context, endpoints *oidcEndpoints, kid string) (interface{}, error) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	if endpoints.JWKSURL == "" {
+		return nil, errors.New("oidc jwks endpoint is not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoints.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := s.doJSON(req, &keySet); err != nil {
+		return nil, fmt.Errorf("oidc jwks request failed: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key interface{}
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRSAKey(jwk.N, jwk.E)
+		case "EC":
+			key, err = parseECKey(jwk.Crv, jwk.X, jwk.Y)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("oidc jwks key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown id token key %q", kid)
+	}
+	return key, nil
+}
+
+func parseRSAKey(n string, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	exp := new(big.Int).SetBytes(exponent)
+	if !exp.IsInt64() || exp.Int64() > math.MaxInt32 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(exp.Int64())}, nil
+}
+
+func parseECKey(crv string, x string, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+	xBytes, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, err
+	}
+	yBytes, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, err
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return key, nil
+}
+
+func (s *oidcService) fetchUserInfo(ctx context.Context, endpoints *oidcEndpoints, accessToken string) (*oidcUserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoints.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info oidcUserInfo
+	if err := s.doJSON(req, &info); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("userinfo response is missing subject")
+	}
+
+	return &info, nil
+}
+
+// getEndpoints prefers explicitly configured endpoints and falls back to the
+// issuer's discovery document.
+func (s *oidcService) getEndpoints(ctx context.Context) (*oidcEndpoints, error) {
+	if config.Envs.OIDCClientID == "" {
+		return nil, errors.New("oidc login is not configured")
+	}
+
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+
+	if s.endpoints != nil {
+		return s.endpoints, nil
+	}
+
+	endpoints := &oidcEndpoints{
+		AuthURL:     config.Envs.OIDCAuthURL,
+		TokenURL:    config.Envs.OIDCTokenURL,
+		UserInfoURL: config.Envs.OIDCUserInfoURL,
+		JWKSURL:     config.Envs.OIDCJWKSURL,
+	}
+
+	if endpoints.AuthURL == "" || endpoints.TokenURL == "" || endpoints.UserInfoURL == "" || endpoints.JWKSURL == "" {
+		if config.Envs.OIDCIssuer == "" {
+			return nil, errors.New("oidc endpoints are not configured")
+		}
+
+		discoveryURL := strings.TrimSuffix(config.Envs.OIDCIssuer, "/") + "/.well-known/openid-configuration"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var discovered oidcEndpoints
+		if err := s.doJSON(req, &discovered); err != nil {
+			return nil, fmt.Errorf("oidc discovery failed: %w", err)
+		}
+
+		if endpoints.AuthURL == "" {
+			endpoints.AuthURL = discovered.AuthURL
+		}
+		if endpoints.TokenURL == "" {
+			endpoints.TokenURL = discovered.TokenURL
+		}
+		if endpoints.UserInfoURL == "" {
+			endpoints.UserInfoURL = discovered.UserInfoURL
+		}
+		if endpoints.JWKSURL == "" {
+			endpoints.JWKSURL = discovered.JWKSURL
+		}
+	}
+
+	s.endpoints = endpoints
+	return endpoints, nil
+}
+
+func (s *oidcService) doJSON(req *http.Request, target interface{}) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, target)
+}
+
+// encodeLoginState signs the login state for the cookie, so the callback can
+// trust it without keeping it on the server.
+func encodeLoginState(login *oidcLoginState) (string, error) {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + utils.Sign(config.Envs.SigningSecret, "oidc_login", payload), nil
+}
+
+func decodeLoginState(value string) (*oidcLoginState, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !utils.VerifySignature(config.Envs.SigningSecret, signature, "oidc_login", payload) {
+		return nil, errors.New("invalid login state")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var login oidcLoginState
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, err
+	}
+	if login.State == "" || time.Now().UnixMilli() > login.ExpiresAt {
+		return nil, errors.New("login state expired")
+	}
+	return &login, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"nuxatech-nextmedis/config"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/model"
@@ -15,25 +16,76 @@ import (
 	"slices"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, userID string, req *request.CreateOrderRequest) (*response.OrderResponse, error)
 	GetOrder(ctx context.Context, userID, orderID string) (*response.OrderResponse, error)
-	UpdateOrderStatus(ctx context.Context, userID, orderID string, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error)
+	UpdateOrderStatus(ctx context.Context, userID, orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error)
+	AdminGetOrder(ctx context.Context, orderID string) (*response.OrderResponse, error)
+	AdminUpdateOrderStatus(ctx context.Context, orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error)
 	GetUserOrders(ctx context.Context, userID string, params ProductQueryParams) (*response.OrderPagingResponse, error)
+	Run(ctx context.Context)
 }
 
+const (
+	orderExpiryInterval = time.Minute
+	orderExpiryBatch    = 100
+)
+
 type orderService struct {
-	orderRepo   repository.OrderRepository
-	cartRepo    repository.CartRepository
-	productRepo repository.ProductRepository
-	accountRepo repository.AccountRepository
-	validate    *validator.Validate
-	mutex       sync.Mutex
+	orderRepo         repository.OrderRepository
+	cartRepo          repository.CartRepository
+	productRepo       repository.ProductRepository
+	variantRepo       repository.ProductVariantRepository
+	stockRepo         repository.StockMovementRepository
+	reservationRepo   repository.StockReservationRepository
+	warehouseRepo     repository.WarehouseRepository
+	codeRepo          repository.ProductCodeRepository
+	accountRepo       repository.AccountRepository
+	addressRepo       repository.AddressRepository
+	auditService      AuditService
+	stockAlertService StockAlertService
+	validate          *validator.Validate
+	mutex             sync.Mutex
+}
+
+// UpdateOrderStatus lets the owner cancel a pending order. Payment,
+// shipping and completion are set by admins through AdminUpdateOrderStatus,
+// as paying hands out stock and license codes.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, userID, orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+	return s.recordOrderStatusUpdate(ctx, userID, orderID, version, req)
+}
+
+// AdminUpdateOrderStatus moves any order to any status.
+func (s *orderService) AdminUpdateOrderStatus(ctx context.Context, orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+	return s.recordOrderStatusUpdate(ctx, "", orderID, version, req)
+}
+
+func (s *orderService) recordOrderStatusUpdate(ctx context.Context, userID, orderID string, version int64, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+	event := &model.AuditEvent{
+		Action:     model.AuditActionOrderStatusUpdate,
+		TargetType: "order",
+		TargetID:   orderID,
+		Metadata:   model.AuditMetadata{"to": req.Status},
+	}
+
+	order, err := s.updateOrderStatus(ctx, userID, orderID, version, req, event.Metadata)
+
+	event.Outcome = auditOutcome(err)
+	if err != nil {
+		event.Metadata["error"] = err.Error()
+	}
+	s.auditService.Record(ctx, event)
+
+	return order, err
 }
 
-func (s *orderService) UpdateOrderStatus(ctx context.Context, userID, orderID string, req *request.UpdateOrderStatusRequest) (*response.OrderResponse, error) {
+// updateOrderStatus changes the status of the order of userID, or of any
+// order when userID is empty.
+func (s *orderService) updateOrderStatus(ctx context.Context, userID, orderID string, version int64, req *request.UpdateOrderStatusRequest, metadata model.AuditMetadata) (*response.OrderResponse, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return nil, err
 	}
@@ -43,10 +95,29 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, userID, orderID st
 		return nil, err
 	}
 
-	if order.UserID != userID {
-		return nil, errors.New("unauthorized")
+	if userID != "" {
+		if order.UserID != userID {
+			return nil, errors.New("unauthorized")
+		}
+		if req.Status != string(model.OrderStatusCanceled) || order.Status != model.OrderStatusPending {
+			return nil, errors.New("only a pending order can be canceled")
+		}
+	}
+	if order.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	// Canceling puts the items back in stock, so it cannot be undone.
+	if order.Status == model.OrderStatusCanceled {
+		return nil, errors.New("order is canceled")
+	}
+	if order.Status == model.OrderStatusPending && req.Status != string(model.OrderStatusCanceled) &&
+		order.ExpiresAt != nil && *order.ExpiresAt < time.Now().UnixMilli() {
+		return nil, errors.New("payment deadline has passed")
 	}
+	wasPending := order.Status == model.OrderStatusPending
 
+	metadata["from"] = string(order.Status)
 	now := time.Now().UnixMilli()
 	order.Status = model.OrderStatus(req.Status)
 	order.UpdatedAt = now
@@ -54,11 +125,233 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, userID, orderID st
 	if req.Status == "paid" {
 		order.PaidAt = &now
 	}
+	if order.Status != model.OrderStatusPending {
+		order.ExpiresAt = nil
+	}
 
-	if err := s.orderRepo.UpdateOrder(ctx, order); err != nil {
+	tx := s.orderRepo.BeginTx(ctx)
+	if tx == nil {
+		return nil, errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	if err := s.orderRepo.UpdateOrder(ctx, tx, order); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionMismatch
+		}
 		return nil, err
 	}
 
+	if order.Status == model.OrderStatusCanceled {
+		if err := s.releaseOrderStock(ctx, tx, order); err != nil {
+			return nil, err
+		}
+	} else if wasPending {
+		if err := s.commitOrderStock(ctx, tx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.toOrderResponse(order), nil
+}
+
+// commitOrderStock turns the active reservations of an order into stock
+// movements once it leaves pending, and assigns digital items their codes.
+func (s *orderService) commitOrderStock(ctx context.Context, tx *gorm.DB, order *model.Order) error {
+	reservations, err := s.reservationRepo.GetOrderReservations(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	items := make(map[string][]*model.OrderItem, len(order.Items))
+	for i := range order.Items {
+		item := &order.Items[i]
+		key := stockKey(item.ProductID, item.VariantID, item.WarehouseID)
+		items[key] = append(items[key], item)
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status != model.StockReservationActive {
+			continue
+		}
+		movement := newStockMovement(ctx, -reservation.Quantity, model.StockReasonOrderPlaced, "order", order.ID)
+		movement.ProductID = reservation.ProductID
+		movement.VariantID = reservation.VariantID
+		movement.WarehouseID = reservation.WarehouseID
+		if err := s.stockRepo.RecordMovement(ctx, tx, movement); err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return fmt.Errorf("insufficient stock for product %s", reservation.ProductID)
+			}
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		if err := s.reservationRepo.CloseReservation(ctx, tx, reservation, model.StockReservationCommitted); err != nil {
+			return err
+		}
+
+		key := stockKey(reservation.ProductID, reservation.VariantID, reservation.WarehouseID)
+		held := items[key]
+		if len(held) == 0 {
+			continue
+		}
+		item := held[0]
+		items[key] = held[1:]
+		if item.Product.UniqueCodeType == "" {
+			continue
+		}
+		codes, err := s.codeRepo.AssignCodes(ctx, tx, item, reservation.Quantity)
+		if err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return fmt.Errorf("not enough codes left for product %s", reservation.ProductID)
+			}
+			return fmt.Errorf("failed to assign codes: %w", err)
+		}
+		item.Codes = codes
+	}
+	return nil
+}
+
+// releaseOrderStock gives back the stock of a canceled order. Items still held
+// by a reservation only release it, the others were taken from stock and are
+// put back, except digital items whose codes are voided instead.
+func (s *orderService) releaseOrderStock(ctx context.Context, tx *gorm.DB, order *model.Order) error {
+	reservations, err := s.reservationRepo.GetOrderReservations(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[string][]*model.StockReservation, len(reservations))
+	for _, reservation := range reservations {
+		if reservation.Status == model.StockReservationActive {
+			key := stockKey(reservation.ProductID, reservation.VariantID, reservation.WarehouseID)
+			active[key] = append(active[key], reservation)
+		}
+	}
+
+	for i, item := range order.Items {
+		key := stockKey(item.ProductID, item.VariantID, item.WarehouseID)
+		if held := active[key]; len(held) > 0 {
+			reservation := held[0]
+			active[key] = held[1:]
+			if err := s.reservationRepo.CloseReservation(ctx, tx, reservation, model.StockReservationReleased); err != nil {
+				return fmt.Errorf("failed to release reservation of product %s: %w", item.ProductID, err)
+			}
+			continue
+		}
+
+		voided, err := s.codeRepo.VoidOrderItemCodes(ctx, tx, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to void codes of product %s: %w", item.ProductID, err)
+		}
+		if voided > 0 {
+			order.Items[i].Codes = nil
+			continue
+		}
+
+		movement := newStockMovement(ctx, item.Quantity, model.StockReasonOrderCanceled, "order", order.ID)
+		movement.ProductID = item.ProductID
+		movement.VariantID = item.VariantID
+		movement.WarehouseID = item.WarehouseID
+		if err := s.stockRepo.RecordMovement(ctx, tx, movement); err != nil {
+			return fmt.Errorf("failed to restock product %s: %w", item.ProductID, err)
+		}
+	}
+	return nil
+}
+
+// Run cancels pending orders that were not paid before their deadline until
+// ctx is done, releasing the stock they reserved.
+func (s *orderService) Run(ctx context.Context) {
+	s.expireOrders(ctx)
+
+	ticker := time.NewTicker(orderExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireOrders(ctx)
+		}
+	}
+}
+
+func (s *orderService) expireOrders(ctx context.Context) {
+	for {
+		orders, err := s.orderRepo.FindExpiredOrders(ctx, time.Now().UnixMilli(), orderExpiryBatch)
+		if err != nil {
+			log.Printf("Failed to load expired orders: %v", err)
+			return
+		}
+
+		for _, order := range orders {
+			if err := s.expireOrder(ctx, order); err != nil {
+				log.Printf("Failed to expire order %s: %v", order.ID, err)
+				// The order stays pending and is retried on the next tick.
+				return
+			}
+		}
+		if len(orders) < orderExpiryBatch {
+			return
+		}
+	}
+}
+
+func (s *orderService) expireOrder(ctx context.Context, order *model.Order) error {
+	tx := s.orderRepo.BeginTx(ctx)
+	if tx == nil {
+		return errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	order.Status = model.OrderStatusCanceled
+	order.UpdatedAt = time.Now().UnixMilli()
+	order.ExpiresAt = nil
+
+	if err := s.orderRepo.UpdateOrder(ctx, tx, order); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Paid or canceled in the meantime.
+			return nil
+		}
+		return err
+	}
+	if err := s.releaseOrderStock(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionOrderExpire,
+		TargetType: "order",
+		TargetID:   order.ID,
+		Metadata:   model.AuditMetadata{"from": string(model.OrderStatusPending), "reason": "payment_expired"},
+	})
+	return nil
+}
+
+func stockKey(productID string, variantID *string, warehouseID *string) string {
+	key := productID
+	if variantID != nil {
+		key += "/" + *variantID
+	}
+	if warehouseID != nil {
+		key += "@" + *warehouseID
+	}
+	return key
+}
+
+// AdminGetOrder returns any order.
+func (s *orderService) AdminGetOrder(ctx context.Context, orderID string) (*response.OrderResponse, error) {
+	order, err := s.orderRepo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
 	return s.toOrderResponse(order), nil
 }
 
@@ -83,13 +376,17 @@ func (s *orderService) GetUserOrders(ctx context.Context, userID string, params
 		params.Limit = 10
 	}
 
+	if params.cursorMode() {
+		return s.getUserOrdersByCursor(ctx, userID, params)
+	}
+
 	orders, total, err := s.orderRepo.GetUserOrders(ctx, userID, params.Page, params.Limit)
 	if err != nil {
 		return nil, err
 	}
 
 	return &response.OrderPagingResponse{
-		Metadata: response.Metadata{
+		Metadata: &response.Metadata{
 			TotalCount: int(total),
 			Page:       params.Page,
 			PerPage:    params.Limit,
@@ -98,7 +395,55 @@ func (s *orderService) GetUserOrders(ctx context.Context, userID string, params
 	}, nil
 }
 
+func (s *orderService) getUserOrdersByCursor(ctx context.Context, userID string, params ProductQueryParams) (*response.OrderPagingResponse, error) {
+	cursor, err := params.decode()
+	if err != nil {
+		return nil, err
+	}
+	limit := cursorLimit(params.Limit)
+
+	orders, page, err := s.orderRepo.GetUserOrdersAfter(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IncludeTotal {
+		count, err := s.orderRepo.CountUserOrders(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return &response.OrderPagingResponse{
+		Cursor: toCursorMetadata(page, limit, total),
+		Result: orders,
+	}, nil
+}
+
 func (s *orderService) CreateOrder(ctx context.Context, userID string, req *request.CreateOrderRequest) (*response.OrderResponse, error) {
+	order, err := s.createOrder(ctx, userID, req)
+
+	event := &model.AuditEvent{
+		Action:     model.AuditActionOrderCreate,
+		TargetType: "order",
+		Outcome:    auditOutcome(err),
+		Metadata:   model.AuditMetadata{"cart_items": req.SelectedItems},
+	}
+	if order != nil {
+		event.TargetID = order.ID
+		event.Metadata["total_amount"] = order.TotalAmount
+	}
+	if err != nil {
+		event.Metadata["error"] = err.Error()
+	}
+	s.auditService.Record(ctx, event)
+
+	return order, err
+}
+
+func (s *orderService) createOrder(ctx context.Context, userID string, req *request.CreateOrderRequest) (*response.OrderResponse, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return nil, err
 	}
@@ -126,6 +471,11 @@ func (s *orderService) CreateOrder(ctx context.Context, userID string, req *requ
 		return nil, errors.New("some selected items were not found in cart")
 	}
 
+	shippingAddress, err := s.resolveShippingAddress(ctx, userID, req.AddressID)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := s.orderRepo.BeginTx(ctx)
 	if tx == nil {
 		return nil, errors.New("failed to start transaction")
@@ -134,7 +484,15 @@ func (s *orderService) CreateOrder(ctx context.Context, userID string, req *requ
 
 	var totalAmount int64
 	now := time.Now().UnixMilli()
-	orderItems := make([]model.OrderItem, len(selectedItems))
+	lineItems := make([]model.OrderItem, len(selectedItems))
+	lines := make([]allocationLine, len(selectedItems))
+	// The ID is known up front so reservations can reference the order.
+	orderID := uuid.New().String()
+	// Stock is only held until the payment deadline, it is taken once paid.
+	expiresAt := now + int64(config.Envs.ReservationTTL)*1000
+	// Products as locked before the order, and how much of each it takes.
+	ordered := make(map[string]*model.Product)
+	orderedQuantity := make(map[string]int)
 
 	for i, item := range selectedItems {
 		product, err := s.productRepo.GetProductForUpdate(ctx, tx, item.ProductID)
@@ -142,42 +500,99 @@ func (s *orderService) CreateOrder(ctx context.Context, userID string, req *requ
 			return nil, err
 		}
 
-		if product.Stock < item.Quantity {
+		// Products sold in variants keep stock per variant, lock that row too.
+		var variant *model.ProductVariant
+		if item.VariantID != nil {
+			variant, err = s.variantRepo.GetVariantForUpdate(ctx, tx, *item.VariantID)
+			if err != nil {
+				return nil, fmt.Errorf("variant of product %s is no longer available", product.Name)
+			}
+		} else if product.VariantCount > 0 {
+			return nil, fmt.Errorf("select a variant for product: %s", product.Name)
+		}
+
+		stock := availableStock(product, variant)
+		if stock < item.Quantity {
 			return nil, fmt.Errorf("insufficient stock for product: %s (available: %d, requested: %d)",
-				product.Name, product.Stock, item.Quantity)
+				product.Name, stock, item.Quantity)
 		}
 
-		price := int64(product.Price)
+		price := int64(unitPrice(product, variant))
 		quantity := item.Quantity
 		itemTotal := price * int64(quantity)
+		if _, ok := ordered[product.ID]; !ok {
+			ordered[product.ID] = product
+		}
+		orderedQuantity[product.ID] += quantity
 
-		orderItems[i] = model.OrderItem{
+		lineItems[i] = model.OrderItem{
 			ProductID: item.ProductID,
 			Product:   *product,
+			VariantID: item.VariantID,
+			Variant:   variant,
 			Quantity:  quantity,
 			Price:     price,
 			CreatedAt: now,
 		}
 		totalAmount += itemTotal
 
-		if err := s.productRepo.UpdateStock(ctx, tx, product.ID, product.Stock-quantity); err != nil {
-			return nil, fmt.Errorf("failed to update stock: %w", err)
+		levels, err := s.warehouseRepo.GetStockLevels(ctx, tx, product.ID, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = allocationLine{
+			name:     product.Name,
+			key:      stockKey(product.ID, item.VariantID, nil),
+			quantity: quantity,
+			levels:   levels,
+		}
+	}
+
+	allocations, err := allocateStock(config.Envs.Allocation, shippingAddress, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems := make([]model.OrderItem, len(allocations))
+	reservations := make([]*model.StockReservation, len(allocations))
+	for i, allocated := range allocations {
+		warehouseID := allocated.warehouseID
+		orderItems[i] = lineItems[allocated.line]
+		orderItems[i].Quantity = allocated.quantity
+		orderItems[i].WarehouseID = &warehouseID
+
+		reservations[i] = &model.StockReservation{
+			OrderID:     orderID,
+			ProductID:   orderItems[i].ProductID,
+			VariantID:   orderItems[i].VariantID,
+			WarehouseID: &warehouseID,
+			Quantity:    allocated.quantity,
+			Status:      model.StockReservationActive,
+			ExpiresAt:   expiresAt,
+			CreatedAt:   now,
 		}
 	}
 
 	order := &model.Order{
-		UserID:      userID,
-		CartID:      cart.ID,
-		Status:      model.OrderStatusPending,
-		TotalAmount: totalAmount,
-		Items:       orderItems,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:              orderID,
+		UserID:          userID,
+		CartID:          cart.ID,
+		Status:          model.OrderStatusPending,
+		TotalAmount:     totalAmount,
+		Items:           orderItems,
+		ShippingAddress: shippingAddress,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		ExpiresAt:       &expiresAt,
+		Version:         1,
 	}
 
 	if err := s.orderRepo.CreateOrder(ctx, tx, order); err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	if err := s.reservationRepo.CreateReservations(ctx, tx, reservations); err != nil {
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	}
 
 	for _, itemID := range req.SelectedItems {
 		if err := s.cartRepo.RemoveItem(ctx, itemID); err != nil {
@@ -189,28 +604,66 @@ func (s *orderService) CreateOrder(ctx context.Context, userID string, req *requ
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	for productID, product := range ordered {
+		before := product.Stock - product.Reserved
+		s.stockAlertService.ReportStockDrop(ctx, product, before, before-orderedQuantity[productID])
+	}
+
 	return s.toOrderResponse(order), nil
 }
 
+// resolveShippingAddress snapshots the chosen address, or the default one when
+// none is given. Orders without any address book entry ship nowhere.
+func (s *orderService) resolveShippingAddress(ctx context.Context, userID string, addressID string) (*model.ShippingAddress, error) {
+	if addressID != "" {
+		address, err := s.addressRepo.GetAddress(ctx, userID, addressID)
+		if err != nil {
+			return nil, errors.New("address not found")
+		}
+		return address.Snapshot(), nil
+	}
+
+	address, err := s.addressRepo.GetDefaultAddress(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return address.Snapshot(), nil
+}
+
 func (s *orderService) toOrderResponse(order *model.Order) *response.OrderResponse {
 	items := make([]response.OrderItemResponse, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = response.OrderItemResponse{
-			ID:       item.ID,
-			Product:  toProductResponse(item.Product),
-			Quantity: item.Quantity,
-			Price:    item.Price,
+			ID:          item.ID,
+			Product:     toProductResponse(item.Product),
+			Variant:     item.Variant,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+			WarehouseID: item.WarehouseID,
+		}
+		for _, code := range item.Codes {
+			items[i].Codes = append(items[i].Codes, response.OrderItemCodeResponse{
+				ID:         code.ID,
+				Code:       code.Code,
+				AssignedAt: code.AssignedAt,
+			})
 		}
 	}
 
 	return &response.OrderResponse{
-		ID:          order.ID,
-		Status:      string(order.Status),
-		TotalAmount: order.TotalAmount,
-		Items:       items,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
-		PaidAt:      order.PaidAt,
+		ID:              order.ID,
+		Status:          string(order.Status),
+		TotalAmount:     order.TotalAmount,
+		Items:           items,
+		ShippingAddress: order.ShippingAddress,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+		PaidAt:          order.PaidAt,
+		ExpiresAt:       order.ExpiresAt,
+		Version:         order.Version,
 	}
 }
 
@@ -218,13 +671,29 @@ func NewOrderService(
 	orderRepo repository.OrderRepository,
 	cartRepo repository.CartRepository,
 	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	stockRepo repository.StockMovementRepository,
+	reservationRepo repository.StockReservationRepository,
+	warehouseRepo repository.WarehouseRepository,
+	codeRepo repository.ProductCodeRepository,
 	accountRepo repository.AccountRepository,
+	addressRepo repository.AddressRepository,
+	auditService AuditService,
+	stockAlertService StockAlertService,
 ) OrderService {
 	return &orderService{
-		orderRepo:   orderRepo,
-		cartRepo:    cartRepo,
-		productRepo: productRepo,
-		accountRepo: accountRepo,
-		validate:    validator.New(),
+		orderRepo:         orderRepo,
+		cartRepo:          cartRepo,
+		productRepo:       productRepo,
+		variantRepo:       variantRepo,
+		stockRepo:         stockRepo,
+		reservationRepo:   reservationRepo,
+		warehouseRepo:     warehouseRepo,
+		codeRepo:          codeRepo,
+		accountRepo:       accountRepo,
+		addressRepo:       addressRepo,
+		auditService:      auditService,
+		stockAlertService: stockAlertService,
+		validate:          validator.New(),
 	}
 }
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+)
+
+// maxCursorLimit caps the page size of cursor-paginated listings.
+const maxCursorLimit = 100
+
+// CursorParams switch a listing from page numbers to keyset pagination.
+// Cursor is a next_cursor or prev_cursor from a previous page, empty for the
+// first page. Counting the total is opt-in since it scans the whole set.
+type CursorParams struct {
+	UseCursor    bool
+	Cursor       string
+	IncludeTotal bool
+}
+
+func (p CursorParams) cursorMode() bool {
+	return p.UseCursor || p.Cursor != ""
+}
+
+func (p CursorParams) decode() (*utils.Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+	return utils.DecodeCursor(p.Cursor)
+}
+
+func cursorLimit(limit int) int {
+	if limit < 1 {
+		return 10
+	}
+	return min(limit, maxCursorLimit)
+}
+
+func toCursorMetadata(page *repository.CursorPage, limit int, total *int64) *response.CursorMetadata {
+	metadata := &response.CursorMetadata{
+		NextCursor: page.Next,
+		PrevCursor: page.Prev,
+		Limit:      limit,
+	}
+	if total != nil {
+		count := int(*total)
+		metadata.TotalCount = &count
+	}
+	return metadata
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type PriceService interface {
+	GetPriceHistory(ctx context.Context, productID string, params PriceHistoryParams) (*response.PriceChangePagingResponse, error)
+	GetSchedules(ctx context.Context, productID string, all bool) ([]*model.PriceSchedule, error)
+	CreateSchedule(ctx context.Context, productID string, req request.CreatePriceScheduleRequest) (*model.PriceSchedule, error)
+	CancelSchedule(ctx context.Context, productID string, id string) (*model.PriceSchedule, error)
+	Run(ctx context.Context)
+}
+
+type priceService struct {
+	priceRepo    repository.PriceRepository
+	productRepo  repository.ProductRepository
+	variantRepo  repository.ProductVariantRepository
+	auditService AuditService
+	validate     *validator.Validate
+}
+
+type PriceHistoryParams struct {
+	Page      int
+	Limit     int
+	VariantID string
+}
+
+const (
+	priceSweepInterval = time.Minute
+	priceSweepBatch    = 100
+)
+
+func NewPriceService(
+	priceRepo repository.PriceRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	auditService AuditService,
+) PriceService {
+	return &priceService{
+		priceRepo:    priceRepo,
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		auditService: auditService,
+		validate:     validator.New(),
+	}
+}
+
+// GetPriceHistory lists the price changes of a product, newest first,
+// including those of its variants.
+func (s *priceService) GetPriceHistory(ctx context.Context, productID string, params PriceHistoryParams) (*response.PriceChangePagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	changes, total, err := s.priceRepo.GetPriceHistory(ctx, productID, params.VariantID, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.PriceChangePagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: changes,
+	}, nil
+}
+
+// GetSchedules lists the upcoming and running price schedules of a product,
+// or all of them including past ones.
+func (s *priceService) GetSchedules(ctx context.Context, productID string, all bool) ([]*model.PriceSchedule, error) {
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return s.priceRepo.GetSchedules(ctx, productID, all)
+}
+
+// CreateSchedule plans a price change. Schedules of the same product or
+// variant cannot overlap. One starting now or in the past is applied at once.
+func (s *priceService) CreateSchedule(ctx context.Context, productID string, req request.CreatePriceScheduleRequest) (*model.PriceSchedule, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	if req.EndsAt != nil && *req.EndsAt <= now {
+		return nil, errors.New("ends_at must be in the future")
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	if req.VariantID != nil {
+		if _, err := s.variantRepo.GetVariant(ctx, productID, *req.VariantID); err != nil {
+			return nil, errors.New("variant not found")
+		}
+	} else if product.VariantCount > 0 {
+		return nil, errors.New("a product with variants is priced per variant")
+	}
+
+	schedule := &model.PriceSchedule{
+		ProductID: productID,
+		VariantID: req.VariantID,
+		Price:     req.Price,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Status:    model.PriceScheduleStatusScheduled,
+		CreatedAt: now,
+	}
+	if meta := utils.RequestMetaFromContext(ctx); meta != nil && meta.ActorID != "" {
+		actorID := meta.ActorID
+		schedule.CreatedBy = &actorID
+	}
+
+	overlaps, err := s.priceRepo.ScheduleOverlaps(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, errors.New("another price schedule is set for this time")
+	}
+
+	if err := s.priceRepo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionPriceSchedule,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata: model.AuditMetadata{
+			"schedule_id": schedule.ID,
+			"variant_id":  schedule.VariantID,
+			"price":       schedule.Price,
+			"starts_at":   schedule.StartsAt,
+			"ends_at":     schedule.EndsAt,
+		},
+	})
+
+	if schedule.StartsAt > now {
+		return schedule, nil
+	}
+	if err := s.priceRepo.StartSchedule(ctx, schedule.ID, now); err != nil {
+		log.Printf("Failed to start price schedule %s: %v", schedule.ID, err)
+		return schedule, nil
+	}
+	return s.priceRepo.GetSchedule(ctx, productID, schedule.ID)
+}
+
+// CancelSchedule drops a schedule that has not started, or ends a running
+// sale early.
+func (s *priceService) CancelSchedule(ctx context.Context, productID string, id string) (*model.PriceSchedule, error) {
+	schedule, err := s.priceRepo.GetSchedule(ctx, productID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("price schedule not found")
+		}
+		return nil, err
+	}
+
+	switch schedule.Status {
+	case model.PriceScheduleStatusScheduled:
+		canceled, err := s.priceRepo.CancelSchedule(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !canceled {
+			return nil, errors.New("price schedule already started")
+		}
+	case model.PriceScheduleStatusActive:
+		if err := s.priceRepo.EndSchedule(ctx, id, time.Now().UnixMilli()); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("price schedule already ended")
+			}
+			return nil, err
+		}
+	default:
+		return nil, errors.New("price schedule already ended")
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionPriceScheduleCancel,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata: model.AuditMetadata{
+			"schedule_id": id,
+			"status":      schedule.Status,
+		},
+	})
+
+	return s.priceRepo.GetSchedule(ctx, productID, id)
+}
+
+// Run applies due price schedules until ctx is done.
+func (s *priceService) Run(ctx context.Context) {
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(priceSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep ends due sales before starting due schedules, so a sale can follow
+// another one ending at the same time.
+func (s *priceService) sweep(ctx context.Context) {
+	for {
+		schedules, err := s.priceRepo.FindSchedulesToEnd(ctx, time.Now().UnixMilli(), priceSweepBatch)
+		if err != nil {
+			log.Printf("Failed to load sales to end: %v", err)
+			return
+		}
+		for _, schedule := range schedules {
+			err := s.priceRepo.EndSchedule(ctx, schedule.ID, time.Now().UnixMilli())
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Failed to end price schedule %s: %v", schedule.ID, err)
+				return
+			}
+		}
+		if len(schedules) < priceSweepBatch {
+			break
+		}
+	}
+
+	for {
+		schedules, err := s.priceRepo.FindSchedulesToStart(ctx, time.Now().UnixMilli(), priceSweepBatch)
+		if err != nil {
+			log.Printf("Failed to load price schedules to start: %v", err)
+			return
+		}
+		for _, schedule := range schedules {
+			err := s.priceRepo.StartSchedule(ctx, schedule.ID, time.Now().UnixMilli())
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Failed to start price schedule %s: %v", schedule.ID, err)
+				return
+			}
+		}
+		if len(schedules) < priceSweepBatch {
+			return
+		}
+	}
+}
+
+// newPriceChange builds a price history entry made by the current actor,
+// attributed to the import on ctx if any. The repository fills in the new
+// price.
+func newPriceChange(ctx context.Context, reason string, previous *int) *model.PriceChange {
+	change := &model.PriceChange{
+		Reason:        reason,
+		PreviousPrice: previous,
+	}
+	if source, ok := ctx.Value(stockSourceKey{}).(stockSource); ok && source.reason == model.StockReasonImport {
+		change.Reason = model.PriceReasonImport
+		change.ReferenceType = source.referenceType
+		change.ReferenceID = source.referenceID
+	}
+	if meta := utils.RequestMetaFromContext(ctx); meta != nil && meta.ActorID != "" {
+		actorID := meta.ActorID
+		change.ActorID = &actorID
+	}
+	return change
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type ProductCodeService interface {
+	UploadCodes(ctx context.Context, productID string, req request.UploadProductCodesRequest) (*response.ProductCodeUploadResponse, error)
+	UploadCodeFile(ctx context.Context, productID string, file *multipart.FileHeader) (*response.ProductCodeUploadResponse, error)
+	GetCodes(ctx context.Context, productID string, params ProductCodeParams) (*response.ProductCodePagingResponse, error)
+	VoidCode(ctx context.Context, productID string, id string) (*response.ProductCodeResponse, error)
+	ReissueCode(ctx context.Context, productID string, id string) (*response.ProductCodeResponse, error)
+}
+
+type productCodeService struct {
+	codeRepo      repository.ProductCodeRepository
+	productRepo   repository.ProductRepository
+	warehouseRepo repository.WarehouseRepository
+	auditService  AuditService
+	validate      *validator.Validate
+}
+
+type ProductCodeParams struct {
+	Page   int
+	Limit  int
+	Status string
+}
+
+const (
+	maxCodesPerUpload = 10000
+	// visibleCodeChars is how many trailing characters of a code admins see.
+	visibleCodeChars = 4
+)
+
+func NewProductCodeService(
+	codeRepo repository.ProductCodeRepository,
+	productRepo repository.ProductRepository,
+	warehouseRepo repository.WarehouseRepository,
+	auditService AuditService,
+) ProductCodeService {
+	return &productCodeService{
+		codeRepo:      codeRepo,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		auditService:  auditService,
+		validate:      validator.New(),
+	}
+}
+
+// UploadCodes adds codes to the pool of a digital product, each one adding a
+// unit of stock.
+func (s *productCodeService) UploadCodes(ctx context.Context, productID string, req request.UploadProductCodesRequest) (*response.ProductCodeUploadResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+	return s.addCodes(ctx, productID, req.Codes)
+}
+
+// UploadCodeFile adds the codes of a text file, one per line, to the pool of
+// a digital product. Blank lines are ignored.
+func (s *productCodeService) UploadCodeFile(ctx context.Context, productID string, file *multipart.FileHeader) (*response.ProductCodeUploadResponse, error) {
+	if file.Size > int64(config.Envs.UploadMaxBytes) {
+		return nil, fmt.Errorf("file exceeds the %d byte limit", config.Envs.UploadMaxBytes)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var codes []string
+	scanner := bufio.NewScanner(io.LimitReader(f, int64(config.Envs.UploadMaxBytes)))
+	for scanner.Scan() {
+		code := strings.TrimSpace(scanner.Text())
+		if code == "" {
+			continue
+		}
+		if len(code) > 255 {
+			return nil, fmt.Errorf("line %d: code is longer than 255 characters", len(codes)+1)
+		}
+		codes = append(codes, code)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return s.addCodes(ctx, productID, codes)
+}
+
+func (s *productCodeService) addCodes(ctx context.Context, productID string, codes []string) (*response.ProductCodeUploadResponse, error) {
+	if len(codes) == 0 {
+		return nil, errors.New("no codes uploaded")
+	}
+	if len(codes) > maxCodesPerUpload {
+		return nil, fmt.Errorf("at most %d codes can be uploaded at once", maxCodesPerUpload)
+	}
+
+	if _, err := s.getDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	seen := make(map[string]bool, len(codes))
+	pool := make([]*model.ProductCode, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		pool = append(pool, &model.ProductCode{
+			ProductID: productID,
+			Code:      code,
+			Status:    model.ProductCodeAvailable,
+			CreatedAt: now,
+		})
+	}
+
+	movement := newStockMovement(ctx, 0, model.StockReasonCodeUpload, "", "")
+	movement.ProductID = productID
+	added, err := s.codeRepo.AddCodes(ctx, pool, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionCodeUpload,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"added": added, "duplicates": len(codes) - added},
+	})
+
+	return &response.ProductCodeUploadResponse{
+		Added:      added,
+		Duplicates: len(codes) - added,
+		Stock:      product.Stock,
+	}, nil
+}
+
+// GetCodes lists the codes of a digital product, newest first, masked.
+func (s *productCodeService) GetCodes(ctx context.Context, productID string, params ProductCodeParams) (*response.ProductCodePagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	if _, err := s.getDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	codes, total, err := s.codeRepo.GetCodes(ctx, productID, params.Status, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.ProductCodeResponse, len(codes))
+	for i, code := range codes {
+		result[i] = toProductCodeResponse(code)
+	}
+
+	return &response.ProductCodePagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: result,
+	}, nil
+}
+
+// VoidCode withdraws an available code from the pool, taking a unit out of
+// stock. Assigned codes are voided by canceling their order or reissuing them.
+func (s *productCodeService) VoidCode(ctx context.Context, productID string, id string) (*response.ProductCodeResponse, error) {
+	code, err := s.getCode(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if code.Status != model.ProductCodeAvailable {
+		return nil, fmt.Errorf("only available codes can be voided, this one is %s", code.Status)
+	}
+
+	warehouseID, err := s.codeWarehouse(ctx, productID)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return nil, errors.New("every available code is held by a pending order")
+	}
+	if err != nil {
+		return nil, err
+	}
+	movement := newStockMovement(ctx, -1, model.StockReasonCodeVoid, "product_code", code.ID)
+	movement.ProductID = productID
+	movement.WarehouseID = warehouseID
+
+	if err := s.codeRepo.VoidCode(ctx, code, movement); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("code is no longer available")
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("every available code is held by a pending order")
+		}
+		return nil, err
+	}
+	code.Status = model.ProductCodeVoided
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionCodeVoid,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"code_id": code.ID},
+	})
+
+	result := toProductCodeResponse(code)
+	return &result, nil
+}
+
+// ReissueCode voids an assigned code, for instance one the buyer could not
+// redeem, and assigns its order item a replacement from the pool.
+func (s *productCodeService) ReissueCode(ctx context.Context, productID string, id string) (*response.ProductCodeResponse, error) {
+	code, err := s.getCode(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if code.Status != model.ProductCodeAssigned {
+		return nil, fmt.Errorf("only assigned codes can be reissued, this one is %s", code.Status)
+	}
+
+	warehouseID, err := s.codeWarehouse(ctx, productID)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return nil, errors.New("no code available to reissue")
+	}
+	if err != nil {
+		return nil, err
+	}
+	movement := newStockMovement(ctx, -1, model.StockReasonCodeReissue, "order", *code.OrderID)
+	movement.ProductID = productID
+	movement.WarehouseID = warehouseID
+
+	replacement, err := s.codeRepo.ReissueCode(ctx, code, movement)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("code is no longer assigned")
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("no code available to reissue")
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionCodeReissue,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata: model.AuditMetadata{
+			"code_id":        code.ID,
+			"replacement_id": replacement.ID,
+			"order_id":       *code.OrderID,
+		},
+	})
+
+	result := toProductCodeResponse(replacement)
+	return &result, nil
+}
+
+func (s *productCodeService) getDigitalProduct(ctx context.Context, productID string) (*model.Product, error) {
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	if product.UniqueCodeType == "" {
+		return nil, errors.New("product is not digital")
+	}
+	return product, nil
+}
+
+func (s *productCodeService) getCode(ctx context.Context, productID string, id string) (*model.ProductCode, error) {
+	if _, err := s.getDigitalProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	code, err := s.codeRepo.GetCode(ctx, productID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("code not found")
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+// codeWarehouse picks the warehouse holding the most unreserved stock of a
+// digital product. Codes are not tied to a warehouse, but its stock is, and
+// codes held for pending orders cannot be taken.
+func (s *productCodeService) codeWarehouse(ctx context.Context, productID string) (*string, error) {
+	levels, err := s.warehouseRepo.GetStockLevels(ctx, nil, productID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var best *model.WarehouseStock
+	for _, level := range levels {
+		if best == nil || level.Available > best.Available {
+			best = level
+		}
+	}
+	if best == nil || best.Available < 1 {
+		return nil, repository.ErrInsufficientStock
+	}
+	return &best.WarehouseID, nil
+}
+
+func toProductCodeResponse(code *model.ProductCode) response.ProductCodeResponse {
+	return response.ProductCodeResponse{
+		ID:          code.ID,
+		Code:        maskCode(code.Code),
+		Status:      code.Status,
+		OrderID:     code.OrderID,
+		OrderItemID: code.OrderItemID,
+		ReplacesID:  code.ReplacesID,
+		CreatedAt:   code.CreatedAt,
+		AssignedAt:  code.AssignedAt,
+		VoidedAt:    code.VoidedAt,
+	}
+}
+
+// maskCode hides all but the last characters of a code, so admin listings
+// cannot leak codes that were or will be sold.
+func maskCode(code string) string {
+	runes := []rune(code)
+	if len(runes) <= visibleCodeChars {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visibleCodeChars) + string(runes[len(runes)-visibleCodeChars:])
+}
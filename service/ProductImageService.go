@@ -0,0 +1,376 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/filestore"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"slices"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductImageService interface {
+	GetImages(ctx context.Context, productID string) ([]*model.ProductImage, error)
+	UploadImages(ctx context.Context, productID string, files []*multipart.FileHeader) ([]*model.ProductImage, error)
+	ReorderImages(ctx context.Context, productID string, req request.ReorderProductImagesRequest) ([]*model.ProductImage, error)
+	DeleteImage(ctx context.Context, productID string, id string) error
+	Run(ctx context.Context)
+}
+
+type productImageService struct {
+	productRepo  repository.ProductRepository
+	imageRepo    repository.ProductImageRepository
+	storage      filestore.Storage
+	auditService AuditService
+	validate     *validator.Validate
+}
+
+const (
+	maxProductImages = 20
+	// maxImagePixels bounds the decoded size so a small, highly compressed
+	// upload cannot exhaust memory.
+	maxImagePixels     = 40_000_000
+	imageSweepInterval = time.Hour
+	imageSweepBatch    = 100
+)
+
+// imageTypes maps the accepted MIME types to the extension of stored files.
+var imageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// thumbnailSizes is the bounding box, in pixels, of each generated thumbnail.
+var thumbnailSizes = map[string]int{
+	"small":  150,
+	"medium": 400,
+	"large":  800,
+}
+
+func NewProductImageService(
+	productRepo repository.ProductRepository,
+	imageRepo repository.ProductImageRepository,
+	storage filestore.Storage,
+	auditService AuditService,
+) ProductImageService {
+	return &productImageService{
+		productRepo:  productRepo,
+		imageRepo:    imageRepo,
+		storage:      storage,
+		auditService: auditService,
+		validate:     validator.New(),
+	}
+}
+
+func (s *productImageService) GetImages(ctx context.Context, productID string) ([]*model.ProductImage, error) {
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+	return s.imageRepo.GetProductImages(ctx, productID)
+}
+
+// UploadImages stores the files with their thumbnails and appends them to the
+// product gallery. Nothing is saved unless every file is a valid image.
+func (s *productImageService) UploadImages(ctx context.Context, productID string, files []*multipart.FileHeader) ([]*model.ProductImage, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no images uploaded")
+	}
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	count, err := s.imageRepo.CountProductImages(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if int(count)+len(files) > maxProductImages {
+		return nil, fmt.Errorf("a product can have at most %d images", maxProductImages)
+	}
+
+	uploads := make([]imageUpload, len(files))
+	for i, file := range files {
+		upload, err := readImageUpload(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Filename, err)
+		}
+		uploads[i] = upload
+	}
+
+	images := make([]*model.ProductImage, 0, len(uploads))
+	var stored []string
+	for _, upload := range uploads {
+		productImage, err := s.storeImage(ctx, productID, upload)
+		if productImage != nil {
+			stored = append(stored, productImage.StorageKeys()...)
+		}
+		if err != nil {
+			s.deleteFiles(ctx, stored)
+			return nil, err
+		}
+		images = append(images, productImage)
+	}
+
+	if err := s.imageRepo.CreateImages(ctx, images); err != nil {
+		s.deleteFiles(ctx, stored)
+		return nil, err
+	}
+
+	for _, productImage := range images {
+		s.auditService.Record(ctx, &model.AuditEvent{
+			Action:     model.AuditActionImageUpload,
+			TargetType: "product",
+			TargetID:   productID,
+			Metadata:   model.AuditMetadata{"image_id": productImage.ID, "size": productImage.Size},
+		})
+	}
+
+	return images, nil
+}
+
+// ReorderImages sets the gallery order. The request must list every image of
+// the product exactly once.
+func (s *productImageService) ReorderImages(ctx context.Context, productID string, req request.ReorderProductImagesRequest) ([]*model.ProductImage, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	images, err := s.GetImages(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.ImageIDs) != len(images) {
+		return nil, errors.New("image_ids must list every image of the product")
+	}
+	for _, productImage := range images {
+		if !slices.Contains(req.ImageIDs, productImage.ID) {
+			return nil, errors.New("image_ids must list every image of the product")
+		}
+	}
+
+	if err := s.imageRepo.ReorderImages(ctx, productID, req.ImageIDs); err != nil {
+		return nil, err
+	}
+
+	return s.imageRepo.GetProductImages(ctx, productID)
+}
+
+func (s *productImageService) DeleteImage(ctx context.Context, productID string, id string) error {
+	productImage, err := s.imageRepo.GetImage(ctx, productID, id)
+	if err != nil {
+		return errors.New("image not found")
+	}
+
+	if err := s.imageRepo.DeleteImage(ctx, productID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("image not found")
+		}
+		return err
+	}
+	s.deleteFiles(ctx, productImage.StorageKeys())
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionImageDelete,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"image_id": productImage.ID},
+	})
+
+	return nil
+}
+
+// Run removes the images of products that stayed deleted for longer than the
+// retention period until ctx is done. Products can be restored until then.
+func (s *productImageService) Run(ctx context.Context) {
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(imageSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *productImageService) sweep(ctx context.Context) {
+	deletedBefore := time.Now().Add(-time.Duration(config.Envs.ImageRetention) * time.Second)
+
+	for {
+		images, err := s.imageRepo.FindImagesOfDeletedProducts(ctx, deletedBefore, imageSweepBatch)
+		if err != nil {
+			log.Printf("Failed to load orphaned product images: %v", err)
+			return
+		}
+		if len(images) == 0 {
+			return
+		}
+
+		ids := make([]string, 0, len(images))
+		for _, productImage := range images {
+			failed := false
+			for _, key := range productImage.StorageKeys() {
+				if err := s.storage.Delete(ctx, key); err != nil {
+					log.Printf("Failed to remove image file %s: %v", key, err)
+					failed = true
+				}
+			}
+			if !failed {
+				ids = append(ids, productImage.ID)
+			}
+		}
+		if err := s.imageRepo.DeleteImagesByIDs(ctx, ids); err != nil {
+			log.Printf("Failed to remove orphaned product images: %v", err)
+			return
+		}
+		if len(ids) < len(images) {
+			// Failed images stay listed, retry them on the next tick.
+			return
+		}
+	}
+}
+
+// imageUpload is a sniffed and decoded upload, ready to be stored.
+type imageUpload struct {
+	data        []byte
+	contentType string
+	ext         string
+	img         image.Image
+}
+
+func readImageUpload(file *multipart.FileHeader) (imageUpload, error) {
+	if file.Size > int64(config.Envs.UploadMaxBytes) {
+		return imageUpload{}, fmt.Errorf("file exceeds the %d byte limit", config.Envs.UploadMaxBytes)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return imageUpload{}, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, int64(config.Envs.UploadMaxBytes)+1))
+	if err != nil {
+		return imageUpload{}, err
+	}
+	if len(data) > config.Envs.UploadMaxBytes {
+		return imageUpload{}, fmt.Errorf("file exceeds the %d byte limit", config.Envs.UploadMaxBytes)
+	}
+
+	// The declared Content-Type and file name are ignored, only the bytes count.
+	contentType := mimetype.Detect(data).String()
+	ext, ok := imageTypes[contentType]
+	if !ok {
+		return imageUpload{}, fmt.Errorf("unsupported file type %s", contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return imageUpload{}, errors.New("file is not a valid image")
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return imageUpload{}, errors.New("image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return imageUpload{}, errors.New("file is not a valid image")
+	}
+
+	return imageUpload{data: data, contentType: contentType, ext: ext, img: img}, nil
+}
+
+// storeImage writes the original and its thumbnails. On error the returned
+// image, if any, lists the files already written.
+func (s *productImageService) storeImage(ctx context.Context, productID string, upload imageUpload) (*model.ProductImage, error) {
+	id := uuid.New().String()
+	base := fmt.Sprintf("products/%s/%s", productID, id)
+	bounds := upload.img.Bounds()
+
+	productImage := &model.ProductImage{
+		ID:          id,
+		ProductID:   productID,
+		StorageKey:  base + "." + upload.ext,
+		ContentType: upload.contentType,
+		Size:        int64(len(upload.data)),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Thumbnails:  model.ImageThumbnails{},
+		CreatedAt:   time.Now().UnixMilli(),
+	}
+	if err := s.storage.Put(ctx, productImage.StorageKey, upload.data, upload.contentType); err != nil {
+		return nil, err
+	}
+	productImage.URL = s.storage.URL(productImage.StorageKey)
+
+	// PNG keeps transparency, everything else becomes JPEG.
+	thumbType, thumbExt := "image/jpeg", "jpg"
+	if upload.contentType == "image/png" {
+		thumbType, thumbExt = "image/png", "png"
+	}
+
+	names := make([]string, 0, len(thumbnailSizes))
+	for name := range thumbnailSizes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		thumb := utils.ResizeToFit(upload.img, thumbnailSizes[name])
+
+		var buf bytes.Buffer
+		var err error
+		if thumbType == "image/png" {
+			err = png.Encode(&buf, thumb)
+		} else {
+			err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return productImage, err
+		}
+
+		key := fmt.Sprintf("%s_%s.%s", base, name, thumbExt)
+		if err := s.storage.Put(ctx, key, buf.Bytes(), thumbType); err != nil {
+			return productImage, err
+		}
+		thumbBounds := thumb.Bounds()
+		productImage.Thumbnails[name] = model.ImageFile{
+			Key:    key,
+			URL:    s.storage.URL(key),
+			Width:  thumbBounds.Dx(),
+			Height: thumbBounds.Dy(),
+		}
+	}
+
+	return productImage, nil
+}
+
+func (s *productImageService) deleteFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Failed to remove image file %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductImportService interface {
+	StartImport(ctx context.Context, userID string, format string, file *multipart.FileHeader) (*model.ProductImport, error)
+	GetImport(ctx context.Context, id string) (*model.ProductImport, error)
+	ExportProducts(ctx context.Context, format string, w io.Writer) error
+	Run(ctx context.Context)
+}
+
+type productImportService struct {
+	importRepo     repository.ProductImportRepository
+	productRepo    repository.ProductRepository
+	productService ProductService
+	auditService   AuditService
+	validate       *validator.Validate
+	queue          chan string
+}
+
+const (
+	importSweepInterval = time.Minute
+	// importProgressEvery is how many rows are processed between progress saves.
+	importProgressEvery = 100
+	// maxImportRowErrors caps the stored error report, FailedCount keeps counting.
+	maxImportRowErrors = 1000
+	exportBatchSize    = 500
+	// maxImportLineBytes bounds a single NDJSON line.
+	maxImportLineBytes = 1 << 20
+)
+
+// productCSVColumns is the column order of exports. Imports accept the
+// columns in any order, only sku and name are required. List columns are
+// separated by "|".
+var productCSVColumns = []string{"sku", "name", "description", "price", "base_price", "stock", "weight", "image", "category_ids"}
+
+func NewProductImportService(
+	importRepo repository.ProductImportRepository,
+	productRepo repository.ProductRepository,
+	productService ProductService,
+	auditService AuditService,
+) ProductImportService {
+	return &productImportService{
+		importRepo:     importRepo,
+		productRepo:    productRepo,
+		productService: productService,
+		auditService:   auditService,
+		validate:       validator.New(),
+		queue:          make(chan string, 100),
+	}
+}
+
+// ProductImportFormat picks the format from an explicit value, falling back to
+// the file extension.
+func ProductImportFormat(format string, filename string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = model.ProductImportFormatCSV
+		case ".ndjson", ".jsonl":
+			format = model.ProductImportFormatNDJSON
+		}
+	}
+	switch format {
+	case model.ProductImportFormatCSV, model.ProductImportFormatNDJSON:
+		return format, nil
+	case "":
+		return "", errors.New("format is required, use csv or ndjson")
+	default:
+		return "", fmt.Errorf("unsupported format %s, use csv or ndjson", format)
+	}
+}
+
+// StartImport stores the uploaded file and queues it for processing.
+func (s *productImportService) StartImport(ctx context.Context, userID string, format string, file *multipart.FileHeader) (*model.ProductImport, error) {
+	format, err := ProductImportFormat(format, file.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size > int64(config.Envs.ImportMaxBytes) {
+		return nil, fmt.Errorf("file exceeds the %d byte limit", config.Envs.ImportMaxBytes)
+	}
+
+	productImport := &model.ProductImport{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Format:    format,
+		Status:    model.ProductImportStatusPending,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	productImport.FilePath = filepath.Join(config.Envs.ImportDir, productImport.ID+"."+format)
+
+	if err := saveImportFile(file, productImport.FilePath); err != nil {
+		return nil, err
+	}
+	if err := s.importRepo.CreateImport(ctx, productImport); err != nil {
+		os.Remove(productImport.FilePath)
+		return nil, err
+	}
+
+	// A full queue is fine, the periodic sweep picks up pending rows.
+	select {
+	case s.queue <- productImport.ID:
+	default:
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionProductImport,
+		TargetType: "product_import",
+		TargetID:   productImport.ID,
+		Metadata:   model.AuditMetadata{"format": format, "size": file.Size},
+	})
+
+	return productImport, nil
+}
+
+func (s *productImportService) GetImport(ctx context.Context, id string) (*model.ProductImport, error) {
+	productImport, err := s.importRepo.GetImport(ctx, id)
+	if err != nil {
+		return nil, errors.New("import not found")
+	}
+	return productImport, nil
+}
+
+// ExportProducts streams the live catalog to w in a format StartImport
+// accepts, so an export can be edited and imported again.
+func (s *productImportService) ExportProducts(ctx context.Context, format string, w io.Writer) error {
+	format, err := ProductImportFormat(format, "")
+	if err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == model.ProductImportFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(productCSVColumns); err != nil {
+			return err
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	return s.productRepo.EachProductBatch(ctx, exportBatchSize, func(products []*model.Product) error {
+		for _, product := range products {
+			row := toImportRow(product)
+			if csvWriter != nil {
+				if err := csvWriter.Write(row.csvRecord()); err != nil {
+					return err
+				}
+			} else if err := jsonEncoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// Run processes queued imports until ctx is done.
+func (s *productImportService) Run(ctx context.Context) {
+	if err := s.importRepo.ResetProcessing(ctx); err != nil {
+		log.Printf("Failed to reset interrupted imports: %v", err)
+	}
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(importSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *productImportService) sweep(ctx context.Context) {
+	pending, err := s.importRepo.FindByStatus(ctx, []string{model.ProductImportStatusPending})
+	if err != nil {
+		log.Printf("Failed to load pending imports: %v", err)
+		return
+	}
+	for _, productImport := range pending {
+		s.process(ctx, productImport.ID)
+	}
+}
+
+func (s *productImportService) process(ctx context.Context, importID string) {
+	claimed, err := s.importRepo.ClaimImport(ctx, importID)
+	if err != nil {
+		log.Printf("Failed to claim import %s: %v", importID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	productImport, err := s.importRepo.GetImport(ctx, importID)
+	if err != nil {
+		log.Printf("Failed to load import %s: %v", importID, err)
+		return
+	}
+
+	// Product changes are audited as the admin who started the import, and
+	// stock changes reference it.
+	ctx = utils.WithRequestMeta(ctx, &utils.RequestMeta{ActorID: productImport.UserID})
+	ctx = withStockSource(ctx, model.StockReasonImport, "product_import", productImport.ID)
+
+	err = s.importRows(ctx, productImport)
+	completedAt := time.Now().UnixMilli()
+	productImport.CompletedAt = &completedAt
+	productImport.Status = model.ProductImportStatusCompleted
+	if err != nil {
+		log.Printf("Failed to process import %s: %v", productImport.ID, err)
+		productImport.Status = model.ProductImportStatusFailed
+		productImport.Error = err.Error()
+	}
+
+	if err := os.Remove(productImport.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove import file %s: %v", productImport.FilePath, err)
+	}
+	productImport.FilePath = ""
+	if err := s.importRepo.UpdateImport(ctx, productImport); err != nil {
+		log.Printf("Failed to update import %s: %v", productImport.ID, err)
+	}
+}
+
+// importRows upserts every row of the file. Invalid rows are recorded on the
+// import and skipped; only an unreadable file fails the whole import.
+func (s *productImportService) importRows(ctx context.Context, productImport *model.ProductImport) error {
+	file, err := os.Open(productImport.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader importRowReader
+	if productImport.Format == model.ProductImportFormatCSV {
+		reader, err = newCSVImportReader(file)
+	} else {
+		reader = newNDJSONImportReader(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rowErr := row.err
+		if rowErr == nil {
+			var created bool
+			created, rowErr = s.upsertRow(ctx, row)
+			if rowErr == nil && created {
+				productImport.CreatedCount++
+			} else if rowErr == nil {
+				productImport.UpdatedCount++
+			}
+		}
+		if rowErr != nil {
+			productImport.FailedCount++
+			if len(productImport.RowErrors) < maxImportRowErrors {
+				productImport.RowErrors = append(productImport.RowErrors, model.ImportRowError{
+					Row:   row.number,
+					SKU:   row.SKU,
+					Error: rowErr.Error(),
+				})
+			}
+		}
+
+		productImport.ProcessedRows++
+		if productImport.ProcessedRows%importProgressEvery == 0 {
+			if err := s.importRepo.UpdateImport(ctx, productImport); err != nil {
+				log.Printf("Failed to save progress of import %s: %v", productImport.ID, err)
+			}
+		}
+	}
+}
+
+// upsertRow creates the product or updates the one with the same SKU,
+// reporting whether it was created.
+func (s *productImportService) upsertRow(ctx context.Context, row *importRow) (bool, error) {
+	if err := s.validate.Struct(row.CreateProductRequest); err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(row.SKU) == "" {
+		return false, errors.New("sku is required")
+	}
+
+	product, err := s.productRepo.GetProductBySKU(ctx, row.SKU)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := s.productService.CreateProduct(ctx, &row.CreateProductRequest); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Columns missing from the file keep their current value. Stock and
+	// images are left alone when variants, codes or the gallery manage them.
+	update := row.ToUpdate()
+	if !row.present["description"] {
+		update.Description = nil
+	}
+	if !row.present["image"] || len(product.Images) > 0 {
+		update.Image = nil
+	}
+	if !row.present["stock"] || product.VariantCount > 0 || product.UniqueCodeType != "" {
+		update.Stock = nil
+	}
+	if !row.present["weight"] {
+		update.Weight = nil
+	}
+	if !row.present["base_price"] {
+		update.BasePrice = nil
+	}
+	if !row.present["category_ids"] {
+		update.CategoryIDs = nil
+	}
+	if !row.present["price"] {
+		update.Price = nil
+	}
+
+	if _, err := s.productService.UpdateProduct(ctx, product.ID, product.Version, &update); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// importRow is one product of an import file. present lists the fields the
+// row actually set, err why the row could not be read.
+type importRow struct {
+	request.CreateProductRequest
+	number  int
+	present map[string]bool
+	err     error
+}
+
+func toImportRow(product *model.Product) *importRow {
+	categoryIDs := make([]string, len(product.Categories))
+	for i, category := range product.Categories {
+		categoryIDs[i] = category.ID
+	}
+	return &importRow{
+		CreateProductRequest: request.CreateProductRequest{
+			Name:        product.Name,
+			Description: product.Description,
+			Image:       product.Image,
+			Stock:       product.Stock,
+			Price:       product.Price,
+			Weight:      product.Weight,
+			BasePrice:   product.BasePrice,
+			SKU:         product.SKU,
+			CategoryIDs: categoryIDs,
+		},
+	}
+}
+
+func (r *importRow) csvRecord() []string {
+	return []string{
+		r.SKU,
+		r.Name,
+		r.Description,
+		strconv.Itoa(r.Price),
+		strconv.Itoa(r.BasePrice),
+		strconv.Itoa(r.Stock),
+		strconv.Itoa(r.Weight),
+		strings.Join(r.Image, "|"),
+		strings.Join(r.CategoryIDs, "|"),
+	}
+}
+
+// importRowReader yields the rows of an import file. Problems with a single
+// row are reported in its err, a returned error ends the import.
+type importRowReader interface {
+	next() (*importRow, error)
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns []string
+	rows    int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !slices.Contains(productCSVColumns, column) {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		if slices.Contains(columns, column) {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+		columns[i] = column
+	}
+	if !slices.Contains(columns, "sku") || !slices.Contains(columns, "name") {
+		return nil, errors.New("sku and name columns are required")
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) next() (*importRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	r.rows++
+	row := &importRow{number: r.rows, present: map[string]bool{}}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.err = parseErr.Err
+		return row, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(record) != len(r.columns) {
+		row.err = fmt.Errorf("expected %d fields, got %d", len(r.columns), len(record))
+		return row, nil
+	}
+
+	for i, column := range r.columns {
+		value := record[i]
+		row.present[column] = true
+
+		var err error
+		switch column {
+		case "sku":
+			row.SKU = strings.TrimSpace(value)
+		case "name":
+			row.Name = strings.TrimSpace(value)
+		case "description":
+			row.Description = value
+		case "price":
+			row.Price, err = parseImportInt(column, value)
+		case "base_price":
+			row.BasePrice, err = parseImportInt(column, value)
+		case "stock":
+			row.Stock, err = parseImportInt(column, value)
+		case "weight":
+			row.Weight, err = parseImportInt(column, value)
+		case "image":
+			row.Image = splitImportList(value)
+		case "category_ids":
+			row.CategoryIDs = splitImportList(value)
+		}
+		if err != nil {
+			row.err = err
+			return row, nil
+		}
+	}
+
+	return row, nil
+}
+
+func parseImportInt(column string, value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a whole number", column)
+	}
+	return n, nil
+}
+
+func splitImportList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	rows    int
+}
+
+func newNDJSONImportReader(r io.Reader) *ndjsonImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineBytes)
+	return &ndjsonImportReader{scanner: scanner}
+}
+
+func (r *ndjsonImportReader) next() (*importRow, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		line = bytes.TrimSpace(r.scanner.Bytes())
+	}
+	r.rows++
+	row := &importRow{number: r.rows, present: map[string]bool{}}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		row.err = errors.New("invalid JSON object")
+		return row, nil
+	}
+	for field := range fields {
+		if !slices.Contains(productCSVColumns, field) {
+			row.err = fmt.Errorf("unknown field %q", field)
+			return row, nil
+		}
+		row.present[field] = true
+	}
+	if err := json.Unmarshal(line, &row.CreateProductRequest); err != nil {
+		row.err = err
+		return row, nil
+	}
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.Name = strings.TrimSpace(row.Name)
+
+	return row, nil
+}
+
+func saveImportFile(file *multipart.FileHeader, path string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, io.LimitReader(src, int64(config.Envs.ImportMaxBytes))); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
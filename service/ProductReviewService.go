@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/filestore"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductReviewService interface {
+	CreateReview(ctx context.Context, userID string, productID string, req request.CreateReviewRequest, files []*multipart.FileHeader) (*response.ReviewResponse, error)
+	GetProductReviews(ctx context.Context, productID string, params ReviewQueryParams) (*response.ReviewPagingResponse, error)
+	GetReviewQueue(ctx context.Context, params ReviewQueryParams) (*response.ReviewPagingResponse, error)
+	ModerateReview(ctx context.Context, id string, req request.ModerateReviewRequest) (*response.ReviewResponse, error)
+	VoteHelpful(ctx context.Context, userID string, reviewID string) error
+	RemoveHelpfulVote(ctx context.Context, userID string, reviewID string) error
+}
+
+type productReviewService struct {
+	reviewRepo   repository.ProductReviewRepository
+	productRepo  repository.ProductRepository
+	storage      filestore.Storage
+	auditService AuditService
+	validate     *validator.Validate
+}
+
+type ReviewQueryParams struct {
+	Page       int
+	Limit      int
+	ProductID  string
+	Status     string
+	Rating     int
+	WithImages bool
+	Sort       string
+}
+
+const maxReviewImages = 5
+
+func NewProductReviewService(
+	reviewRepo repository.ProductReviewRepository,
+	productRepo repository.ProductRepository,
+	storage filestore.Storage,
+	auditService AuditService,
+) ProductReviewService {
+	return &productReviewService{
+		reviewRepo:   reviewRepo,
+		productRepo:  productRepo,
+		storage:      storage,
+		auditService: auditService,
+		validate:     validator.New(),
+	}
+}
+
+// CreateReview posts the user's review of a product they received in a
+// complete order. It waits in the moderation queue until approved.
+func (s *productReviewService) CreateReview(ctx context.Context, userID string, productID string, req request.CreateReviewRequest, files []*multipart.FileHeader) (*response.ReviewResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if len(files) > maxReviewImages {
+		return nil, fmt.Errorf("a review can have at most %d images", maxReviewImages)
+	}
+
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	exists, err := s.reviewRepo.ReviewExists(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("you already reviewed this product")
+	}
+
+	orderID, err := s.reviewRepo.FindCompletedOrder(ctx, userID, productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("only customers with a complete order of this product can review it")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]imageUpload, len(files))
+	for i, file := range files {
+		upload, err := readImageUpload(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Filename, err)
+		}
+		uploads[i] = upload
+	}
+
+	review := &model.ProductReview{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		UserID:    userID,
+		OrderID:   orderID,
+		Rating:    req.Rating,
+		Title:     req.Title,
+		Body:      req.Body,
+		Images:    model.ReviewImages{},
+		Status:    model.ReviewStatusPending,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	for _, upload := range uploads {
+		key := fmt.Sprintf("reviews/%s/%s.%s", review.ID, uuid.New().String(), upload.ext)
+		if err := s.storage.Put(ctx, key, upload.data, upload.contentType); err != nil {
+			s.deleteFiles(ctx, review.StorageKeys())
+			return nil, err
+		}
+		bounds := upload.img.Bounds()
+		review.Images = append(review.Images, model.ImageFile{
+			Key:    key,
+			URL:    s.storage.URL(key),
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+		})
+	}
+
+	if err := s.reviewRepo.CreateReview(ctx, review); err != nil {
+		s.deleteFiles(ctx, review.StorageKeys())
+		return nil, err
+	}
+
+	result := toReviewResponse(review, false)
+	return &result, nil
+}
+
+// GetProductReviews lists the approved reviews of a product.
+func (s *productReviewService) GetProductReviews(ctx context.Context, productID string, params ReviewQueryParams) (*response.ReviewPagingResponse, error) {
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	params.ProductID = productID
+	params.Status = model.ReviewStatusApproved
+	return s.getReviews(ctx, params, false)
+}
+
+// GetReviewQueue lists reviews for moderation, pending ones unless another
+// status is asked for, oldest first by default.
+func (s *productReviewService) GetReviewQueue(ctx context.Context, params ReviewQueryParams) (*response.ReviewPagingResponse, error) {
+	if params.Status == "" {
+		params.Status = model.ReviewStatusPending
+	}
+	if params.Sort == "" {
+		params.Sort = "oldest"
+	}
+	return s.getReviews(ctx, params, true)
+}
+
+func (s *productReviewService) getReviews(ctx context.Context, params ReviewQueryParams, admin bool) (*response.ReviewPagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	filter := repository.ReviewFilter{
+		ProductID:  params.ProductID,
+		Status:     params.Status,
+		Rating:     params.Rating,
+		WithImages: params.WithImages,
+		Sort:       params.Sort,
+	}
+	reviews, total, err := s.reviewRepo.GetReviews(ctx, filter, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		result[i] = toReviewResponse(review, admin)
+	}
+
+	return &response.ReviewPagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: result,
+	}, nil
+}
+
+// ModerateReview approves or hides a review. Only approved reviews are public
+// and count towards the product's rating.
+func (s *productReviewService) ModerateReview(ctx context.Context, id string, req request.ModerateReviewRequest) (*response.ReviewResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	review, err := s.reviewRepo.GetReview(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+
+	from := review.Status
+	now := time.Now().UnixMilli()
+	review.Status = req.Status
+	review.ModeratedAt = &now
+	if meta := utils.RequestMetaFromContext(ctx); meta != nil && meta.ActorID != "" {
+		actorID := meta.ActorID
+		review.ModeratedBy = &actorID
+	}
+
+	if err := s.reviewRepo.ModerateReview(ctx, review); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionReviewModerate,
+		TargetType: "review",
+		TargetID:   review.ID,
+		Metadata: model.AuditMetadata{
+			"product_id": review.ProductID,
+			"from":       from,
+			"to":         review.Status,
+		},
+	})
+
+	result := toReviewResponse(review, true)
+	return &result, nil
+}
+
+// VoteHelpful marks an approved review as helpful, once per user.
+func (s *productReviewService) VoteHelpful(ctx context.Context, userID string, reviewID string) error {
+	review, err := s.reviewRepo.GetReview(ctx, reviewID)
+	if err != nil || review.Status != model.ReviewStatusApproved {
+		return errors.New("review not found")
+	}
+	if review.UserID == userID {
+		return errors.New("you cannot vote for your own review")
+	}
+
+	return s.reviewRepo.AddVote(ctx, &model.ReviewVote{
+		ReviewID:  reviewID,
+		UserID:    userID,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+}
+
+func (s *productReviewService) RemoveHelpfulVote(ctx context.Context, userID string, reviewID string) error {
+	if _, err := s.reviewRepo.GetReview(ctx, reviewID); err != nil {
+		return errors.New("review not found")
+	}
+	return s.reviewRepo.RemoveVote(ctx, reviewID, userID)
+}
+
+func (s *productReviewService) deleteFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Failed to remove review image %s: %v", key, err)
+		}
+	}
+}
+
+func toReviewResponse(review *model.ProductReview, admin bool) response.ReviewResponse {
+	result := response.ReviewResponse{
+		ID:           review.ID,
+		ProductID:    review.ProductID,
+		Rating:       review.Rating,
+		Title:        review.Title,
+		Body:         review.Body,
+		Images:       review.Images,
+		HelpfulCount: review.HelpfulCount,
+		Status:       review.Status,
+		CreatedAt:    review.CreatedAt,
+	}
+	if review.User != nil {
+		result.Author = review.User.Username
+	}
+	if admin {
+		result.UserID = review.UserID
+		result.OrderID = review.OrderID
+		result.ModeratedAt = review.ModeratedAt
+		result.ModeratedBy = review.ModeratedBy
+	}
+	return result
+}
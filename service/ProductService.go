@@ -3,36 +3,65 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"nuxatech-nextmedis/dto/request"
 	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/model"
 	"nuxatech-nextmedis/repository"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gosimple/slug"
+	"gorm.io/gorm"
 )
 
 type ProductService interface {
 	CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*model.Product, error)
 	GetProduct(ctx context.Context, id string) (*model.Product, error)
-	UpdateProduct(ctx context.Context, id string, product *request.UpdateProductRequest) (*model.Product, error)
-	DeleteProduct(ctx context.Context, id string) error
+	UpdateProduct(ctx context.Context, id string, version int64, product *request.UpdateProductRequest) (*model.Product, error)
+	DeleteProduct(ctx context.Context, id string, version int64) error
+	RestoreProduct(ctx context.Context, id string) (*model.Product, error)
 	GetAllProducts(ctx context.Context, params ProductQueryParams) (*response.PagingResponse, error)
+	SuggestProducts(ctx context.Context, search string, limit int) ([]*response.ProductSuggestion, error)
 }
 
+// ErrVersionMismatch is returned when a write was based on a stale version.
+var ErrVersionMismatch = errors.New("resource has been modified, fetch it again and retry")
+
 type productService struct {
-	productRepo repository.ProductRepository
-	validate    *validator.Validate
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	auditService AuditService
+	validate     *validator.Validate
 }
 
+// ProductQueryParams.Category is a category slug, products from its
+// subcategories are included. Attributes filter on variant options, e.g.
+// Color=Red. Sort is one of ProductSorts and defaults to relevance when
+// searching, newest otherwise. Cursor pagination supports the newest, price
+// and name sorts, so a search must pick one of them to use it.
 type ProductQueryParams struct {
-	Page   int
-	Limit  int
-	Search string
+	CursorParams
+	Page         int
+	Limit        int
+	Search       string
+	Category     string
+	MinPrice     *int
+	MaxPrice     *int
+	InStock      bool
+	Attributes   map[string]string
+	CreatedAfter int64
+	Sort         string
+	WithFacets   bool
 }
 
+var ProductSorts = []string{"relevance", "price_asc", "price_desc", "newest", "best_selling", "name_asc", "name_desc"}
+
+// priceBucketBounds split the price facet into buckets.
+var priceBucketBounds = []int{50000, 100000, 250000, 500000, 1000000}
+
 // GetAllProducts implements ProductService.
 func (p *productService) GetAllProducts(ctx context.Context, params ProductQueryParams) (*response.PagingResponse, error) {
 	if params.Page < 1 {
@@ -42,13 +71,63 @@ func (p *productService) GetAllProducts(ctx context.Context, params ProductQuery
 		params.Limit = 10
 	}
 
-	products, total, err := p.productRepo.GetAllProducts(ctx, params.Page, params.Limit, params.Search)
+	if params.Sort != "" && !slices.Contains(ProductSorts, params.Sort) {
+		return nil, fmt.Errorf("invalid sort, expected one of %s", strings.Join(ProductSorts, ", "))
+	}
+	if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+		return nil, errors.New("min_price must not exceed max_price")
+	}
+
+	filter := repository.ProductFilter{
+		Search:       params.Search,
+		MinPrice:     params.MinPrice,
+		MaxPrice:     params.MaxPrice,
+		InStock:      params.InStock,
+		Attributes:   params.Attributes,
+		CreatedAfter: params.CreatedAfter,
+		Sort:         params.Sort,
+	}
+	if params.Category != "" {
+		category, err := p.categoryRepo.GetCategoryBySlug(ctx, params.Category)
+		if err != nil {
+			return nil, errors.New("category not found")
+		}
+		filter.CategoryIDs, err = p.categoryRepo.GetDescendantIDs(ctx, category.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result *response.PagingResponse
+	var err error
+	if params.cursorMode() {
+		result, err = p.getProductsByCursor(ctx, filter, params)
+	} else {
+		result, err = p.getProductsByPage(ctx, filter, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WithFacets {
+		facets, err := p.productRepo.GetProductFacets(ctx, filter, priceBucketBounds)
+		if err != nil {
+			return nil, err
+		}
+		result.Facets = toProductFacets(facets)
+	}
+
+	return result, nil
+}
+
+func (p *productService) getProductsByPage(ctx context.Context, filter repository.ProductFilter, params ProductQueryParams) (*response.PagingResponse, error) {
+	products, total, err := p.productRepo.GetAllProducts(ctx, params.Page, params.Limit, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	return &response.PagingResponse{
-		Metadata: response.Metadata{
+		Metadata: &response.Metadata{
 			TotalCount: int(total),
 			Page:       params.Page,
 			PerPage:    params.Limit,
@@ -57,37 +136,138 @@ func (p *productService) GetAllProducts(ctx context.Context, params ProductQuery
 	}, nil
 }
 
+func (p *productService) getProductsByCursor(ctx context.Context, filter repository.ProductFilter, params ProductQueryParams) (*response.PagingResponse, error) {
+	cursor, err := params.decode()
+	if err != nil {
+		return nil, err
+	}
+	limit := cursorLimit(params.Limit)
+
+	products, page, err := p.productRepo.GetProductsAfter(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IncludeTotal {
+		count, err := p.productRepo.CountProducts(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return &response.PagingResponse{
+		Cursor: toCursorMetadata(page, limit, total),
+		Result: products,
+	}, nil
+}
+
+func toProductFacets(facets *repository.ProductFacets) *response.ProductFacets {
+	result := &response.ProductFacets{
+		Categories:   make([]response.CategoryFacet, len(facets.Categories)),
+		PriceBuckets: make([]response.PriceBucketFacet, 0, len(facets.PriceBuckets)),
+		Attributes:   make([]response.AttributeFacet, len(facets.Attributes)),
+	}
+
+	for i, category := range facets.Categories {
+		result.Categories[i] = response.CategoryFacet(category)
+	}
+
+	// width_bucket numbers the bucket below the first bound 0.
+	for _, bucket := range facets.PriceBuckets {
+		facet := response.PriceBucketFacet{Count: bucket.Count}
+		if bucket.Bucket > 0 {
+			facet.Min = priceBucketBounds[bucket.Bucket-1]
+		}
+		if bucket.Bucket < len(priceBucketBounds) {
+			max := priceBucketBounds[bucket.Bucket]
+			facet.Max = &max
+		}
+		result.PriceBuckets = append(result.PriceBuckets, facet)
+	}
+
+	for i, attribute := range facets.Attributes {
+		result.Attributes[i] = response.AttributeFacet(attribute)
+	}
+
+	return result
+}
+
+// SuggestProducts implements ProductService.
+func (p *productService) SuggestProducts(ctx context.Context, search string, limit int) ([]*response.ProductSuggestion, error) {
+	search = strings.TrimSpace(search)
+	if len([]rune(search)) < 2 {
+		return nil, errors.New("search must be at least 2 characters")
+	}
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	products, err := p.productRepo.SuggestProducts(ctx, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*response.ProductSuggestion, len(products))
+	for i, product := range products {
+		suggestions[i] = &response.ProductSuggestion{
+			ID:   product.ID,
+			Name: product.Name,
+			Slug: product.Slug,
+		}
+	}
+	return suggestions, nil
+}
+
 // CreateProduct implements ProductService.
 func (p *productService) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*model.Product, error) {
 	if err := p.validate.Struct(product); err != nil {
 		return nil, err
 	}
 
+	if product.UniqueCodeType != "" && product.Stock != 0 {
+		return nil, errors.New("stock of a digital product comes from its codes")
+	}
+
 	slug := slug.Make(product.Name)
-	lowerSku := strings.ToLower(product.SKU)
-	errSlug := p.productRepo.CheckSlug(ctx, slug, lowerSku)
-	if errSlug == nil {
-		return nil, errors.New("slug already exist")
+	if err := p.checkSlugAndSKU(ctx, slug, product.SKU, ""); err != nil {
+		return nil, err
+	}
+
+	categories, err := p.resolveCategories(ctx, product.CategoryIDs)
+	if err != nil {
+		return nil, err
 	}
 
 	var images model.LocalProductImages
 	images = append(images, product.Image...)
 
 	newProduct := &model.Product{
-		Name:        product.Name,
-		Description: product.Description,
-		Image:       images,
-		Stock:       product.Stock,
-		Price:       product.Price,
-		BasePrice:   product.BasePrice,
-		SKU:         product.SKU,
-		Slug:        slug,
-		Weight:      product.Weight,
-		Sold:        false,
-		CreatedAt:   time.Now().UnixMilli(),
-	}
-
-	err := p.productRepo.CreateProduct(ctx, newProduct)
+		Name:             product.Name,
+		Description:      product.Description,
+		Image:            images,
+		Stock:            product.Stock,
+		Price:            product.Price,
+		BasePrice:        product.BasePrice,
+		SKU:              product.SKU,
+		Slug:             slug,
+		UniqueCodeType:   product.UniqueCodeType,
+		Weight:           product.Weight,
+		Sold:             false,
+		ReorderThreshold: product.ReorderThreshold,
+		CreatedAt:        time.Now().UnixMilli(),
+		Version:          1,
+		Categories:       categories,
+	}
+
+	var movement *model.StockMovement
+	if newProduct.Stock != 0 {
+		movement = newSourcedStockMovement(ctx, newProduct.Stock, model.StockReasonInitial)
+	}
+
+	price := newPriceChange(ctx, model.PriceReasonInitial, nil)
+	err = p.productRepo.CreateProduct(ctx, newProduct, movement, price)
 	if err != nil {
 		return nil, err
 	}
@@ -96,8 +276,49 @@ func (p *productService) CreateProduct(ctx context.Context, product *request.Cre
 }
 
 // DeleteProduct implements ProductService.
-func (p *productService) DeleteProduct(ctx context.Context, id string) error {
-	panic("unimplemented")
+// The product is soft-deleted, which hides it from listings and carts while
+// past orders keep referencing it.
+func (p *productService) DeleteProduct(ctx context.Context, id string, version int64) error {
+	product, err := p.productRepo.GetProduct(ctx, id)
+	if err != nil {
+		return errors.New("product not found")
+	}
+	if product.Version != version {
+		return ErrVersionMismatch
+	}
+
+	if err := p.productRepo.DeleteProduct(ctx, id, version); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVersionMismatch
+		}
+		return err
+	}
+
+	p.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionProductDelete,
+		TargetType: "product",
+		TargetID:   id,
+	})
+
+	return nil
+}
+
+// RestoreProduct implements ProductService.
+func (p *productService) RestoreProduct(ctx context.Context, id string) (*model.Product, error) {
+	if err := p.productRepo.RestoreProduct(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("deleted product not found")
+		}
+		return nil, err
+	}
+
+	p.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionProductRestore,
+		TargetType: "product",
+		TargetID:   id,
+	})
+
+	return p.productRepo.GetProduct(ctx, id)
 }
 
 // GetProduct implements ProductService.
@@ -113,13 +334,158 @@ func (p *productService) GetProduct(ctx context.Context, id string) (*model.Prod
 }
 
 // UpdateProduct implements ProductService.
-func (p *productService) UpdateProduct(ctx context.Context, id string, product *request.UpdateProductRequest) (*model.Product, error) {
-	panic("unimplemented")
+func (p *productService) UpdateProduct(ctx context.Context, id string, version int64, req *request.UpdateProductRequest) (*model.Product, error) {
+	if err := p.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := p.productRepo.GetProduct(ctx, id)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	if product.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	changed := model.AuditMetadata{}
+	recheck := false
+	if req.Name != nil && *req.Name != product.Name {
+		changed["name"] = product.Name
+		product.Name = *req.Name
+		product.Slug = slug.Make(product.Name)
+		recheck = true
+	}
+	if req.SKU != nil && *req.SKU != product.SKU {
+		changed["sku"] = product.SKU
+		product.SKU = *req.SKU
+		recheck = true
+	}
+	if recheck {
+		if err := p.checkSlugAndSKU(ctx, product.Slug, product.SKU, product.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.Image != nil {
+		product.Image = model.LocalProductImages(*req.Image)
+	}
+	// Switching between physical and digital would strand the stock or the
+	// codes, so it is only allowed while there is none.
+	if req.UniqueCodeType != nil && *req.UniqueCodeType != product.UniqueCodeType {
+		if product.Stock != 0 || product.Reserved != 0 {
+			return nil, errors.New("unique_code_type can only change while the product has no stock")
+		}
+		if product.VariantCount > 0 {
+			return nil, errors.New("a product with variants cannot be digital")
+		}
+		changed["unique_code_type"] = product.UniqueCodeType
+		product.UniqueCodeType = *req.UniqueCodeType
+	}
+	// Stock changes are recorded as a movement of the difference.
+	var movement *model.StockMovement
+	if req.Stock != nil && *req.Stock != product.Stock {
+		if product.VariantCount > 0 {
+			return nil, errors.New("stock of a product with variants is managed per variant")
+		}
+		if product.UniqueCodeType != "" {
+			return nil, errors.New("stock of a digital product comes from its codes")
+		}
+		changed["stock"] = product.Stock
+		movement = newSourcedStockMovement(ctx, *req.Stock-product.Stock, model.StockReasonAdjustment)
+	}
+	// A price set by hand replaces a running sale, so there is nothing to
+	// compare it to anymore.
+	var price *model.PriceChange
+	if req.Price != nil && *req.Price != product.Price {
+		changed["price"] = product.Price
+		previous := product.Price
+		price = newPriceChange(ctx, model.PriceReasonManual, &previous)
+		product.Price = *req.Price
+		product.CompareAtPrice = nil
+	}
+	if req.Weight != nil {
+		product.Weight = *req.Weight
+	}
+	if req.BasePrice != nil {
+		product.BasePrice = *req.BasePrice
+	}
+	if req.ReorderThreshold != nil {
+		product.ReorderThreshold = *req.ReorderThreshold
+	}
+	var categories []model.Category
+	if req.CategoryIDs != nil {
+		categories, err = p.resolveCategories(ctx, *req.CategoryIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.productRepo.UpdateProduct(ctx, product, movement, price); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("stock cannot go below zero")
+		}
+		return nil, err
+	}
+
+	if req.CategoryIDs != nil {
+		if err := p.categoryRepo.SetProductCategories(ctx, product.ID, *req.CategoryIDs); err != nil {
+			return nil, err
+		}
+		product.Categories = categories
+	}
+
+	p.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionProductUpdate,
+		TargetType: "product",
+		TargetID:   product.ID,
+		Metadata:   model.AuditMetadata{"previous": changed},
+	})
+
+	return product, nil
+}
+
+// checkSlugAndSKU rejects a slug or SKU already used by another product.
+func (p *productService) checkSlugAndSKU(ctx context.Context, slug string, sku string, excludeID string) error {
+	exists, err := p.productRepo.SlugOrSKUExists(ctx, slug, strings.ToLower(sku), excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("slug or sku already exist")
+	}
+	return nil
+}
+
+// resolveCategories loads the given categories, failing if any is unknown.
+func (p *productService) resolveCategories(ctx context.Context, ids []string) ([]model.Category, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	found, err := p.categoryRepo.GetCategoriesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]model.Category, 0, len(found))
+	for _, category := range found {
+		categories = append(categories, *category)
+	}
+	if len(categories) != len(slices.Compact(slices.Sorted(slices.Values(ids)))) {
+		return nil, errors.New("one or more categories not found")
+	}
+	return categories, nil
 }
 
-func NewProductService(productRepo repository.ProductRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, auditService AuditService) ProductService {
 	return &productService{
-		productRepo: productRepo,
-		validate:    validator.New(),
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		auditService: auditService,
+		validate:     validator.New(),
 	}
 }
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type ProductVariantService interface {
+	SetOptions(ctx context.Context, productID string, req request.SetProductOptionsRequest) ([]*model.ProductOption, error)
+	GetVariants(ctx context.Context, productID string) ([]*model.ProductVariant, error)
+	CreateVariant(ctx context.Context, productID string, req request.CreateVariantRequest) (*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID string, id string, req request.UpdateVariantRequest) (*model.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID string, id string) error
+}
+
+type productVariantService struct {
+	productRepo  repository.ProductRepository
+	variantRepo  repository.ProductVariantRepository
+	auditService AuditService
+	validate     *validator.Validate
+}
+
+func NewProductVariantService(
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	auditService AuditService,
+) ProductVariantService {
+	return &productVariantService{
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		auditService: auditService,
+		validate:     validator.New(),
+	}
+}
+
+// SetOptions replaces the option types of a product. Existing variants must
+// still pick a valid value for every option afterwards.
+func (s *productVariantService) SetOptions(ctx context.Context, productID string, req request.SetProductOptionsRequest) ([]*model.ProductOption, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	options := make([]*model.ProductOption, len(req.Options))
+	names := make([]string, 0, len(req.Options))
+	for i, option := range req.Options {
+		name := strings.TrimSpace(option.Name)
+		if slices.Contains(names, strings.ToLower(name)) {
+			return nil, fmt.Errorf("duplicate option: %s", name)
+		}
+		names = append(names, strings.ToLower(name))
+
+		values := make(model.LocalProductOption, 0, len(option.Values))
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if slices.Contains(values, value) {
+				return nil, fmt.Errorf("duplicate value %s for option %s", value, name)
+			}
+			values = append(values, value)
+		}
+
+		options[i] = &model.ProductOption{
+			ProductID: productID,
+			Name:      name,
+			Values:    values,
+			Position:  i,
+		}
+	}
+
+	variants, err := s.variantRepo.GetVariants(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		if err := checkVariantOptions(options, variant.Options); err != nil {
+			return nil, fmt.Errorf("variant %s does not fit the new options: %w", variant.SKU, err)
+		}
+	}
+
+	if err := s.variantRepo.SetOptions(ctx, productID, options); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+func (s *productVariantService) GetVariants(ctx context.Context, productID string) ([]*model.ProductVariant, error) {
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+	return s.variantRepo.GetVariants(ctx, productID)
+}
+
+func (s *productVariantService) CreateVariant(ctx context.Context, productID string, req request.CreateVariantRequest) (*model.ProductVariant, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	if product.UniqueCodeType != "" {
+		return nil, errors.New("a digital product cannot have variants")
+	}
+
+	variantOptions := model.VariantOptions(req.Options)
+	if err := s.checkVariant(ctx, productID, "", req.SKU, variantOptions); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	variant := &model.ProductVariant{
+		ProductID: productID,
+		SKU:       req.SKU,
+		Options:   variantOptions,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		Image:     model.LocalProductImages(req.Image),
+		Position:  req.Position,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var movement *model.StockMovement
+	if variant.Stock != 0 {
+		movement = newStockMovement(ctx, variant.Stock, model.StockReasonInitial, "", "")
+	}
+
+	price := newPriceChange(ctx, model.PriceReasonInitial, nil)
+	if err := s.variantRepo.CreateVariant(ctx, variant, movement, price); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionVariantCreate,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"variant_id": variant.ID, "sku": variant.SKU},
+	})
+
+	return variant, nil
+}
+
+func (s *productVariantService) UpdateVariant(ctx context.Context, productID string, id string, req request.UpdateVariantRequest) (*model.ProductVariant, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.GetVariant(ctx, productID, id)
+	if err != nil {
+		return nil, errors.New("variant not found")
+	}
+
+	changed := model.AuditMetadata{}
+	if req.SKU != nil && *req.SKU != variant.SKU {
+		changed["sku"] = variant.SKU
+		variant.SKU = *req.SKU
+	}
+	if req.Options != nil {
+		changed["options"] = variant.Options
+		variant.Options = model.VariantOptions(*req.Options)
+	}
+	if req.SKU != nil || req.Options != nil {
+		if err := s.checkVariant(ctx, productID, variant.ID, variant.SKU, variant.Options); err != nil {
+			return nil, err
+		}
+	}
+	var price *model.PriceChange
+	if req.Price != nil && *req.Price != variant.Price {
+		changed["price"] = variant.Price
+		previous := variant.Price
+		price = newPriceChange(ctx, model.PriceReasonManual, &previous)
+		variant.Price = *req.Price
+		variant.CompareAtPrice = nil
+	}
+	var movement *model.StockMovement
+	if req.Stock != nil && *req.Stock != variant.Stock {
+		changed["stock"] = variant.Stock
+		movement = newStockMovement(ctx, *req.Stock-variant.Stock, model.StockReasonAdjustment, "", "")
+	}
+	if req.Image != nil {
+		variant.Image = model.LocalProductImages(*req.Image)
+	}
+	if req.Position != nil {
+		variant.Position = *req.Position
+	}
+
+	variant.UpdatedAt = time.Now().UnixMilli()
+	if err := s.variantRepo.UpdateVariant(ctx, variant, movement, price); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("stock cannot go below zero")
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionVariantUpdate,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"variant_id": variant.ID, "previous": changed},
+	})
+
+	return variant, nil
+}
+
+// DeleteVariant removes a variant from sale. Carts holding it drop the item,
+// past orders keep it.
+func (s *productVariantService) DeleteVariant(ctx context.Context, productID string, id string) error {
+	if err := s.variantRepo.DeleteVariant(ctx, productID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("variant not found")
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionVariantDelete,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata:   model.AuditMetadata{"variant_id": id},
+	})
+
+	return nil
+}
+
+// checkVariant validates the options against the product's option types and
+// rejects duplicate SKUs and option combinations.
+func (s *productVariantService) checkVariant(ctx context.Context, productID string, excludeID string, sku string, variantOptions model.VariantOptions) error {
+	options, err := s.variantRepo.GetOptions(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if len(options) == 0 {
+		return errors.New("product has no option types, set them first")
+	}
+	if err := checkVariantOptions(options, variantOptions); err != nil {
+		return err
+	}
+
+	variants, err := s.variantRepo.GetVariants(ctx, productID)
+	if err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		if variant.ID != excludeID && variant.Options.Matches(variantOptions) {
+			return fmt.Errorf("variant %s already has these options", variant.SKU)
+		}
+	}
+
+	exists, err := s.variantRepo.SKUExists(ctx, sku, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("sku already exist")
+	}
+	return nil
+}
+
+// checkVariantOptions requires exactly one allowed value per option type.
+func checkVariantOptions(options []*model.ProductOption, variantOptions model.VariantOptions) error {
+	if len(variantOptions) != len(options) {
+		return fmt.Errorf("expected a value for each of %d options", len(options))
+	}
+	for _, option := range options {
+		value, ok := variantOptions[option.Name]
+		if !ok {
+			return fmt.Errorf("missing value for option %s", option.Name)
+		}
+		if !slices.Contains(option.Values, value) {
+			return fmt.Errorf("invalid value %s for option %s", value, option.Name)
+		}
+	}
+	return nil
+}
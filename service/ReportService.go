@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/repository"
+	"time"
+)
+
+type ReportService interface {
+	GetSalesReport(ctx context.Context, from int64, to int64) (*response.SalesReportResponse, error)
+}
+
+type reportService struct {
+	reportRepo repository.ReportRepository
+}
+
+// defaultReportPeriod is covered when a report is asked without a start.
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+func NewReportService(reportRepo repository.ReportRepository) ReportService {
+	return &reportService{
+		reportRepo: reportRepo,
+	}
+}
+
+// GetSalesReport sums the paid orders placed between from and to per product,
+// at the prices charged on the orders rather than the current ones. It covers
+// the last 30 days up to now by default.
+func (s *reportService) GetSalesReport(ctx context.Context, from int64, to int64) (*response.SalesReportResponse, error) {
+	if to == 0 {
+		to = time.Now().UnixMilli()
+	}
+	if from == 0 {
+		from = to - defaultReportPeriod.Milliseconds()
+	}
+	if from > to {
+		return nil, errors.New("from must be before to")
+	}
+
+	sales, err := s.reportRepo.GetProductSales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.SalesReportResponse{
+		From:     from,
+		To:       to,
+		Products: make([]response.ProductSalesReport, len(sales)),
+	}
+	for i, product := range sales {
+		item := response.ProductSalesReport{
+			ProductID:    product.ProductID,
+			Name:         product.Name,
+			SKU:          product.SKU,
+			Units:        product.Units,
+			Revenue:      product.Revenue,
+			Discount:     product.RegularRevenue - product.Revenue,
+			CurrentPrice: product.CurrentPrice,
+		}
+		if product.Units > 0 {
+			item.AveragePrice = product.Revenue / product.Units
+		}
+		report.Products[i] = item
+		report.Units += item.Units
+		report.Revenue += item.Revenue
+		report.Discount += item.Discount
+	}
+	return report, nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// StockAlertService tells admins about products running low and customers
+// about products back in stock.
+type StockAlertService interface {
+	ReportStockDrop(ctx context.Context, product *model.Product, before int, after int)
+	Subscribe(ctx context.Context, userID string, req request.StockSubscriptionRequest) (*model.StockSubscription, error)
+	GetSubscriptions(ctx context.Context, userID string) ([]*model.StockSubscription, error)
+	Unsubscribe(ctx context.Context, userID string, id string) error
+	Run(ctx context.Context)
+}
+
+type stockAlertService struct {
+	subscriptionRepo    repository.StockSubscriptionRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.ProductVariantRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	validate            *validator.Validate
+}
+
+const (
+	restockSweepInterval = time.Minute
+	restockSweepBatch    = 100
+)
+
+func NewStockAlertService(
+	subscriptionRepo repository.StockSubscriptionRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+) StockAlertService {
+	return &stockAlertService{
+		subscriptionRepo:    subscriptionRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		validate:            validator.New(),
+	}
+}
+
+// ReportStockDrop alerts every admin when available stock fell from at or
+// above the product's reorder threshold to below it. Alerting on the crossing
+// only means one alert per drop, not one per order.
+func (s *stockAlertService) ReportStockDrop(ctx context.Context, product *model.Product, before int, after int) {
+	threshold := product.ReorderThreshold
+	if threshold <= 0 || before < threshold || after >= threshold {
+		return
+	}
+
+	admins, err := s.userRepo.FindActiveByRole(ctx, model.RoleAdmin)
+	if err != nil {
+		log.Printf("Failed to load admins for low stock alert of product %s: %v", product.ID, err)
+		return
+	}
+
+	title := fmt.Sprintf("Low stock: %s", product.Name)
+	body := fmt.Sprintf("%s has %d left to sell, below its reorder threshold of %d.", product.Name, after, threshold)
+	data := model.NotificationData{
+		"product_id": product.ID,
+		"sku":        product.SKU,
+		"available":  after,
+		"threshold":  threshold,
+	}
+	for _, admin := range admins {
+		s.notificationService.Notify(ctx, admin.ID, model.NotificationTypeLowStock, title, body, data)
+	}
+}
+
+// Subscribe registers the user for a back in stock notification. Subscribing
+// twice returns the existing subscription.
+func (s *stockAlertService) Subscribe(ctx context.Context, userID string, req request.StockSubscriptionRequest) (*model.StockSubscription, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	stock := product.Stock
+	if req.VariantID != nil {
+		variant, err := s.variantRepo.GetVariant(ctx, req.ProductID, *req.VariantID)
+		if err != nil {
+			return nil, errors.New("variant not found")
+		}
+		stock = variant.Stock
+	} else if product.VariantCount > 0 {
+		return nil, errors.New("select a variant to be notified about")
+	}
+	if stock > 0 {
+		return nil, errors.New("product is in stock")
+	}
+
+	existing, err := s.subscriptionRepo.GetOpenSubscription(ctx, userID, req.ProductID, req.VariantID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	subscription := &model.StockSubscription{
+		UserID:    userID,
+		ProductID: req.ProductID,
+		Product:   *product,
+		VariantID: req.VariantID,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if err := s.subscriptionRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *stockAlertService) GetSubscriptions(ctx context.Context, userID string) ([]*model.StockSubscription, error) {
+	return s.subscriptionRepo.GetUserSubscriptions(ctx, userID)
+}
+
+func (s *stockAlertService) Unsubscribe(ctx context.Context, userID string, id string) error {
+	err := s.subscriptionRepo.DeleteSubscription(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("subscription not found")
+	}
+	return err
+}
+
+// Run notifies subscribers of products back in stock until ctx is done.
+// Subscriptions are only taken while out of stock, so stock found on an open
+// subscription means it went from zero to positive.
+func (s *stockAlertService) Run(ctx context.Context) {
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(restockSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *stockAlertService) sweep(ctx context.Context) {
+	for {
+		subscriptions, err := s.subscriptionRepo.FindRestocked(ctx, restockSweepBatch)
+		if err != nil {
+			log.Printf("Failed to load restocked subscriptions: %v", err)
+			return
+		}
+
+		for _, subscription := range subscriptions {
+			notified, err := s.subscriptionRepo.MarkNotified(ctx, subscription.ID, time.Now().UnixMilli())
+			if err != nil {
+				log.Printf("Failed to close stock subscription %s: %v", subscription.ID, err)
+				return
+			}
+			if !notified {
+				continue
+			}
+
+			product := subscription.Product
+			s.notificationService.Notify(ctx, subscription.UserID, model.NotificationTypeBackInStock,
+				fmt.Sprintf("Back in stock: %s", product.Name),
+				fmt.Sprintf("%s is available again.", product.Name),
+				model.NotificationData{
+					"product_id": subscription.ProductID,
+					"variant_id": subscription.VariantID,
+					"slug":       product.Slug,
+				})
+		}
+		if len(subscriptions) < restockSweepBatch {
+			return
+		}
+	}
+}
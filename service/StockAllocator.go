@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"nuxatech-nextmedis/model"
+	"sort"
+	"strings"
+)
+
+// allocationLine is an order line waiting for a warehouse.
+type allocationLine struct {
+	name string
+	// key identifies the product or variant, lines sharing it share stock.
+	key      string
+	quantity int
+	// levels is the stock of the line's product or variant per warehouse.
+	levels []*model.WarehouseStock
+}
+
+// allocation is the part of a line shipped from one warehouse.
+type allocation struct {
+	line        int
+	warehouseID string
+	quantity    int
+}
+
+// allocateStock picks the warehouses each line ships from.
+//
+// closest and priority rank warehouses by distance to the shipping address or
+// by priority, and ship the whole order from the first warehouse that can.
+// Otherwise each line ships from the first warehouse holding all of it, and
+// lines no single warehouse holds are split. split ranks by priority and fills
+// every line from the first warehouses, whatever the number of shipments.
+func allocateStock(strategy string, address *model.ShippingAddress, lines []allocationLine) ([]allocation, error) {
+	warehouses := rankWarehouses(strategy, address, lines)
+
+	// available is the stock left per product or variant and warehouse.
+	available := make(map[string]map[string]int)
+	for _, line := range lines {
+		if _, ok := available[line.key]; ok {
+			continue
+		}
+		available[line.key] = make(map[string]int, len(line.levels))
+		for _, level := range line.levels {
+			available[line.key][level.WarehouseID] = level.Available
+		}
+	}
+
+	if strategy != model.FulfilmentSplit {
+		for _, warehouse := range warehouses {
+			if canShipOrder(warehouse.ID, lines, available) {
+				allocations := make([]allocation, len(lines))
+				for i, line := range lines {
+					allocations[i] = allocation{line: i, warehouseID: warehouse.ID, quantity: line.quantity}
+				}
+				return allocations, nil
+			}
+		}
+	}
+
+	var allocations []allocation
+	for i, line := range lines {
+		stock := available[line.key]
+		if strategy != model.FulfilmentSplit {
+			if warehouse := firstWarehouseHolding(warehouses, stock, line.quantity); warehouse != nil {
+				allocations = append(allocations, allocation{line: i, warehouseID: warehouse.ID, quantity: line.quantity})
+				stock[warehouse.ID] -= line.quantity
+				continue
+			}
+		}
+
+		remaining := line.quantity
+		for _, warehouse := range warehouses {
+			take := min(remaining, stock[warehouse.ID])
+			if take <= 0 {
+				continue
+			}
+			allocations = append(allocations, allocation{line: i, warehouseID: warehouse.ID, quantity: take})
+			stock[warehouse.ID] -= take
+			remaining -= take
+			if remaining == 0 {
+				break
+			}
+		}
+		if remaining > 0 {
+			return nil, fmt.Errorf("insufficient stock for product: %s (available: %d, requested: %d)",
+				line.name, line.quantity-remaining, line.quantity)
+		}
+	}
+	return allocations, nil
+}
+
+func canShipOrder(warehouseID string, lines []allocationLine, available map[string]map[string]int) bool {
+	needed := make(map[string]int)
+	for _, line := range lines {
+		needed[line.key] += line.quantity
+		if available[line.key][warehouseID] < needed[line.key] {
+			return false
+		}
+	}
+	return true
+}
+
+func firstWarehouseHolding(warehouses []*model.Warehouse, available map[string]int, quantity int) *model.Warehouse {
+	for _, warehouse := range warehouses {
+		if available[warehouse.ID] >= quantity {
+			return warehouse
+		}
+	}
+	return nil
+}
+
+// rankWarehouses orders the warehouses holding stock of any line. closest
+// ranks by how much of the shipping address they share, then by priority.
+func rankWarehouses(strategy string, address *model.ShippingAddress, lines []allocationLine) []*model.Warehouse {
+	seen := make(map[string]bool)
+	var warehouses []*model.Warehouse
+	for _, line := range lines {
+		for _, level := range line.levels {
+			if level.Warehouse != nil && !seen[level.WarehouseID] {
+				seen[level.WarehouseID] = true
+				warehouses = append(warehouses, level.Warehouse)
+			}
+		}
+	}
+
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		a, b := warehouses[i], warehouses[j]
+		if strategy == model.FulfilmentClosest && address != nil {
+			if da, db := warehouseDistance(a, address), warehouseDistance(b, address); da != db {
+				return da < db
+			}
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Code < b.Code
+	})
+	return warehouses
+}
+
+// warehouseDistance is a coarse distance from 0, same postal code, to 4,
+// another country.
+func warehouseDistance(warehouse *model.Warehouse, address *model.ShippingAddress) int {
+	if !strings.EqualFold(warehouse.CountryCode, address.CountryCode) {
+		return 4
+	}
+	if warehouse.PostalCode != "" && strings.EqualFold(warehouse.PostalCode, address.PostalCode) {
+		return 0
+	}
+	if warehouse.City != "" && strings.EqualFold(warehouse.City, address.City) {
+		return 1
+	}
+	if warehouse.Region != "" && strings.EqualFold(warehouse.Region, address.Region) {
+		return 2
+	}
+	return 3
+}
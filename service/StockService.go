@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type StockService interface {
+	AdjustStock(ctx context.Context, productID string, req request.StockAdjustmentRequest) (*model.StockMovement, error)
+	GetStockHistory(ctx context.Context, productID string, params StockHistoryParams) (*response.StockMovementPagingResponse, error)
+	GetStockLevels(ctx context.Context, productID string) ([]*model.WarehouseStock, error)
+}
+
+type stockService struct {
+	stockRepo     repository.StockMovementRepository
+	productRepo   repository.ProductRepository
+	variantRepo   repository.ProductVariantRepository
+	warehouseRepo repository.WarehouseRepository
+	auditService  AuditService
+	validate      *validator.Validate
+}
+
+type StockHistoryParams struct {
+	Page        int
+	Limit       int
+	VariantID   string
+	WarehouseID string
+	Reason      string
+}
+
+func NewStockService(
+	stockRepo repository.StockMovementRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	warehouseRepo repository.WarehouseRepository,
+	auditService AuditService,
+) StockService {
+	return &stockService{
+		stockRepo:     stockRepo,
+		productRepo:   productRepo,
+		variantRepo:   variantRepo,
+		warehouseRepo: warehouseRepo,
+		auditService:  auditService,
+		validate:      validator.New(),
+	}
+}
+
+// AdjustStock records a manual stock change such as a recount or a return.
+func (s *stockService) AdjustStock(ctx context.Context, productID string, req request.StockAdjustmentRequest) (*model.StockMovement, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	if req.VariantID != nil {
+		if _, err := s.variantRepo.GetVariant(ctx, productID, *req.VariantID); err != nil {
+			return nil, errors.New("variant not found")
+		}
+	} else if product.VariantCount > 0 {
+		return nil, errors.New("stock of a product with variants is managed per variant")
+	}
+	if product.UniqueCodeType != "" {
+		return nil, errors.New("stock of a digital product comes from its codes")
+	}
+	if req.WarehouseID != nil {
+		if _, err := s.warehouseRepo.GetWarehouse(ctx, *req.WarehouseID); err != nil {
+			return nil, errors.New("warehouse not found")
+		}
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = model.StockReasonAdjustment
+	}
+	movement := newStockMovement(ctx, req.Quantity, reason, "", req.ReferenceID)
+	movement.ProductID = productID
+	movement.VariantID = req.VariantID
+	movement.WarehouseID = req.WarehouseID
+	movement.Note = req.Note
+
+	if err := s.stockRepo.RecordMovement(ctx, nil, movement); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("stock cannot go below zero")
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionStockAdjust,
+		TargetType: "product",
+		TargetID:   productID,
+		Metadata: model.AuditMetadata{
+			"movement_id":  movement.ID,
+			"variant_id":   req.VariantID,
+			"warehouse_id": movement.WarehouseID,
+			"quantity":     req.Quantity,
+			"reason":       reason,
+		},
+	})
+
+	return movement, nil
+}
+
+// GetStockHistory lists the stock movements of a product, newest first,
+// including those of its variants.
+func (s *stockService) GetStockHistory(ctx context.Context, productID string, params StockHistoryParams) (*response.StockMovementPagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	filter := repository.StockMovementFilter{
+		ProductID:   productID,
+		VariantID:   params.VariantID,
+		WarehouseID: params.WarehouseID,
+		Reason:      params.Reason,
+	}
+	movements, total, err := s.stockRepo.GetMovements(ctx, filter, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.StockMovementPagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: movements,
+	}, nil
+}
+
+// GetStockLevels lists the stock of a product and its variants per warehouse.
+func (s *stockService) GetStockLevels(ctx context.Context, productID string) ([]*model.WarehouseStock, error) {
+	if _, err := s.productRepo.GetProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return s.warehouseRepo.GetProductStockLevels(ctx, productID)
+}
+
+type stockSourceKey struct{}
+
+// stockSource is the reason and reference given to stock changes that are a
+// side effect of a generic write, like a product update made by an import.
+type stockSource struct {
+	reason        string
+	referenceType string
+	referenceID   string
+}
+
+func withStockSource(ctx context.Context, reason string, referenceType string, referenceID string) context.Context {
+	return context.WithValue(ctx, stockSourceKey{}, stockSource{
+		reason:        reason,
+		referenceType: referenceType,
+		referenceID:   referenceID,
+	})
+}
+
+// newSourcedStockMovement builds a movement attributed to the stock source on
+// ctx, falling back to the given reason.
+func newSourcedStockMovement(ctx context.Context, quantity int, fallbackReason string) *model.StockMovement {
+	if source, ok := ctx.Value(stockSourceKey{}).(stockSource); ok {
+		return newStockMovement(ctx, quantity, source.reason, source.referenceType, source.referenceID)
+	}
+	return newStockMovement(ctx, quantity, fallbackReason, "", "")
+}
+
+// newStockMovement builds a movement made by the current actor. The caller
+// sets the product and variant.
+func newStockMovement(ctx context.Context, quantity int, reason string, referenceType string, referenceID string) *model.StockMovement {
+	movement := &model.StockMovement{
+		Quantity:      quantity,
+		Reason:        reason,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+	}
+	if meta := utils.RequestMetaFromContext(ctx); meta != nil && meta.ActorID != "" {
+		actorID := meta.ActorID
+		movement.ActorID = &actorID
+	}
+	return movement
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
 	"nuxatech-nextmedis/model"
 	"nuxatech-nextmedis/repository"
 	"nuxatech-nextmedis/utils"
+	"os"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -16,22 +19,70 @@ import (
 type UserService interface {
 	CreateUser(ctx context.Context, req request.CreateUserRequest) error
 	GetUser(ctx context.Context, id string) (*model.User, error)
-	UpdateUser(ctx context.Context, id string, req request.UpdateUserRequest) error
+	UpdateUser(ctx context.Context, id string, req request.UpdateUserRequest) (*model.User, error)
+	ChangePassword(ctx context.Context, id string, req request.ChangePasswordRequest) (*response.TokenResponse, error)
 	FindUserByEmail(ctx context.Context, email string) (*model.User, error)
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
 	FindUserAfterDate(ctx context.Context, date string) ([]*model.User, error)
-	DeleteUser(ctx context.Context, id string) error
+	DeleteUser(ctx context.Context, id string, req request.DeleteUserRequest) error
+	SearchUsers(ctx context.Context, params AdminUserQueryParams) (*response.AdminUserPagingResponse, error)
+	SuspendUser(ctx context.Context, actorID string, id string, req request.SuspendUserRequest) (*response.AdminUserResponse, error)
+	ReactivateUser(ctx context.Context, id string) (*response.AdminUserResponse, error)
+	ForceLogout(ctx context.Context, id string) error
+}
+
+type AdminUserQueryParams struct {
+	Page        int
+	Limit       int
+	Query       string
+	Username    string
+	Email       string
+	Role        string
+	Status      string
+	CreatedFrom int64
+	CreatedTo   int64
+	SortBy      string
+	SortOrder   string
 }
 
 type userService struct {
-	repo     repository.UserRepository
-	validate *validator.Validate
+	repo         repository.UserRepository
+	tokenRepo    repository.PersonalTokenRepository
+	apiTokenRepo repository.ApiTokenRepository
+	identityRepo repository.UserIdentityRepository
+	accountRepo  repository.AccountRepository
+	cartRepo     repository.CartRepository
+	addressRepo  repository.AddressRepository
+	exportRepo   repository.DataExportRepository
+	authService  AuthService
+	auditService AuditService
+	validate     *validator.Validate
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(
+	repo repository.UserRepository,
+	tokenRepo repository.PersonalTokenRepository,
+	apiTokenRepo repository.ApiTokenRepository,
+	identityRepo repository.UserIdentityRepository,
+	accountRepo repository.AccountRepository,
+	cartRepo repository.CartRepository,
+	addressRepo repository.AddressRepository,
+	exportRepo repository.DataExportRepository,
+	authService AuthService,
+	auditService AuditService,
+) UserService {
 	return &userService{
-		repo:     repo,
-		validate: validator.New(),
+		repo:         repo,
+		tokenRepo:    tokenRepo,
+		apiTokenRepo: apiTokenRepo,
+		identityRepo: identityRepo,
+		accountRepo:  accountRepo,
+		cartRepo:     cartRepo,
+		addressRepo:  addressRepo,
+		exportRepo:   exportRepo,
+		authService:  authService,
+		auditService: auditService,
+		validate:     validator.New(),
 	}
 }
 
@@ -50,14 +101,103 @@ func (u *userService) CreateUser(ctx context.Context, req request.CreateUserRequ
 		Email:     req.Email,
 		Username:  req.Username,
 		Password:  hashedPassword,
+		Role:      model.RoleCustomer,
+		Status:    model.UserStatusActive,
 		CreatedAt: time.Now().UnixMilli(),
 	}
 	return u.repo.CreateUser(ctx, &user)
 }
 
 // DeleteUser implements UserService.
-func (u *userService) DeleteUser(ctx context.Context, id string) error {
-	panic("unimplemented")
+// The user row is soft-deleted with its PII replaced, wallets are closed and
+// the cart is dropped. Data exports are expired and their archives removed.
+// Orders are kept for accounting.
+func (u *userService) DeleteUser(ctx context.Context, id string, req request.DeleteUserRequest) error {
+	if err := u.validate.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.VerifyPassword(req.Password, user.Password) {
+		return errors.New("invalid password")
+	}
+
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	tx := u.repo.BeginTx(ctx)
+	if tx == nil {
+		return errors.New("failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	// The accounts stay locked until they are closed, so no credit can land
+	// after the balance check.
+	accounts, err := u.accountRepo.LockUserAccounts(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		if account.Balance != 0 {
+			return errors.New("wallet balance must be withdrawn before deleting the account")
+		}
+	}
+
+	if err := u.accountRepo.CloseUserAccounts(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := u.cartRepo.DeleteUserCart(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := u.identityRepo.DeleteUserIdentities(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := u.addressRepo.DeleteUserAddresses(ctx, tx, id); err != nil {
+		return err
+	}
+	exportFiles, err := u.exportRepo.ExpireUserExports(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.AnonymizeUser(ctx, tx, id,
+		"deleted-"+id,
+		"deleted+"+id+"@deleted.invalid",
+		utils.HashPassword(randomPassword),
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	for _, path := range exportFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export file %s: %v", path, err)
+		}
+	}
+
+	if err := u.tokenRepo.DeleteAllUserTokens(ctx, id); err != nil {
+		return err
+	}
+	if err := u.apiTokenRepo.RevokeAllUserTokens(ctx, id, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionUserDelete,
+		TargetType: "user",
+		TargetID:   id,
+		Metadata:   model.AuditMetadata{"closed_accounts": len(accounts)},
+	})
+
+	return nil
 }
 
 // FindByUsername implements UserService.
@@ -89,6 +229,237 @@ func (u *userService) GetUser(ctx context.Context, id string) (*model.User, erro
 }
 
 // UpdateUser implements UserService.
-func (u *userService) UpdateUser(ctx context.Context, id string, req request.UpdateUserRequest) error {
-	panic("unimplemented")
+func (u *userService) UpdateUser(ctx context.Context, id string, req request.UpdateUserRequest) (*model.User, error) {
+	if err := u.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := model.AuditMetadata{}
+	if req.Username != nil && *req.Username != user.Username {
+		changed["username"] = map[string]string{"from": user.Username, "to": *req.Username}
+		user.Username = *req.Username
+	}
+	if req.Email != nil && *req.Email != user.Email {
+		changed["email"] = map[string]string{"from": user.Email, "to": *req.Email}
+		user.Email = *req.Email
+	}
+
+	if len(changed) == 0 {
+		return user, nil
+	}
+
+	if u.repo.CheckUserExistsExcept(ctx, user.Email, user.Username, user.ID) {
+		return nil, errors.New("username or email already taken")
+	}
+
+	if err := u.repo.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionProfileUpdate,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   changed,
+	})
+
+	return user, nil
+}
+
+// ChangePassword implements UserService.
+// Every existing session is revoked at once and a fresh pair is returned for
+// the caller.
+func (u *userService) ChangePassword(ctx context.Context, id string, req request.ChangePasswordRequest) (*response.TokenResponse, error) {
+	if err := u.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.VerifyPassword(req.CurrentPassword, user.Password) {
+		u.auditService.Record(ctx, &model.AuditEvent{
+			Action:     model.AuditActionPasswordChange,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Outcome:    model.AuditOutcomeFailure,
+			Metadata:   model.AuditMetadata{"reason": "wrong current password"},
+		})
+		return nil, errors.New("current password is incorrect")
+	}
+
+	if err := u.repo.UpdatePassword(ctx, user.ID, utils.HashPassword(req.NewPassword)); err != nil {
+		return nil, err
+	}
+
+	if err := u.revokeSessions(ctx, user.ID, time.Now().UnixMilli()); err != nil {
+		return nil, err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionPasswordChange,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+
+	return u.authService.GenerateTokenPair(user)
+}
+
+// SearchUsers implements UserService.
+func (u *userService) SearchUsers(ctx context.Context, params AdminUserQueryParams) (*response.AdminUserPagingResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	filter := repository.UserFilter{
+		Query:       params.Query,
+		Username:    params.Username,
+		Email:       params.Email,
+		Role:        params.Role,
+		Status:      params.Status,
+		CreatedFrom: params.CreatedFrom,
+		CreatedTo:   params.CreatedTo,
+		SortBy:      params.SortBy,
+		SortOrder:   params.SortOrder,
+	}
+
+	users, total, err := u.repo.SearchUsers(ctx, filter, params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.AdminUserResponse, len(users))
+	for i, user := range users {
+		result[i] = toAdminUserResponse(user)
+	}
+
+	return &response.AdminUserPagingResponse{
+		Metadata: response.Metadata{
+			TotalCount: int(total),
+			Page:       params.Page,
+			PerPage:    params.Limit,
+		},
+		Result: result,
+	}, nil
+}
+
+// SuspendUser implements UserService.
+// Suspension blocks login, refresh and API tokens, and ends current sessions.
+func (u *userService) SuspendUser(ctx context.Context, actorID string, id string, req request.SuspendUserRequest) (*response.AdminUserResponse, error) {
+	if err := u.validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if actorID == id {
+		return nil, errors.New("cannot suspend your own account")
+	}
+
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsSuspended() {
+		return nil, errors.New("user is already suspended")
+	}
+
+	now := time.Now().UnixMilli()
+	if err := u.repo.UpdateStatus(ctx, id, model.UserStatusSuspended, &now); err != nil {
+		return nil, err
+	}
+	if err := u.revokeSessions(ctx, id, now); err != nil {
+		return nil, err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionUserSuspend,
+		TargetType: "user",
+		TargetID:   id,
+		Metadata:   model.AuditMetadata{"reason": req.Reason},
+	})
+
+	user.Status = model.UserStatusSuspended
+	user.SuspendedAt = &now
+	user.SessionsRevokedAt = &now
+	result := toAdminUserResponse(user)
+	return &result, nil
+}
+
+// ReactivateUser implements UserService.
+func (u *userService) ReactivateUser(ctx context.Context, id string) (*response.AdminUserResponse, error) {
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsSuspended() {
+		return nil, errors.New("user is not suspended")
+	}
+
+	if err := u.repo.UpdateStatus(ctx, id, model.UserStatusActive, nil); err != nil {
+		return nil, err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionUserReactivate,
+		TargetType: "user",
+		TargetID:   id,
+		Metadata:   model.AuditMetadata{"suspended_at": user.SuspendedAt},
+	})
+
+	user.Status = model.UserStatusActive
+	user.SuspendedAt = nil
+	result := toAdminUserResponse(user)
+	return &result, nil
+}
+
+// ForceLogout implements UserService.
+// API tokens are not sessions and stay valid, revoke them separately.
+func (u *userService) ForceLogout(ctx context.Context, id string) error {
+	if _, err := u.repo.FindById(ctx, id); err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := u.revokeSessions(ctx, id, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+
+	u.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionUserForceLogout,
+		TargetType: "user",
+		TargetID:   id,
+	})
+
+	return nil
+}
+
+// revokeSessions drops refresh tokens and invalidates outstanding access tokens.
+func (u *userService) revokeSessions(ctx context.Context, id string, revokedAt int64) error {
+	if err := u.tokenRepo.DeleteAllUserTokens(ctx, id); err != nil {
+		return err
+	}
+	return u.repo.RevokeSessions(ctx, id, revokedAt)
+}
+
+func toAdminUserResponse(user *model.User) response.AdminUserResponse {
+	return response.AdminUserResponse{
+		ID:                user.ID,
+		Username:          user.Username,
+		Email:             user.Email,
+		Role:              user.Role,
+		Status:            user.Status,
+		CreatedAt:         user.CreatedAt,
+		SuspendedAt:       user.SuspendedAt,
+		SessionsRevokedAt: user.SessionsRevokedAt,
+	}
 }
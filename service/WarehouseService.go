@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WarehouseService interface {
+	GetWarehouses(ctx context.Context) ([]*model.Warehouse, error)
+	CreateWarehouse(ctx context.Context, req request.CreateWarehouseRequest) (*model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, id string, req request.UpdateWarehouseRequest) (*model.Warehouse, error)
+	TransferStock(ctx context.Context, req request.StockTransferRequest) ([]*model.StockMovement, error)
+}
+
+type warehouseService struct {
+	warehouseRepo repository.WarehouseRepository
+	stockRepo     repository.StockMovementRepository
+	productRepo   repository.ProductRepository
+	variantRepo   repository.ProductVariantRepository
+	auditService  AuditService
+	validate      *validator.Validate
+}
+
+func NewWarehouseService(
+	warehouseRepo repository.WarehouseRepository,
+	stockRepo repository.StockMovementRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	auditService AuditService,
+) WarehouseService {
+	return &warehouseService{
+		warehouseRepo: warehouseRepo,
+		stockRepo:     stockRepo,
+		productRepo:   productRepo,
+		variantRepo:   variantRepo,
+		auditService:  auditService,
+		validate:      validator.New(),
+	}
+}
+
+func (s *warehouseService) GetWarehouses(ctx context.Context) ([]*model.Warehouse, error) {
+	return s.warehouseRepo.GetWarehouses(ctx)
+}
+
+func (s *warehouseService) CreateWarehouse(ctx context.Context, req request.CreateWarehouseRequest) (*model.Warehouse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if err := s.checkCode(ctx, code, ""); err != nil {
+		return nil, err
+	}
+
+	active := req.Active == nil || *req.Active
+	if req.IsDefault && !active {
+		return nil, errors.New("the default warehouse must be active")
+	}
+
+	now := time.Now().UnixMilli()
+	warehouse := &model.Warehouse{
+		Code:        code,
+		Name:        req.Name,
+		City:        req.City,
+		Region:      req.Region,
+		PostalCode:  req.PostalCode,
+		CountryCode: strings.ToUpper(req.CountryCode),
+		Priority:    req.Priority,
+		Active:      active,
+		IsDefault:   req.IsDefault,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.warehouseRepo.CreateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionWarehouseCreate,
+		TargetType: "warehouse",
+		TargetID:   warehouse.ID,
+		Metadata:   model.AuditMetadata{"code": warehouse.Code},
+	})
+
+	return warehouse, nil
+}
+
+func (s *warehouseService) UpdateWarehouse(ctx context.Context, id string, req request.UpdateWarehouseRequest) (*model.Warehouse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	warehouse, err := s.warehouseRepo.GetWarehouse(ctx, id)
+	if err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	wasDefault := warehouse.IsDefault
+
+	if req.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*req.Code))
+		if code != warehouse.Code {
+			if err := s.checkCode(ctx, code, warehouse.ID); err != nil {
+				return nil, err
+			}
+			warehouse.Code = code
+		}
+	}
+	if req.Name != nil {
+		warehouse.Name = *req.Name
+	}
+	if req.City != nil {
+		warehouse.City = *req.City
+	}
+	if req.Region != nil {
+		warehouse.Region = *req.Region
+	}
+	if req.PostalCode != nil {
+		warehouse.PostalCode = *req.PostalCode
+	}
+	if req.CountryCode != nil {
+		warehouse.CountryCode = strings.ToUpper(*req.CountryCode)
+	}
+	if req.Priority != nil {
+		warehouse.Priority = *req.Priority
+	}
+	if req.Active != nil {
+		warehouse.Active = *req.Active
+	}
+	if req.IsDefault != nil {
+		warehouse.IsDefault = *req.IsDefault
+	}
+
+	// Stock changes without a warehouse need somewhere to go.
+	if wasDefault && !warehouse.IsDefault {
+		return nil, errors.New("make another warehouse the default instead")
+	}
+	if warehouse.IsDefault && !warehouse.Active {
+		return nil, errors.New("the default warehouse must be active")
+	}
+
+	warehouse.UpdatedAt = time.Now().UnixMilli()
+	if err := s.warehouseRepo.UpdateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionWarehouseUpdate,
+		TargetType: "warehouse",
+		TargetID:   warehouse.ID,
+		Metadata:   model.AuditMetadata{"code": warehouse.Code, "active": warehouse.Active},
+	})
+
+	return warehouse, nil
+}
+
+// TransferStock moves stock between warehouses and records both sides in the
+// stock ledger under one transfer reference.
+func (s *warehouseService) TransferStock(ctx context.Context, req request.StockTransferRequest) ([]*model.StockMovement, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	if req.VariantID != nil {
+		if _, err := s.variantRepo.GetVariant(ctx, req.ProductID, *req.VariantID); err != nil {
+			return nil, errors.New("variant not found")
+		}
+	} else if product.VariantCount > 0 {
+		return nil, errors.New("stock of a product with variants is managed per variant")
+	}
+
+	if _, err := s.warehouseRepo.GetWarehouse(ctx, req.FromWarehouseID); err != nil {
+		return nil, errors.New("source warehouse not found")
+	}
+	if _, err := s.warehouseRepo.GetWarehouse(ctx, req.ToWarehouseID); err != nil {
+		return nil, errors.New("destination warehouse not found")
+	}
+
+	transferID := uuid.New().String()
+	out := newStockMovement(ctx, -req.Quantity, model.StockReasonTransfer, "transfer", transferID)
+	in := newStockMovement(ctx, req.Quantity, model.StockReasonTransfer, "transfer", transferID)
+	for _, movement := range []*model.StockMovement{out, in} {
+		movement.ProductID = req.ProductID
+		movement.VariantID = req.VariantID
+		movement.Note = req.Note
+	}
+	out.WarehouseID = &req.FromWarehouseID
+	in.WarehouseID = &req.ToWarehouseID
+
+	if err := s.stockRepo.TransferStock(ctx, out, in); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("not enough unreserved stock at the source warehouse")
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditEvent{
+		Action:     model.AuditActionStockTransfer,
+		TargetType: "product",
+		TargetID:   req.ProductID,
+		Metadata: model.AuditMetadata{
+			"transfer_id":       transferID,
+			"variant_id":        req.VariantID,
+			"from_warehouse_id": req.FromWarehouseID,
+			"to_warehouse_id":   req.ToWarehouseID,
+			"quantity":          req.Quantity,
+		},
+	})
+
+	return []*model.StockMovement{out, in}, nil
+}
+
+func (s *warehouseService) checkCode(ctx context.Context, code string, excludeID string) error {
+	if code == "" {
+		return errors.New("code is required")
+	}
+	exists, err := s.warehouseRepo.CodeExists(ctx, code, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("warehouse code already exists")
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"nuxatech-nextmedis/config"
+	"nuxatech-nextmedis/dto/request"
+	"nuxatech-nextmedis/dto/response"
+	"nuxatech-nextmedis/model"
+	"nuxatech-nextmedis/repository"
+	"nuxatech-nextmedis/utils"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type WishlistService interface {
+	GetWishlists(ctx context.Context, userID string) ([]response.WishlistResponse, error)
+	GetWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error)
+	GetSharedWishlist(ctx context.Context, token string) (*response.WishlistResponse, error)
+	CreateWishlist(ctx context.Context, userID string, req request.CreateWishlistRequest) (*response.WishlistResponse, error)
+	RenameWishlist(ctx context.Context, userID string, id string, req request.UpdateWishlistRequest) (*response.WishlistResponse, error)
+	DeleteWishlist(ctx context.Context, userID string, id string) error
+	ShareWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error)
+	UnshareWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error)
+	AddItem(ctx context.Context, userID string, wishlistID string, req request.AddWishlistItemRequest) (*response.WishlistResponse, error)
+	RemoveItem(ctx context.Context, userID string, wishlistID string, itemID string) error
+	MoveToCart(ctx context.Context, userID string, wishlistID string, itemID string, req request.MoveToCartRequest) (*response.CartResponse, error)
+	Run(ctx context.Context)
+}
+
+type wishlistService struct {
+	wishlistRepo        repository.WishlistRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.ProductVariantRepository
+	cartService         CartService
+	notificationService NotificationService
+	validate            *validator.Validate
+}
+
+const (
+	maxWishlists           = 20
+	maxWishlistItems       = 100
+	priceDropSweepInterval = 5 * time.Minute
+	priceDropSweepBatch    = 100
+)
+
+func NewWishlistService(
+	wishlistRepo repository.WishlistRepository,
+	productRepo repository.ProductRepository,
+	variantRepo repository.ProductVariantRepository,
+	cartService CartService,
+	notificationService NotificationService,
+) WishlistService {
+	return &wishlistService{
+		wishlistRepo:        wishlistRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		cartService:         cartService,
+		notificationService: notificationService,
+		validate:            validator.New(),
+	}
+}
+
+func (s *wishlistService) GetWishlists(ctx context.Context, userID string) ([]response.WishlistResponse, error) {
+	wishlists, err := s.wishlistRepo.GetUserWishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.WishlistResponse, len(wishlists))
+	for i, wishlist := range wishlists {
+		result[i] = toWishlistResponse(wishlist, true)
+	}
+	return result, nil
+}
+
+func (s *wishlistService) GetWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error) {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	result := toWishlistResponse(wishlist, true)
+	return &result, nil
+}
+
+// GetSharedWishlist returns a wishlist through its public link, without
+// anything identifying its owner.
+func (s *wishlistService) GetSharedWishlist(ctx context.Context, token string) (*response.WishlistResponse, error) {
+	wishlist, err := s.wishlistRepo.GetWishlistByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist not found")
+		}
+		return nil, err
+	}
+	result := toWishlistResponse(wishlist, false)
+	return &result, nil
+}
+
+func (s *wishlistService) CreateWishlist(ctx context.Context, userID string, req request.CreateWishlistRequest) (*response.WishlistResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	count, err := s.wishlistRepo.CountWishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWishlists {
+		return nil, fmt.Errorf("you can have at most %d wishlists", maxWishlists)
+	}
+	if err := s.checkName(ctx, userID, req.Name, ""); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	wishlist := &model.Wishlist{
+		UserID:    userID,
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.wishlistRepo.CreateWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+
+	result := toWishlistResponse(wishlist, true)
+	return &result, nil
+}
+
+func (s *wishlistService) RenameWishlist(ctx context.Context, userID string, id string, req request.UpdateWishlistRequest) (*response.WishlistResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, userID, req.Name, wishlist.ID); err != nil {
+		return nil, err
+	}
+
+	wishlist.Name = req.Name
+	return s.saveWishlist(ctx, wishlist)
+}
+
+func (s *wishlistService) DeleteWishlist(ctx context.Context, userID string, id string) error {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.wishlistRepo.DeleteWishlist(ctx, wishlist.ID)
+}
+
+// ShareWishlist gives the wishlist a public link. Sharing a shared wishlist
+// keeps its link.
+func (s *wishlistService) ShareWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error) {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.ShareToken == nil {
+		token, err := utils.GenerateRandomToken(24)
+		if err != nil {
+			return nil, err
+		}
+		wishlist.ShareToken = &token
+	}
+	return s.saveWishlist(ctx, wishlist)
+}
+
+// UnshareWishlist revokes the public link. Sharing again creates a new one.
+func (s *wishlistService) UnshareWishlist(ctx context.Context, userID string, id string) (*response.WishlistResponse, error) {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	wishlist.ShareToken = nil
+	return s.saveWishlist(ctx, wishlist)
+}
+
+// AddItem saves a product in the wishlist. Adding it twice is a no-op.
+func (s *wishlistService) AddItem(ctx context.Context, userID string, wishlistID string, req request.AddWishlistItemRequest) (*response.WishlistResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+	var variant *model.ProductVariant
+	if req.VariantID != nil {
+		variant, err = s.variantRepo.GetVariant(ctx, product.ID, *req.VariantID)
+		if err != nil {
+			return nil, errors.New("variant not found")
+		}
+	} else if product.VariantCount > 0 {
+		return nil, errors.New("variant_id is required for products with variants")
+	}
+
+	_, err = s.wishlistRepo.FindItem(ctx, wishlist.ID, product.ID, req.VariantID)
+	if err == nil {
+		return s.GetWishlist(ctx, userID, wishlist.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	count, err := s.wishlistRepo.CountItems(ctx, wishlist.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWishlistItems {
+		return nil, fmt.Errorf("a wishlist can hold at most %d items", maxWishlistItems)
+	}
+
+	item := &model.WishlistItem{
+		WishlistID: wishlist.ID,
+		ProductID:  product.ID,
+		VariantID:  req.VariantID,
+		LastPrice:  unitPrice(product, variant),
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+	if err := s.wishlistRepo.AddItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return s.GetWishlist(ctx, userID, wishlist.ID)
+}
+
+func (s *wishlistService) RemoveItem(ctx context.Context, userID string, wishlistID string, itemID string) error {
+	wishlist, err := s.getWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		return err
+	}
+	if err := s.wishlistRepo.DeleteItem(ctx, wishlist.ID, itemID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("item not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// MoveToCart adds the item to the cart, with the usual stock checks, and
+// removes it from the wishlist.
+func (s *wishlistService) MoveToCart(ctx context.Context, userID string, wishlistID string, itemID string, req request.MoveToCartRequest) (*response.CartResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.wishlistRepo.GetItem(ctx, wishlist.ID, itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("item not found")
+		}
+		return nil, err
+	}
+
+	addReq := &request.AddToCartRequest{
+		ProductID: item.ProductID,
+		Quantity:  req.Quantity,
+	}
+	if addReq.Quantity == 0 {
+		addReq.Quantity = 1
+	}
+	if item.VariantID != nil {
+		addReq.VariantID = *item.VariantID
+	}
+	cart, err := s.cartService.AddToCart(ctx, userID, addReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.wishlistRepo.DeleteItem(ctx, wishlist.ID, item.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Failed to remove wishlist item %s moved to cart: %v", item.ID, err)
+	}
+	return cart, nil
+}
+
+// Run notifies users of price drops on their wishlist items until ctx is
+// done. Price changes are picked up whatever made them, edits, imports or
+// variants.
+func (s *wishlistService) Run(ctx context.Context) {
+	s.sweep(ctx)
+
+	ticker := time.NewTicker(priceDropSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *wishlistService) sweep(ctx context.Context) {
+	if err := s.wishlistRepo.RaiseLastPrices(ctx); err != nil {
+		log.Printf("Failed to update wishlist prices: %v", err)
+		return
+	}
+
+	for {
+		items, err := s.wishlistRepo.FindPriceDrops(ctx, priceDropSweepBatch)
+		if err != nil {
+			log.Printf("Failed to load wishlist price drops: %v", err)
+			return
+		}
+
+		// A product saved in several wishlists of a user is notified once.
+		notified := make(map[string]bool, len(items))
+		for _, item := range items {
+			updated, err := s.wishlistRepo.UpdateLastPrice(ctx, item.ID, item.LastPrice, item.CurrentPrice)
+			if err != nil {
+				log.Printf("Failed to update wishlist item %s: %v", item.ID, err)
+				return
+			}
+			if !updated || item.Wishlist == nil {
+				continue
+			}
+
+			key := item.Wishlist.UserID + ":" + stockKey(item.ProductID, item.VariantID, nil)
+			if notified[key] {
+				continue
+			}
+			notified[key] = true
+
+			product := item.Product
+			s.notificationService.Notify(ctx, item.Wishlist.UserID, model.NotificationTypePriceDrop,
+				fmt.Sprintf("Price drop: %s", product.Name),
+				fmt.Sprintf("%s from your wishlist %s is now %d, down from %d.", product.Name, item.Wishlist.Name, item.CurrentPrice, item.LastPrice),
+				model.NotificationData{
+					"product_id":  item.ProductID,
+					"variant_id":  item.VariantID,
+					"wishlist_id": item.WishlistID,
+					"slug":        product.Slug,
+					"old_price":   item.LastPrice,
+					"new_price":   item.CurrentPrice,
+				})
+		}
+		if len(items) < priceDropSweepBatch {
+			return
+		}
+	}
+}
+
+func (s *wishlistService) getWishlist(ctx context.Context, userID string, id string) (*model.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetWishlist(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist not found")
+		}
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (s *wishlistService) checkName(ctx context.Context, userID string, name string, excludeID string) error {
+	exists, err := s.wishlistRepo.NameExists(ctx, userID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("you already have a wishlist with this name")
+	}
+	return nil
+}
+
+func (s *wishlistService) saveWishlist(ctx context.Context, wishlist *model.Wishlist) (*response.WishlistResponse, error) {
+	wishlist.UpdatedAt = time.Now().UnixMilli()
+	if err := s.wishlistRepo.UpdateWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	result := toWishlistResponse(wishlist, true)
+	return &result, nil
+}
+
+// toWishlistResponse shows the share link only to the owner.
+func toWishlistResponse(wishlist *model.Wishlist, owner bool) response.WishlistResponse {
+	items := make([]response.WishlistItemResponse, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		items[i] = response.WishlistItemResponse{
+			ID:        item.ID,
+			Product:   toProductResponse(item.Product),
+			Variant:   item.Variant,
+			Price:     unitPrice(&item.Product, item.Variant),
+			CreatedAt: item.CreatedAt,
+		}
+	}
+
+	result := response.WishlistResponse{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Items:     items,
+		CreatedAt: wishlist.CreatedAt,
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+	if owner && wishlist.ShareToken != nil {
+		shareURL := fmt.Sprintf("%s/api/v1/wishlists/shared/%s", config.Envs.AppURL, *wishlist.ShareToken)
+		result.ShareURL = &shareURL
+	}
+	return result
+}
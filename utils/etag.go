@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// VersionETag is the strong ETag for a versioned resource.
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseVersionETag reads the version back out of an If-Match header value.
+func ParseVersionETag(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "W/") {
+		return 0, errors.New("weak etags cannot be used with If-Match")
+	}
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, errors.New("invalid etag")
+	}
+	return version, nil
+}
+
+// ContentETag is a weak ETag derived from a response body, for resources
+// without a version such as listings.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches implements the weak comparison used for If-None-Match.
+func ETagMatches(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == target {
+			return true
+		}
+	}
+	return false
+}
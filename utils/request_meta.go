@@ -0,0 +1,22 @@
+package utils
+
+import "context"
+
+type requestMetaKey struct{}
+
+// RequestMeta describes who made the current request and from where, so that
+// services can attribute audit events without access to the gin context.
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+	ActorID   string
+}
+
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(*RequestMeta)
+	return meta
+}
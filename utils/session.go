@@ -9,3 +9,16 @@ func GetUserID(c *gin.Context) (userID string) {
 func GetEmail(c *gin.Context) (email string) {
 	return c.MustGet("email").(string)
 }
+
+// GetActorID returns the real user behind the request, which differs from
+// GetUserID while an admin is impersonating someone.
+func GetActorID(c *gin.Context) (actorID string) {
+	if actorID = c.GetString("actor_id"); actorID != "" {
+		return actorID
+	}
+	return GetUserID(c)
+}
+
+func IsImpersonating(c *gin.Context) bool {
+	return c.GetString("actor_id") != ""
+}
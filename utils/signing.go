@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Sign returns a hex HMAC-SHA256 of the joined parts, used for links that
+// must work without an Authorization header.
+func Sign(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifySignature(secret string, signature string, parts ...string) bool {
+	return hmac.Equal([]byte(Sign(secret, parts...)), []byte(signature))
+}